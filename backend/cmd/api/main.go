@@ -11,7 +11,6 @@ import (
 	// swagger embed files
 	// gin-swagger middleware
 	_ "github.com/biomonash/nillumbik/docs"
-	"github.com/biomonash/nillumbik/internal/db"
 	"github.com/biomonash/nillumbik/internal/server"
 )
 
@@ -32,9 +31,7 @@ func run() error {
 	}
 	defer conn.Close()
 
-	querier := db.New(conn)
-
-	s := server.New(querier)
+	s := server.New(conn)
 
 	return s.Run(":8000")
 }
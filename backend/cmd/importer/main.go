@@ -1,20 +1,96 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
+	"github.com/biomonash/nillumbik/internal/auth"
 	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/dwca"
+	"github.com/biomonash/nillumbik/internal/export"
 	"github.com/biomonash/nillumbik/internal/importer"
+	"github.com/biomonash/nillumbik/internal/stats"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const usage = `Usage:
+  importer [flags]          import the CSV file (CSV_PATH)
+  importer batches          list past imports
+  importer undo <batch-id>  delete everything an import created and restore what it updated
+  importer import-dwca [flags] [-block n] [-tenure tenure] [-forest forest] <archive.zip>
+                            import the occurrences of a Darwin Core Archive
+  importer import-birdnet [flags] [-pattern regexp] [-min-confidence n] <results file or directory>
+                            import BirdNET-Analyzer detections as audio observations
+  importer import-camtrap [flags] [-block n] [-tenure tenure] [-forest forest] [-event-gap duration] <package>
+                            import a Camtrap DP package as camera observations
+  importer import-megadetector [flags] [-pattern regexp] [-min-confidence n] [-event-gap duration] <output.json>
+                            import classified MegaDetector detections as camera observations
+  importer import-deployments [flags] <deployments.csv>
+                            import when devices were recording at each site
+  importer export-dwca [-o file] [-from date] [-to date] [-block n] [-site code] [-tenure tenure] [-forest forest]
+                       [-taxa taxa] [-common-name name] [-independence minutes]
+                            export observations as a Darwin Core Archive
+  importer create-user -email email -name name [-role role]
+                            add an API user, reading their password from stdin
+
+Flags:
+`
+
+// importFlags are the flags shared by every import.
+type importFlags struct {
+	dryRun       *bool
+	reportPath   *string
+	rejectedPath *string
+	onConflict   *string
+}
+
+func addImportFlags(fs *flag.FlagSet) importFlags {
+	return importFlags{
+		dryRun:       fs.Bool("dry-run", false, "Validate every row without writing to the database"),
+		reportPath:   fs.String("report", "import-report.json", "Where the dry-run writes its JSON report"),
+		rejectedPath: fs.String("rejected", "import-rejected.csv", "Where the dry-run writes the rejected rows"),
+		onConflict:   fs.String("on-conflict", string(importer.ConflictUpdate), "What to do with rows already imported: skip or update"),
+	}
+}
+
+// format validates and imports one kind of file.
+type format struct {
+	name     string
+	validate func(ctx context.Context, q db.Querier, filename string, opts importer.Options) (*importer.Report, error)
+	run      func(ctx context.Context, conn importer.Conn, filename string, opts importer.Options) (*importer.Report, error)
+}
+
+var (
+	csvFormat     = format{"CSV", importer.ValidateCSV, importer.ImportCSV}
+	dwcaFormat    = format{"Darwin Core Archive", importer.ValidateDwCA, importer.ImportDwCA}
+	birdnetFormat = format{"BirdNET", importer.ValidateBirdNET, importer.ImportBirdNET}
+	camtrapFormat = format{"Camtrap DP", importer.ValidateCamtrapDP, importer.ImportCamtrapDP}
+	mdFormat      = format{"MegaDetector", importer.ValidateMegaDetector, importer.ImportMegaDetector}
+	deplFormat    = format{"deployments", importer.ValidateDeployments, importer.ImportDeployments}
+)
+
 func main() {
-	fmt.Println("Starting CSV import...")
+	flags := addImportFlags(flag.CommandLine)
+	columnsPath := flag.String("columns", "", "YAML or JSON file mapping fields to the CSV headers of this spreadsheet")
+	positional := flag.Bool("positional", false, "Read the CSV columns in the survey spreadsheet's fixed order instead of by header name")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
 	// Load DB connection string from environment
 	connStr := os.Getenv("DB_URL")
@@ -42,23 +118,406 @@ func main() {
 	}
 	defer pool.Close()
 
-	q := db.New(pool)
+	switch flag.Arg(0) {
+	case "":
+		opts := importer.Options{
+			OnConflict: importer.ConflictMode(*flags.onConflict),
+			Positional: *positional,
+		}
+		if *columnsPath != "" {
+			opts.Columns, err = importer.LoadColumnMapping(*columnsPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		// Determine CSV path (environment variable fallback or default relative path)
+		csvPath := os.Getenv("CSV_PATH")
+		if csvPath == "" {
+			csvPath = "./data/nillumbik.csv"
+		}
+		runImport(ctx, pool, csvFormat, csvPath, flags, opts)
+	case "batches":
+		listBatches(ctx, db.New(pool))
+	case "undo":
+		id, err := strconv.ParseInt(flag.Arg(1), 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid batch id %q: %v", flag.Arg(1), err)
+		}
+		undoBatch(ctx, pool, id)
+	case "import-dwca":
+		importDwCA(ctx, pool, flag.Args()[1:])
+	case "import-birdnet":
+		importBirdNET(ctx, pool, flag.Args()[1:])
+	case "import-camtrap":
+		importCamtrapDP(ctx, pool, flag.Args()[1:])
+	case "import-megadetector":
+		importMegaDetector(ctx, pool, flag.Args()[1:])
+	case "import-deployments":
+		importDeployments(ctx, pool, flag.Args()[1:])
+	case "export-dwca":
+		exportDwCA(ctx, db.New(pool), flag.Args()[1:])
+	case "create-user":
+		createUser(ctx, db.New(pool), flag.Args()[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func runImport(ctx context.Context, pool *pgxpool.Pool, f format, path string, flags importFlags, opts importer.Options) {
+	if *flags.dryRun {
+		fmt.Printf("Starting %s validation (dry run)...\n", f.name)
+	} else {
+		fmt.Printf("Starting %s import...\n", f.name)
+	}
 
-	// Determine CSV path (environment variable fallback or default relative path)
-	csvPath := os.Getenv("CSV_PATH")
-	if csvPath == "" {
-		csvPath = "./data/nillumbik.csv"
+	// Check that the file exists
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("%s not found at %s: %v", f.name, path, err)
 	}
 
-	// Check that CSV file exists
-	if _, err := os.Stat(csvPath); err != nil {
-		log.Fatalf("CSV file not found at %s: %v", csvPath, err)
+	if *flags.dryRun {
+		report, err := f.validate(ctx, db.New(pool), path, opts)
+		if err != nil {
+			log.Fatalf("Validation failed: %v", err)
+		}
+		if err := writeReport(report, *flags.reportPath, *flags.rejectedPath); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		fmt.Printf("Rows: %d, valid: %d, rejected: %d\n", report.TotalRows, report.ValidRows, report.RejectedRows)
+		fmt.Printf("New sites: %d, new species: %d\n", len(report.NewSites), len(report.NewSpecies))
+		printIgnored(report)
+		fmt.Printf("Report written to %s, rejected rows to %s\n", *flags.reportPath, *flags.rejectedPath)
+		if report.RejectedRows > 0 {
+			os.Exit(1)
+		}
+		return
 	}
 
 	// Run importer
-	if err := importer.ImportCSV(ctx, q, csvPath); err != nil {
-		log.Fatalf("Import failed: %v", err)
+	report, err := f.run(ctx, pool, path, opts)
+	if err != nil {
+		if report != nil {
+			fmt.Printf("Processed %d rows before the failure\n", report.TotalRows)
+		}
+		log.Fatalf("Import failed, rolled back and nothing was committed: %v", err)
 	}
 
+	fmt.Printf("Committed import batch %d from %d rows: %d new, %d updated and %d unchanged observations, %d new sites, %d new species, %d deployments\n",
+		report.BatchID, report.TotalRows, report.Observations, report.Updated, report.Unchanged, len(report.NewSites), len(report.NewSpecies), report.Deployments)
+	printIgnored(report)
 	fmt.Println("Import completed successfully!")
 }
+
+func printIgnored(report *importer.Report) {
+	if report.ColumnsByPosition {
+		fmt.Println("Columns were read by position, in the survey spreadsheet's fixed order")
+	}
+	if len(report.Duplicates) > 0 {
+		fmt.Printf("Duplicate rows: %d repeat the observation of an earlier row\n", len(report.Duplicates))
+	}
+	if len(report.UnmappedTerms) > 0 {
+		fmt.Printf("Ignored terms: %s\n", strings.Join(report.UnmappedTerms, ", "))
+	}
+	if len(report.UnsupportedClasses) > 0 {
+		fmt.Printf("Skipped classes: %s\n", strings.Join(report.UnsupportedClasses, ", "))
+	}
+	for _, reason := range slices.Sorted(maps.Keys(report.Skipped)) {
+		fmt.Printf("Skipped %d rows: %s\n", report.Skipped[reason], reason)
+	}
+}
+
+// addSiteFlags adds the flags giving the block, tenure and forest of new
+// sites, for files that do not record them.
+func addSiteFlags(fs *flag.FlagSet) func() importer.SiteDefaults {
+	block := fs.Int("block", 0, "Block of new sites the file gives no block for")
+	tenure := fs.String("tenure", "", "Tenure of new sites the file gives no tenure for: public or private")
+	forest := fs.String("forest", "", "Forest type of new sites the file gives no forest type for: dry or wet")
+	return func() importer.SiteDefaults {
+		defaults := importer.SiteDefaults{
+			Tenure: db.TenureType(*tenure),
+			Forest: db.ForestType(*forest),
+		}
+		if *block != 0 {
+			b := int32(*block)
+			defaults.Block = &b
+		}
+		return defaults
+	}
+}
+
+// addDetectionFlags adds the flags to read file names and filter detections
+// of classifier results.
+func addDetectionFlags(fs *flag.FlagSet) func() (*importer.FilePattern, float32) {
+	pattern := fs.String("pattern", importer.DefaultFilePattern, "Regular expression finding the site, date and time groups in recording and image paths")
+	minConfidence := fs.Float64("min-confidence", 0.5, "Drop detections scored below this confidence")
+	return func() (*importer.FilePattern, float32) {
+		filePattern, err := importer.NewFilePattern(*pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return filePattern, float32(*minConfidence)
+	}
+}
+
+// parseArgs parses the flags of a subcommand taking one path.
+func parseArgs(fs *flag.FlagSet, args []string) string {
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	return fs.Arg(0)
+}
+
+func importDwCA(ctx context.Context, pool *pgxpool.Pool, args []string) {
+	fs := flag.NewFlagSet("import-dwca", flag.ExitOnError)
+	flags := addImportFlags(fs)
+	siteDefaults := addSiteFlags(fs)
+	path := parseArgs(fs, args)
+
+	opts := importer.Options{
+		OnConflict: importer.ConflictMode(*flags.onConflict),
+		Site:       siteDefaults(),
+	}
+	runImport(ctx, pool, dwcaFormat, path, flags, opts)
+}
+
+func writeReport(report *importer.Report, reportPath, rejectedPath string) error {
+	reportFile, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer reportFile.Close()
+	if err := report.WriteJSON(reportFile); err != nil {
+		return err
+	}
+
+	rejectedFile, err := os.Create(rejectedPath)
+	if err != nil {
+		return err
+	}
+	defer rejectedFile.Close()
+	return report.WriteRejectedCSV(rejectedFile)
+}
+
+func listBatches(ctx context.Context, q db.Querier) {
+	batches, err := q.ListImportBatches(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list import batches: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFILE\tSTATUS\tROWS\tOBSERVATIONS\tSTARTED\tFINISHED\tCHECKSUM")
+	for _, b := range batches {
+		finished := "-"
+		if b.FinishedAt.Valid {
+			finished = b.FinishedAt.Time.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+			b.ID, b.FileName, b.Status, b.RowCount, b.ObservationCount,
+			b.StartedAt.Format(time.DateTime), finished, b.Checksum[:min(12, len(b.Checksum))])
+	}
+	w.Flush()
+}
+
+func undoBatch(ctx context.Context, pool *pgxpool.Pool, id int64) {
+	report, err := importer.UndoImport(ctx, pool, id)
+	if err != nil {
+		log.Fatalf("Undo failed, nothing was deleted: %v", err)
+	}
+	fmt.Printf("Undid import batch %d: deleted %d observations, %d deployments, %d sites and %d species, restored %d updated observations\n",
+		report.BatchID, report.Observations, report.Deployments, report.Sites, report.Species, report.Restored)
+}
+
+func importBirdNET(ctx context.Context, pool *pgxpool.Pool, args []string) {
+	fs := flag.NewFlagSet("import-birdnet", flag.ExitOnError)
+	flags := addImportFlags(fs)
+	detections := addDetectionFlags(fs)
+	path := parseArgs(fs, args)
+
+	opts := importer.Options{OnConflict: importer.ConflictMode(*flags.onConflict)}
+	opts.FilePattern, opts.MinConfidence = detections()
+	runImport(ctx, pool, birdnetFormat, path, flags, opts)
+}
+
+func importCamtrapDP(ctx context.Context, pool *pgxpool.Pool, args []string) {
+	fs := flag.NewFlagSet("import-camtrap", flag.ExitOnError)
+	flags := addImportFlags(fs)
+	siteDefaults := addSiteFlags(fs)
+	eventGap := fs.Duration("event-gap", importer.DefaultEventGap, "Longest gap between images of a species at a site within one observation")
+	path := parseArgs(fs, args)
+
+	opts := importer.Options{
+		OnConflict: importer.ConflictMode(*flags.onConflict),
+		Site:       siteDefaults(),
+		EventGap:   *eventGap,
+	}
+	runImport(ctx, pool, camtrapFormat, path, flags, opts)
+}
+
+func importMegaDetector(ctx context.Context, pool *pgxpool.Pool, args []string) {
+	fs := flag.NewFlagSet("import-megadetector", flag.ExitOnError)
+	flags := addImportFlags(fs)
+	detections := addDetectionFlags(fs)
+	eventGap := fs.Duration("event-gap", importer.DefaultEventGap, "Longest gap between images of a species at a site within one observation")
+	path := parseArgs(fs, args)
+
+	opts := importer.Options{
+		OnConflict: importer.ConflictMode(*flags.onConflict),
+		EventGap:   *eventGap,
+	}
+	opts.FilePattern, opts.MinConfidence = detections()
+	runImport(ctx, pool, mdFormat, path, flags, opts)
+}
+
+func importDeployments(ctx context.Context, pool *pgxpool.Pool, args []string) {
+	fs := flag.NewFlagSet("import-deployments", flag.ExitOnError)
+	flags := addImportFlags(fs)
+	path := parseArgs(fs, args)
+
+	opts := importer.Options{OnConflict: importer.ConflictMode(*flags.onConflict)}
+	runImport(ctx, pool, deplFormat, path, flags, opts)
+}
+
+func exportDwCA(ctx context.Context, q db.Querier, args []string) {
+	fs := flag.NewFlagSet("export-dwca", flag.ExitOnError)
+	output := fs.String("o", "nillumbik-dwca.zip", "Output file")
+	filters := addFilterFlags(fs)
+	fs.Parse(args)
+
+	input, err := filters.input()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", *output, err)
+	}
+	defer file.Close()
+
+	if err := dwca.Export(ctx, q, export.DwCAParams(input), file); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+	fmt.Printf("Darwin Core Archive written to %s\n", *output)
+}
+
+// filterFlags are the flags of the filters the export endpoints take.
+type filterFlags struct {
+	from         *string
+	to           *string
+	block        *string
+	siteCode     *string
+	tenure       *string
+	forest       *string
+	taxa         *string
+	commonName   *string
+	independence *int
+}
+
+func addFilterFlags(fs *flag.FlagSet) filterFlags {
+	return filterFlags{
+		from:         fs.String("from", "", "Only observations from this date (YYYY-MM-DD)"),
+		to:           fs.String("to", "", "Only observations up to this date (YYYY-MM-DD)"),
+		block:        fs.String("block", "", "Only observations in this block"),
+		siteCode:     fs.String("site", "", "Only observations at this site code"),
+		tenure:       fs.String("tenure", "", "Only observations at sites of this tenure: public or private"),
+		forest:       fs.String("forest", "", "Only observations at sites of this forest type: dry or wet"),
+		taxa:         fs.String("taxa", "", "Only observations of this taxa"),
+		commonName:   fs.String("common-name", "", "Only observations of the species with this common name"),
+		independence: fs.Int("independence", 0, "Only observations starting an independent event, after this many minutes without one of the species at the site by the same method"),
+	}
+}
+
+// input builds the same filters the stats endpoints take from the flags,
+// empty values meaning no filter, and validates them as the endpoints do.
+func (f filterFlags) input() (input stats.ObservationStatsInput, err error) {
+	if err = input.From.UnmarshalParam(*f.from); err != nil {
+		return
+	}
+	if err = input.To.UnmarshalParam(*f.to); err != nil {
+		return
+	}
+	if *f.block != "" {
+		b, perr := strconv.ParseInt(*f.block, 10, 32)
+		if perr != nil {
+			return input, fmt.Errorf("invalid block %q: %w", *f.block, perr)
+		}
+		b32 := int32(b)
+		input.Block = &b32
+	}
+	if *f.siteCode != "" {
+		input.SiteCode = f.siteCode
+	}
+	if *f.tenure != "" {
+		t := db.TenureType(*f.tenure)
+		input.Tenure = &t
+	}
+	if *f.forest != "" {
+		forest := db.ForestType(*f.forest)
+		input.Forest = &forest
+	}
+	if *f.taxa != "" {
+		t := db.Taxa(*f.taxa)
+		if !t.Valid() {
+			return input, fmt.Errorf("unknown taxa %q", *f.taxa)
+		}
+		input.Taxa = &t
+	}
+	if *f.commonName != "" {
+		input.CommonName = f.commonName
+	}
+	if *f.independence != 0 {
+		minutes := int32(*f.independence)
+		input.Independence = &minutes
+	}
+	if err = binding.Validator.ValidateStruct(&input); err != nil {
+		return input, fmt.Errorf("invalid filters: %w", err)
+	}
+	return
+}
+
+// createUser adds a user to the API, e.g. the first admin who then manages
+// the others through /api/users.
+func createUser(ctx context.Context, q db.Querier, args []string) {
+	fs := flag.NewFlagSet("create-user", flag.ExitOnError)
+	email := fs.String("email", "", "Email the user logs in with")
+	name := fs.String("name", "", "Name of the user")
+	role := fs.String("role", string(db.UserRoleAdmin), "Role of the user: viewer, ecologist or admin")
+	fs.Parse(args)
+
+	if *email == "" || *name == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if !db.UserRole(*role).Valid() {
+		log.Fatalf("Unknown role %q", *role)
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	password = strings.TrimRight(password, "\r\n")
+	if err != nil && password == "" {
+		log.Fatalf("Failed to read password: %v", err)
+	}
+	if len(password) < 8 || len(password) > 72 {
+		log.Fatal("Password must be 8 to 72 characters long")
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	user, err := q.CreateUser(ctx, db.CreateUserParams{
+		Email:        *email,
+		Name:         *name,
+		PasswordHash: hash,
+		Role:         db.UserRole(*role),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create user: %v", err)
+	}
+	fmt.Printf("Created %s user %d (%s)\n", user.Role, user.ID, user.Email)
+}
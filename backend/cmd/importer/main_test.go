@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/export"
+)
+
+func TestFilterFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want func(p db.ListObservationDetailsParams) bool
+		err  string
+	}{
+		{"no filters", nil, func(p db.ListObservationDetailsParams) bool {
+			return !p.From.Valid && !p.To.Valid && p.Block == nil && !p.Tenure.Valid && !p.Forest.Valid && p.Independence == nil
+		}, ""},
+		{"every filter", []string{
+			"-from", "2024-01-01", "-to", "2024-12-31", "-block", "3", "-site", "SG01", "-tenure", "private",
+			"-forest", "wet", "-taxa", "bird", "-common-name", "Superb Lyrebird", "-independence", "30",
+		}, func(p db.ListObservationDetailsParams) bool {
+			return p.From.Valid && p.To.Valid && *p.Block == 3 && *p.SiteCode == "SG01" &&
+				p.Tenure == db.NullTenureType{TenureType: db.TenureTypePrivate, Valid: true} &&
+				p.Forest == db.NullForestType{ForestType: db.ForestTypeWet, Valid: true} &&
+				p.Taxa == db.NullTaxa{Taxa: db.TaxaBird, Valid: true} && *p.Independence == 30
+		}, ""},
+		{"unknown tenure", []string{"-tenure", "leased"}, nil, "Tenure"},
+		{"unknown forest", []string{"-forest", "rain"}, nil, "Forest"},
+		{"independence over a week", []string{"-independence", "10081"}, nil, "Independence"},
+		{"negative independence", []string{"-independence", "-5"}, nil, "Independence"},
+		{"invalid block", []string{"-block", "north"}, nil, "invalid block"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("export-dwca", flag.ContinueOnError)
+			filters := addFilterFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			input, err := filters.input()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p := export.DwCAParams(input); !tt.want(p) {
+				t.Errorf("params = %+v", p)
+			}
+		})
+	}
+}
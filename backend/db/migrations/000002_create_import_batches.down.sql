@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS observations_import_batch_id_idx;
+
+ALTER TABLE observations DROP COLUMN IF EXISTS import_batch_id;
+ALTER TABLE species DROP COLUMN IF EXISTS import_batch_id;
+ALTER TABLE sites DROP COLUMN IF EXISTS import_batch_id;
+
+DROP TABLE IF EXISTS import_batches;
+
+DROP TYPE IF EXISTS import_status;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TYPE import_status AS ENUM ('running', 'completed', 'failed', 'reverted');
+
+CREATE TABLE IF NOT EXISTS import_batches (
+    id  BIGSERIAL PRIMARY KEY,
+    file_name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    row_count integer NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL DEFAULT now(),
+    finished_at TIMESTAMP,
+    status import_status NOT NULL DEFAULT 'running'
+);
+
+ALTER TABLE sites ADD COLUMN import_batch_id BIGINT REFERENCES import_batches(id) ON DELETE SET NULL;
+ALTER TABLE species ADD COLUMN import_batch_id BIGINT REFERENCES import_batches(id) ON DELETE SET NULL;
+ALTER TABLE observations ADD COLUMN import_batch_id BIGINT REFERENCES import_batches(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS observations_import_batch_id_idx ON observations (import_batch_id);
+
+COMMIT;
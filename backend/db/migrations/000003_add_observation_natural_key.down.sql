@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP TABLE IF EXISTS observation_revisions;
+
+DROP INDEX IF EXISTS observations_natural_key;
+
+-- Put back the duplicates the up migration set aside
+INSERT INTO observations
+SELECT * FROM observation_duplicates;
+
+DROP TABLE IF EXISTS observation_duplicates;
+
+COMMIT;
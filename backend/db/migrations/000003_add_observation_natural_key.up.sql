@@ -0,0 +1,41 @@
+BEGIN;
+
+-- Move duplicates left behind by earlier re-imports, keeping the first copy,
+-- to a holding table so they can be reviewed or restored by migrating down
+CREATE TABLE IF NOT EXISTS observation_duplicates AS
+SELECT o.*
+FROM observations o
+WHERE EXISTS (
+    SELECT 1 FROM observations d
+    WHERE o.site_id = d.site_id
+      AND o.species_id = d.species_id
+      AND o."timestamp" = d."timestamp"
+      AND o.method = d.method
+      AND COALESCE(o.file, '') = COALESCE(d.file, '')
+      AND o.id > d.id
+);
+
+DELETE FROM observations
+WHERE id IN (SELECT id FROM observation_duplicates);
+
+-- An observation is identified by site, species, timestamp, method and file
+CREATE UNIQUE INDEX IF NOT EXISTS observations_natural_key
+ON observations (site_id, species_id, "timestamp", method, COALESCE(file, ''));
+
+-- The values an import replaced when it updated an observation, so that
+-- undoing the import can put them back
+CREATE TABLE IF NOT EXISTS observation_revisions (
+    id  BIGSERIAL PRIMARY KEY,
+    observation_id BIGINT NOT NULL REFERENCES observations(id) ON DELETE CASCADE,
+    import_batch_id BIGINT NOT NULL REFERENCES import_batches(id) ON DELETE CASCADE,
+    appearance_start integer,
+    appearance_end integer,
+    temperature integer,
+    narrative text,
+    confidence real,
+    UNIQUE (import_batch_id, observation_id)
+);
+
+CREATE INDEX IF NOT EXISTS observation_revisions_observation_id_idx ON observation_revisions (observation_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
+
+DROP TYPE IF EXISTS user_role;
+
+COMMIT;
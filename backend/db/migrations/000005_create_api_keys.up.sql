@@ -0,0 +1,19 @@
+BEGIN;
+
+-- API keys let scripts and partners use the API without a login. Like
+-- session tokens, only the SHA-256 hash of a key is stored; the prefix is
+-- kept to tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id  BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash BYTEA UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL CHECK (scopes <@ ARRAY['stats:read', 'export', 'import']),
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+COMMIT;
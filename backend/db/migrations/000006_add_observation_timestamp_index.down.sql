@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS observations_timestamp_id_idx;
+
+COMMIT;
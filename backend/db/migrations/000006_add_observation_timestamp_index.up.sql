@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Observations are paged through by timestamp, then id
+CREATE INDEX IF NOT EXISTS observations_timestamp_id_idx ON observations ("timestamp", id);
+
+COMMIT;
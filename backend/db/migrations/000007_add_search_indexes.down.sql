@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS observations_search_idx;
+DROP INDEX IF EXISTS species_search_idx;
+DROP INDEX IF EXISTS sites_search_idx;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Full-text search indexes. The expressions must match those of the
+-- queries in db/queries/search.sql for the indexes to be used.
+CREATE INDEX IF NOT EXISTS sites_search_idx ON sites
+USING GIN (to_tsvector('english', code || ' ' || COALESCE(name, '')));
+
+CREATE INDEX IF NOT EXISTS species_search_idx ON species
+USING GIN (to_tsvector('english', common_name || ' ' || scientific_name));
+
+CREATE INDEX IF NOT EXISTS observations_search_idx ON observations
+USING GIN (to_tsvector('english', COALESCE(narrative, '')));
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS starts_event(BIGINT, INT);
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- starts_event tells whether an observation starts an independent detection
+-- event, that is whether no observation of the same species at the same site
+-- by the same method was made in the window_minutes before it. Events are
+-- chained: triggers less than the window apart all belong to the first one's
+-- event. Observations at the same time are ordered by id. The lookup uses the
+-- observations_natural_key index.
+CREATE OR REPLACE FUNCTION starts_event(observation_id BIGINT, window_minutes INT)
+RETURNS BOOLEAN
+LANGUAGE sql STABLE
+AS $$
+    SELECT NOT EXISTS (
+        SELECT 1
+        FROM observations o
+        JOIN observations p
+          ON p.site_id = o.site_id
+         AND p.species_id = o.species_id
+         AND p.method = o.method
+        WHERE o.id = observation_id
+          AND p."timestamp" >= o."timestamp" - make_interval(mins => window_minutes)
+          AND (p."timestamp", p.id) < (o."timestamp", o.id)
+    );
+$$;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- A deployment is a device, or a survey, recording at a site over a period:
+-- the effort observations were made with.
+CREATE TABLE IF NOT EXISTS deployments (
+    id  BIGSERIAL PRIMARY KEY,
+    site_id BIGINT NOT NULL REFERENCES sites(id),
+    method observation_method NOT NULL,
+    device_id TEXT,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    notes TEXT,
+    import_batch_id BIGINT REFERENCES import_batches(id) ON DELETE SET NULL,
+    CHECK (end_time > start_time)
+);
+
+-- A deployment is identified by site, method, device and start
+CREATE UNIQUE INDEX IF NOT EXISTS deployments_natural_key
+ON deployments (site_id, method, start_time, COALESCE(device_id, ''));
+
+CREATE INDEX IF NOT EXISTS deployments_import_batch_id_idx ON deployments (import_batch_id);
+
+COMMIT;
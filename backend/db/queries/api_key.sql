@@ -0,0 +1,25 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+VALUES (
+    $1, $2, $3, $4, $5,
+    now() + make_interval(days => sqlc.narg('expires_in_days')::int)
+)
+RETURNING *;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: UseAPIKey :one
+-- UseAPIKey returns an unrevoked and unexpired key, recording its use.
+UPDATE api_keys
+SET last_used_at = now()
+WHERE key_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now())
+RETURNING *;
@@ -0,0 +1,69 @@
+-- name: CreateDeployment :one
+INSERT INTO deployments (site_id, method, device_id, start_time, end_time, notes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetDeployment :one
+SELECT * FROM deployments
+WHERE id = $1 LIMIT 1;
+
+-- name: ListDeployments :many
+-- ListDeployments lists the deployments of a site and method, or of all of
+-- them, that overlap the period.
+SELECT d.* FROM deployments d
+JOIN sites s ON d.site_id = s.id
+WHERE (sqlc.narg('site_code')::text IS NULL OR s.code = sqlc.narg('site_code'))
+  AND (sqlc.narg('method')::observation_method IS NULL OR d.method = sqlc.narg('method'))
+  AND (sqlc.narg('from')::timestamp IS NULL OR d.end_time > sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR d.start_time < sqlc.narg('to')::timestamp)
+ORDER BY s.code, d.start_time, d.id;
+
+-- name: UpdateDeployment :one
+UPDATE deployments
+SET site_id = $2, method = $3, device_id = $4, start_time = $5, end_time = $6, notes = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteDeployment :execrows
+DELETE FROM deployments
+WHERE id = $1;
+
+-- name: UpsertDeployment :execrows
+-- UpsertDeployment imports a deployment, identified by site, method, device
+-- and start. It affects no row when the deployment exists and is unchanged,
+-- or update_existing is false.
+INSERT INTO deployments (site_id, method, device_id, start_time, end_time, notes, import_batch_id)
+VALUES (
+  sqlc.arg('site_id'),
+  sqlc.arg('method'),
+  sqlc.narg('device_id'),
+  sqlc.arg('start_time'),
+  sqlc.arg('end_time'),
+  sqlc.narg('notes'),
+  sqlc.narg('import_batch_id')
+)
+ON CONFLICT (site_id, method, start_time, COALESCE(device_id, ''))
+DO UPDATE SET
+  end_time = EXCLUDED.end_time,
+  notes = EXCLUDED.notes
+WHERE sqlc.arg('update_existing')::boolean
+  AND (deployments.end_time, deployments.notes) IS DISTINCT FROM (EXCLUDED.end_time, EXCLUDED.notes);
+
+-- name: DeploymentEffortBySite :many
+-- DeploymentEffortBySite sums the time each site was surveyed by each
+-- method within the period, in seconds.
+SELECT s.code AS site_code, d.method,
+    SUM(EXTRACT(epoch FROM
+        LEAST(d.end_time, COALESCE(sqlc.narg('to')::timestamp, d.end_time))
+        - GREATEST(d.start_time, COALESCE(sqlc.narg('from')::timestamp, d.start_time))
+    ))::float8 AS seconds
+FROM deployments d
+JOIN sites s ON d.site_id = s.id
+WHERE (sqlc.narg('from')::timestamp IS NULL OR d.end_time > sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR d.start_time < sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR s.block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR s.tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR s.forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR s.code = sqlc.narg('site_code'))
+GROUP BY s.code, d.method
+ORDER BY s.code, d.method;
@@ -0,0 +1,43 @@
+-- name: ListObservationDetails :many
+SELECT *
+FROM observations_with_details
+WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
+ORDER BY "timestamp", id;
+
+-- name: ListOccupancySites :many
+SELECT * FROM sites
+WHERE (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR code = sqlc.narg('site_code'))
+ORDER BY code;
+
+-- name: ListOccupancyOccasions :many
+-- ListOccupancyOccasions lists the occasions of the period each site has
+-- observations in, of any species, which show it was surveyed, and whether
+-- the species was detected in them. The taxa and independence filters only
+-- narrow down the detections, not the survey effort.
+SELECT site_id,
+    FLOOR(EXTRACT(epoch FROM "timestamp" - sqlc.arg('start_time')::timestamp) / (sqlc.arg('occasion_days')::int * 86400))::int AS occasion,
+    BOOL_OR(species_id = sqlc.arg('species_id')::bigint
+        AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
+        AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
+    ) AS detected
+FROM observations_with_details
+WHERE "timestamp" >= sqlc.arg('start_time')::timestamp
+  AND "timestamp" < sqlc.arg('end_time')::timestamp
+  AND (sqlc.narg('method')::observation_method IS NULL OR method = sqlc.narg('method'))
+  AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
+GROUP BY site_id, occasion
+ORDER BY site_id, occasion;
@@ -0,0 +1,86 @@
+-- name: CreateImportBatch :one
+INSERT INTO import_batches (file_name, checksum)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: FinishImportBatch :one
+UPDATE import_batches
+SET status = $2, row_count = $3, finished_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetImportBatchStatus :exec
+UPDATE import_batches
+SET status = $2
+WHERE id = $1;
+
+-- name: GetImportBatch :one
+SELECT * FROM import_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: ListImportBatches :many
+SELECT b.*, (SELECT COUNT(*) FROM observations o WHERE o.import_batch_id = b.id) AS observation_count
+FROM import_batches b
+ORDER BY b.started_at DESC;
+
+-- name: DeleteObservationsByImportBatch :execrows
+DELETE FROM observations
+WHERE import_batch_id = sqlc.arg('import_batch_id')::bigint;
+
+-- name: RestoreObservationsByImportBatch :execrows
+-- Observations the batch updated get back the values they had before it,
+-- unless a later import updated them again.
+UPDATE observations o
+SET
+  appearance_start = r.appearance_start,
+  appearance_end = r.appearance_end,
+  temperature = r.temperature,
+  narrative = r.narrative,
+  confidence = r.confidence
+FROM observation_revisions r
+WHERE r.import_batch_id = sqlc.arg('import_batch_id')::bigint
+  AND o.id = r.observation_id
+  AND NOT EXISTS (
+    SELECT 1 FROM observation_revisions later
+    WHERE later.observation_id = r.observation_id AND later.id > r.id
+  );
+
+-- name: PassOnObservationRevisionsByImportBatch :exec
+-- The next revision of an observation the batch updated, made by a later
+-- import, takes the values from before the batch, so that undoing the later
+-- import too restores them.
+UPDATE observation_revisions later
+SET
+  appearance_start = r.appearance_start,
+  appearance_end = r.appearance_end,
+  temperature = r.temperature,
+  narrative = r.narrative,
+  confidence = r.confidence
+FROM observation_revisions r
+WHERE r.import_batch_id = sqlc.arg('import_batch_id')::bigint
+  AND later.observation_id = r.observation_id
+  AND later.id = (
+    SELECT MIN(n.id) FROM observation_revisions n
+    WHERE n.observation_id = r.observation_id AND n.id > r.id
+  );
+
+-- name: DeleteObservationRevisionsByImportBatch :exec
+DELETE FROM observation_revisions
+WHERE import_batch_id = sqlc.arg('import_batch_id')::bigint;
+
+-- name: DeleteSpeciesByImportBatch :execrows
+-- Species still observed by other imports are kept.
+DELETE FROM species sp
+WHERE sp.import_batch_id = sqlc.arg('import_batch_id')::bigint
+  AND NOT EXISTS (SELECT 1 FROM observations o WHERE o.species_id = sp.id);
+
+-- name: DeleteSitesByImportBatch :execrows
+-- Sites still used by other imports are kept.
+DELETE FROM sites si
+WHERE si.import_batch_id = sqlc.arg('import_batch_id')::bigint
+  AND NOT EXISTS (SELECT 1 FROM observations o WHERE o.site_id = si.id)
+  AND NOT EXISTS (SELECT 1 FROM deployments d WHERE d.site_id = si.id);
+
+-- name: DeleteDeploymentsByImportBatch :execrows
+DELETE FROM deployments
+WHERE import_batch_id = sqlc.arg('import_batch_id')::bigint;
@@ -9,10 +9,11 @@ INSERT INTO observations (
   temperature,
   narrative,
   confidence,
-  file
+  file,
+  import_batch_id
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file;
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file, import_batch_id;
 
 -- name: CreateObservations :copyfrom
 INSERT INTO observations (
@@ -25,22 +26,84 @@ INSERT INTO observations (
   temperature,
   narrative,
   confidence,
-  file
+  file,
+  import_batch_id
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
 
 -- name: GetObservation :one
-SELECT id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file
+SELECT id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file, import_batch_id
 FROM observations
 WHERE id = $1 LIMIT 1;
 
 -- name: ListObservations :many
-SELECT id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file
-FROM observations
-ORDER BY timestamp
-LIMIT $1
-OFFSET $2;
+-- ListObservations pages through the matching observations in timestamp
+-- order, starting after the cursor (after_timestamp, after_id) if given.
+-- Offset paging is deprecated: the cursor stays fast on late pages.
+SELECT o.id, o.site_id, o.species_id, o."timestamp", o.method, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.file, o.import_batch_id
+FROM observations o
+JOIN sites si ON o.site_id = si.id
+JOIN species sp ON o.species_id = sp.id
+WHERE (sqlc.narg('from')::timestamp IS NULL OR o."timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR o."timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR si.block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR si.tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR si.forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR si.code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR sp.taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(sp.common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(o.id, sqlc.narg('independence')::int))
+  AND (sqlc.narg('method')::observation_method IS NULL OR o.method = sqlc.narg('method')::observation_method)
+  AND (sqlc.narg('min_confidence')::real IS NULL OR o.confidence >= sqlc.narg('min_confidence')::real)
+  AND (sqlc.narg('native')::bool IS NULL OR sp.native = sqlc.narg('native')::bool)
+  AND (sqlc.narg('indicator')::bool IS NULL OR sp.indicator = sqlc.narg('indicator')::bool)
+  AND (sqlc.narg('after_timestamp')::timestamp IS NULL
+    OR (o."timestamp", o.id) > (sqlc.narg('after_timestamp')::timestamp, sqlc.arg('after_id')::bigint))
+ORDER BY o."timestamp", o.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
+-- name: ListObservationsDesc :many
+-- ListObservationsDesc is ListObservations, newest first.
+SELECT o.id, o.site_id, o.species_id, o."timestamp", o.method, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.file, o.import_batch_id
+FROM observations o
+JOIN sites si ON o.site_id = si.id
+JOIN species sp ON o.species_id = sp.id
+WHERE (sqlc.narg('from')::timestamp IS NULL OR o."timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR o."timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR si.block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR si.tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR si.forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR si.code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR sp.taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(sp.common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(o.id, sqlc.narg('independence')::int))
+  AND (sqlc.narg('method')::observation_method IS NULL OR o.method = sqlc.narg('method')::observation_method)
+  AND (sqlc.narg('min_confidence')::real IS NULL OR o.confidence >= sqlc.narg('min_confidence')::real)
+  AND (sqlc.narg('native')::bool IS NULL OR sp.native = sqlc.narg('native')::bool)
+  AND (sqlc.narg('indicator')::bool IS NULL OR sp.indicator = sqlc.narg('indicator')::bool)
+  AND (sqlc.narg('after_timestamp')::timestamp IS NULL
+    OR (o."timestamp", o.id) < (sqlc.narg('after_timestamp')::timestamp, sqlc.arg('after_id')::bigint))
+ORDER BY o."timestamp" DESC, o.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountObservations :one
+SELECT COUNT(*)
+FROM observations o
+JOIN sites si ON o.site_id = si.id
+JOIN species sp ON o.species_id = sp.id
+WHERE (sqlc.narg('from')::timestamp IS NULL OR o."timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR o."timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR si.block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR si.tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR si.forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR si.code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR sp.taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(sp.common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(o.id, sqlc.narg('independence')::int))
+  AND (sqlc.narg('method')::observation_method IS NULL OR o.method = sqlc.narg('method')::observation_method)
+  AND (sqlc.narg('min_confidence')::real IS NULL OR o.confidence >= sqlc.narg('min_confidence')::real)
+  AND (sqlc.narg('native')::bool IS NULL OR sp.native = sqlc.narg('native')::bool)
+  AND (sqlc.narg('indicator')::bool IS NULL OR sp.indicator = sqlc.narg('indicator')::bool);
 
 -- name: UpdateObservation :one
 UPDATE observations
@@ -55,15 +118,12 @@ SET site_id = $2,
     confidence = $10,
     file = $11
 WHERE id = $1
-RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file;
+RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file, import_batch_id;
 
--- name: DeleteObservation :exec
+-- name: DeleteObservation :execrows
 DELETE FROM observations
 WHERE id = $1;
 
--- name: CountObservations :one
-SELECT COUNT(*) FROM observations;
-
 -- name: SearchObservations :many
 SELECT o.*, s.code as site_code, s.name as site_name, sp.scientific_name, sp.common_name, sp.taxa
 FROM observations o
@@ -71,3 +131,67 @@ JOIN sites s ON o.site_id = s.id
 JOIN species sp ON o.species_id = sp.id
 WHERE sp.scientific_name ILIKE $1 OR sp.common_name ILIKE $1 OR o.narrative ILIKE $1
 ORDER BY o.timestamp DESC;
+
+-- name: UpsertObservations :batchone
+-- UpsertObservations inserts observations by their natural key (site, species,
+-- timestamp, method, file). An existing observation is updated only when
+-- update_existing is set and its values differ, otherwise no row is returned.
+-- The values an update replaces are kept as a revision of the import batch.
+WITH previous AS (
+  SELECT o.id, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.import_batch_id
+  FROM observations o
+  WHERE o.site_id = sqlc.arg('site_id')
+    AND o.species_id = sqlc.arg('species_id')
+    AND o."timestamp" = sqlc.arg('timestamp')
+    AND o.method = sqlc.arg('method')
+    AND COALESCE(o.file, '') = COALESCE(sqlc.narg('file')::text, '')
+), upserted AS (
+  INSERT INTO observations (
+    site_id,
+    species_id,
+    "timestamp",
+    method,
+    appearance_start,
+    appearance_end,
+    temperature,
+    narrative,
+    confidence,
+    file,
+    import_batch_id
+  )
+  VALUES (
+    sqlc.arg('site_id'),
+    sqlc.arg('species_id'),
+    sqlc.arg('timestamp'),
+    sqlc.arg('method'),
+    sqlc.narg('appearance_start'),
+    sqlc.narg('appearance_end'),
+    sqlc.narg('temperature'),
+    sqlc.narg('narrative'),
+    sqlc.narg('confidence'),
+    sqlc.narg('file'),
+    sqlc.narg('import_batch_id')
+  )
+  ON CONFLICT (site_id, species_id, "timestamp", method, COALESCE(file, ''))
+  DO UPDATE SET
+    appearance_start = EXCLUDED.appearance_start,
+    appearance_end = EXCLUDED.appearance_end,
+    temperature = EXCLUDED.temperature,
+    narrative = EXCLUDED.narrative,
+    confidence = EXCLUDED.confidence
+  WHERE sqlc.arg('update_existing')::boolean
+    AND (observations.appearance_start, observations.appearance_end, observations.temperature, observations.narrative, observations.confidence)
+      IS DISTINCT FROM (EXCLUDED.appearance_start, EXCLUDED.appearance_end, EXCLUDED.temperature, EXCLUDED.narrative, EXCLUDED.confidence)
+  RETURNING id, (xmax = 0)::boolean AS inserted
+), revision AS (
+  -- Only the first update of the batch holds the values from before it, and
+  -- observations the batch inserted are deleted when it is undone anyway
+  INSERT INTO observation_revisions (observation_id, import_batch_id, appearance_start, appearance_end, temperature, narrative, confidence)
+  SELECT p.id, sqlc.narg('import_batch_id')::bigint, p.appearance_start, p.appearance_end, p.temperature, p.narrative, p.confidence
+  FROM previous p
+  JOIN upserted u ON u.id = p.id AND NOT u.inserted
+  WHERE sqlc.narg('import_batch_id')::bigint IS NOT NULL
+    AND p.import_batch_id IS DISTINCT FROM sqlc.narg('import_batch_id')::bigint
+  ON CONFLICT (import_batch_id, observation_id) DO NOTHING
+)
+SELECT id, inserted FROM upserted;
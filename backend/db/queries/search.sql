@@ -0,0 +1,85 @@
+-- name: FullTextSearchSites :many
+-- FullTextSearchSites ranks the sites whose code or name match the
+-- tsquery.
+SELECT s.id, s.code, s.block, s.name, s.location, s.tenure, s.forest,
+    ts_rank(to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')), q)::real AS rank
+FROM sites s, to_tsquery('english', sqlc.arg('query')) q
+WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q
+ORDER BY rank DESC, s.code
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSearchSites :one
+SELECT COUNT(*)
+FROM sites s, to_tsquery('english', sqlc.arg('query')) q
+WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q;
+
+-- name: FullTextSearchSpecies :many
+-- FullTextSearchSpecies ranks the species whose common or scientific name
+-- match the tsquery.
+SELECT sp.id, sp.scientific_name, sp.common_name, sp.native, sp.taxa, sp.indicator, sp.reportable,
+    ts_rank(to_tsvector('english', sp.common_name || ' ' || sp.scientific_name), q)::real AS rank
+FROM species sp, to_tsquery('english', sqlc.arg('query')) q
+WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q
+ORDER BY rank DESC, sp.scientific_name
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSearchSpecies :one
+SELECT COUNT(*)
+FROM species sp, to_tsquery('english', sqlc.arg('query')) q
+WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q;
+
+-- name: FullTextSearchObservations :many
+-- FullTextSearchObservations ranks the observations whose narrative, species
+-- or site match the tsquery, newest first among equal ranks. Each table is
+-- matched on its own, so that every match can use its index, and the
+-- matching observations are joined up afterwards.
+WITH matches AS (
+    SELECT o.id
+    FROM observations o, to_tsquery('english', sqlc.arg('query')) q
+    WHERE to_tsvector('english', COALESCE(o.narrative, '')) @@ q
+    UNION
+    SELECT o.id
+    FROM species sp
+    JOIN observations o ON o.species_id = sp.id,
+        to_tsquery('english', sqlc.arg('query')) q
+    WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q
+    UNION
+    SELECT o.id
+    FROM sites s
+    JOIN observations o ON o.site_id = s.id,
+        to_tsquery('english', sqlc.arg('query')) q
+    WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q
+)
+SELECT o.id, o.site_id, o.species_id, o."timestamp", o.method, o.narrative, o.confidence, o.file,
+    s.code AS site_code, sp.scientific_name, sp.common_name,
+    (ts_rank(to_tsvector('english', COALESCE(o.narrative, '')), q)
+        + ts_rank(to_tsvector('english', sp.common_name || ' ' || sp.scientific_name), q)
+        + ts_rank(to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')), q))::real AS rank
+FROM matches m
+JOIN observations o ON o.id = m.id
+JOIN sites s ON o.site_id = s.id
+JOIN species sp ON o.species_id = sp.id,
+    to_tsquery('english', sqlc.arg('query')) q
+ORDER BY rank DESC, o."timestamp" DESC, o.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSearchObservations :one
+-- CountSearchObservations counts the matches of FullTextSearchObservations.
+SELECT COUNT(*)
+FROM (
+    SELECT o.id
+    FROM observations o, to_tsquery('english', sqlc.arg('query')) q
+    WHERE to_tsvector('english', COALESCE(o.narrative, '')) @@ q
+    UNION
+    SELECT o.id
+    FROM species sp
+    JOIN observations o ON o.species_id = sp.id,
+        to_tsquery('english', sqlc.arg('query')) q
+    WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q
+    UNION
+    SELECT o.id
+    FROM sites s
+    JOIN observations o ON o.site_id = s.id,
+        to_tsquery('english', sqlc.arg('query')) q
+    WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q
+) matches;
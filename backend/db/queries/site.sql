@@ -1,6 +1,6 @@
 -- name: CreateSite :one
-INSERT INTO sites (code, block, name, location, tenure, forest)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO sites (code, block, name, location, tenure, forest, import_batch_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetSite :one
@@ -34,14 +34,33 @@ RETURNING *;
 DELETE FROM sites
 WHERE id = $1;
 
--- name: DeleteSiteByCode :exec
+-- name: DeleteSiteByCode :execrows
 DELETE FROM sites
 WHERE code = $1;
 
+-- name: DeleteSiteByCodeCascade :execrows
+-- DeleteSiteByCodeCascade deletes a site together with its observations and
+-- deployments.
+WITH site AS (
+    SELECT s.id FROM sites s WHERE s.code = $1
+), deleted_observations AS (
+    DELETE FROM observations
+    WHERE site_id IN (SELECT id FROM site)
+), deleted_deployments AS (
+    DELETE FROM deployments
+    WHERE site_id IN (SELECT id FROM site)
+)
+DELETE FROM sites
+WHERE id IN (SELECT id FROM site);
+
 -- name: CountSites :one
 SELECT COUNT(*) FROM sites;
 
 -- name: SearchSites :many
 SELECT * FROM sites
 WHERE code ILIKE $1 OR name ILIKE $1
-ORDER BY code;
\ No newline at end of file
+ORDER BY code;
+
+-- name: ListSitesByID :many
+SELECT * FROM sites
+WHERE id = ANY(sqlc.arg('ids')::bigint[]);
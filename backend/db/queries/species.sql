@@ -1,25 +1,31 @@
 -- name: CreateSpecies :one
-INSERT INTO species (scientific_name, common_name, native, taxa, indicator, reportable)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable;
+INSERT INTO species (scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id;
 
 -- name: GetSpecies :one
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE id = $1 LIMIT 1;
 
 -- name: GetSpeciesByCommonName :one
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE lower(common_name) = LOWER($1) LIMIT 1;
 
+-- name: ListSpeciesByCommonName :many
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
+FROM species
+WHERE lower(common_name) = LOWER(sqlc.arg('common_name'))
+ORDER BY id;
+
 -- name: GetSpeciesByScientificName :one
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE lower(scientific_name) = LOWER($1) LIMIT 1;
 
 -- name: ListSpecies :many
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 ORDER BY scientific_name;
 
@@ -28,9 +34,9 @@ UPDATE species
 SET scientific_name = $2, common_name = $3, native = $4,
     taxa = $5, indicator = $6, reportable = $7
 WHERE id = $1
-RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable;
+RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id;
 
--- name: DeleteSpecies :exec
+-- name: DeleteSpecies :execrows
 DELETE FROM species
 WHERE id = $1;
 
@@ -38,7 +44,7 @@ WHERE id = $1;
 SELECT COUNT(*) FROM species;
 
 -- name: SearchSpecies :many
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE scientific_name ILIKE $1 OR common_name ILIKE $1
 ORDER BY scientific_name;
@@ -65,3 +71,40 @@ WHERE
     )
 GROUP BY sp.id, sp.scientific_name, sp.common_name
 ORDER BY observation_count DESC;
+
+-- name: MergeSpecies :one
+-- MergeSpecies moves the observations of the source species to the target
+-- and deletes the source. Source observations that the target already has,
+-- by site, timestamp, method and file, are deleted as duplicates.
+WITH moved AS (
+    UPDATE observations o
+    SET species_id = sqlc.arg('target_id')
+    WHERE o.species_id = sqlc.arg('source_id')
+      AND NOT EXISTS (
+        SELECT 1 FROM observations t
+        WHERE t.species_id = sqlc.arg('target_id')
+          AND t.site_id = o.site_id
+          AND t."timestamp" = o."timestamp"
+          AND t.method = o.method
+          AND COALESCE(t.file, '') = COALESCE(o.file, '')
+      )
+    RETURNING o.id
+), duplicates AS (
+    DELETE FROM observations o
+    WHERE o.species_id = sqlc.arg('source_id')
+      AND o.id NOT IN (SELECT id FROM moved)
+    RETURNING o.id
+), deleted AS (
+    DELETE FROM species
+    WHERE id = sqlc.arg('source_id')
+    RETURNING id
+)
+SELECT
+    (SELECT COUNT(*) FROM moved) AS moved_observations,
+    (SELECT COUNT(*) FROM duplicates) AS duplicate_observations,
+    (SELECT COUNT(*) FROM deleted) AS deleted_species;
+
+-- name: ListSpeciesByID :many
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
+FROM species
+WHERE id = ANY(sqlc.arg('ids')::bigint[]);
@@ -10,9 +10,12 @@ FROM observations_with_details
 WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
   AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
   AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
   AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
   AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
   AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
 GROUP BY native;
 
 -- name: ListSpeciesCountByTaxa :many
@@ -21,32 +24,58 @@ FROM observations_with_details
 WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
   AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
   AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
   AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
   AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
   AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
 GROUP BY taxa;
 
--- name: ObservationTimeSeriesGroupByNative :many
-SELECT native as is_native, date_trunc('year', "timestamp")::timestamp AS year, COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count
+-- name: ObservationTimeSeries :many
+-- ObservationTimeSeries counts observations per time bucket and series. The
+-- interval is a date_trunc field or "season", the austral seasons starting
+-- on the first of December, March, June and September.
+SELECT
+    (CASE WHEN sqlc.arg('interval')::text = 'season'
+        THEN date_trunc('quarter', "timestamp" + interval '1 month') - interval '1 month'
+        ELSE date_trunc(sqlc.arg('interval')::text, "timestamp")
+    END)::timestamp AS bucket,
+    (CASE sqlc.arg('group_by')::text
+        WHEN 'taxa' THEN taxa::text
+        WHEN 'method' THEN method::text
+        WHEN 'forest' THEN forest::text
+        WHEN 'tenure' THEN tenure::text
+        ELSE CASE WHEN native THEN 'native' ELSE 'non-native' END
+    END)::text AS series,
+    COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count
 FROM observations_with_details
 WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
   AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
   AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
   AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
   AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
   AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
-GROUP BY year, native
-ORDER BY year;
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
+GROUP BY bucket, series
+ORDER BY bucket, series;
 
 -- name: ObservationGroupBySites :many
-SELECT site_code, COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count
+SELECT site_code, COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count,
+    COUNT(*) FILTER (WHERE method = 'camera') AS camera_count,
+    COUNT(*) FILTER (WHERE method = 'audio') AS audio_count
 FROM observations_with_details
 WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
   AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
   AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
   AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
   AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
   AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
 GROUP BY site_code
 ORDER BY site_code;
 
@@ -56,14 +85,107 @@ FROM observations_with_details
 WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
   AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
   AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
   AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
   AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
   AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
 GROUP BY block
 ORDER BY block;
 
+-- name: ObservationSpeciesBySite :many
+-- ObservationSpeciesBySite lists every site matching the site filters with
+-- its tenure and forest, its observations and the species among them, so
+-- that groups of sites can be compared.
+SELECT s.code AS site_code, s.tenure, s.forest, COUNT(o.id) AS observation_count,
+    COALESCE(ARRAY_AGG(DISTINCT o.species_id) FILTER (WHERE o.species_id IS NOT NULL), '{}')::bigint[] AS species_ids
+FROM sites s
+LEFT JOIN observations_with_details o ON o.site_id = s.id
+  AND (sqlc.narg('from')::timestamp IS NULL OR o."timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR o."timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('taxa')::taxa IS NULL OR o.taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(o.common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(o.id, sqlc.narg('independence')::int))
+WHERE (sqlc.narg('block')::int IS NULL OR s.block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR s.tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR s.forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR s.code = sqlc.narg('site_code'))
+GROUP BY s.id
+ORDER BY s.code;
+
 -- name: CountActiveSites :one
 SELECT COUNT(DISTINCT site_id) as sites_count
 FROM observations_with_details
 WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
   AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp);
+
+-- name: ObservationAbundanceBySite :many
+-- ObservationAbundanceBySite counts the observations of each species at each
+-- site, the abundances diversity indices are computed from.
+SELECT site_code, block, species_id, COUNT(*) AS observation_count
+FROM observations_with_details
+WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
+GROUP BY site_code, block, species_id
+ORDER BY site_code, species_id;
+
+-- name: ObservationSpeciesSequence :many
+-- ObservationSpeciesSequence lists the species of each observation in time
+-- order, to accumulate species over the observations or days of a survey.
+SELECT site_code, block, "timestamp", species_id
+FROM observations_with_details
+WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
+ORDER BY "timestamp", id;
+
+-- name: ObservationActivityByMinute :many
+-- ObservationActivityByMinute counts the observations of each species by
+-- minute of the day, local time, optionally only of the species_ids.
+SELECT species_id, scientific_name, common_name, taxa,
+    (EXTRACT(hour FROM "timestamp") * 60 + EXTRACT(minute FROM "timestamp"))::int AS minute_of_day,
+    COUNT(*) AS observation_count
+FROM observations_with_details
+WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
+  AND (sqlc.narg('species_ids')::bigint[] IS NULL OR species_id = ANY(sqlc.narg('species_ids')::bigint[]))
+GROUP BY species_id, scientific_name, common_name, taxa, minute_of_day
+ORDER BY species_id, minute_of_day;
+
+-- name: ObservationActivityByMonth :many
+-- ObservationActivityByMonth counts the observations of each species by
+-- month of the year.
+SELECT species_id, EXTRACT(month FROM "timestamp")::int AS month, COUNT(*) AS observation_count
+FROM observations_with_details
+WHERE (sqlc.narg('from')::timestamp IS NULL OR "timestamp" >= sqlc.narg('from')::timestamp)
+  AND (sqlc.narg('to')::timestamp IS NULL OR "timestamp" <= sqlc.narg('to')::timestamp)
+  AND (sqlc.narg('block')::int IS NULL OR block = sqlc.narg('block')::int)
+  AND (sqlc.narg('tenure')::tenure_type IS NULL OR tenure = sqlc.narg('tenure')::tenure_type)
+  AND (sqlc.narg('forest')::forest_type IS NULL OR forest = sqlc.narg('forest')::forest_type)
+  AND (sqlc.narg('site_code')::text IS NULL OR site_code = sqlc.narg('site_code'))
+  AND (sqlc.narg('taxa')::taxa IS NULL OR taxa = sqlc.narg('taxa')::taxa)
+  AND (sqlc.narg('common_name')::text IS NULL OR LOWER(common_name) = LOWER(sqlc.narg('common_name')::text))
+  AND (sqlc.narg('independence')::int IS NULL OR starts_event(id, sqlc.narg('independence')::int))
+GROUP BY species_id, month
+ORDER BY species_id, month;
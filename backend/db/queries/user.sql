@@ -0,0 +1,59 @@
+-- name: CreateUser :one
+INSERT INTO users (email, name, password_hash, role)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = $1 LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE lower(email) = lower($1) LIMIT 1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY email;
+
+-- name: UpdateUser :one
+UPDATE users
+SET name = $2, role = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users
+SET password_hash = $2
+WHERE id = $1;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
+
+-- name: CountUsersByRole :one
+SELECT COUNT(*) FROM users
+WHERE role = $1;
+
+-- name: CreateSession :one
+INSERT INTO sessions (token_hash, user_id, expires_at)
+VALUES ($1, $2, now() + sqlc.arg('lifetime')::interval)
+RETURNING *;
+
+-- name: GetSessionUser :one
+-- GetSessionUser returns the user of an unexpired session.
+SELECT u.* FROM users u
+JOIN sessions s ON s.user_id = u.id
+WHERE s.token_hash = $1 AND s.expires_at > now()
+LIMIT 1;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE token_hash = $1;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions
+WHERE user_id = $1;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= now();
@@ -24,9 +24,58 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/observations": {
+        "/api-keys": {
             "get": {
-                "description": "List observations",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "List every API key, revoked and expired ones included",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Issue an API key with scopes stats:read, export and/or import. Send it in an X-API-Key header. The key is only shown in this response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -34,72 +83,100 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "observation"
+                    "auth"
                 ],
-                "summary": "List observations",
+                "summary": "Create API key",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Result limit",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Result offset",
-                        "name": "offset",
-                        "in": "query",
-                        "required": true
+                        "description": "The API key",
+                        "name": "apiKey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.CreateAPIKeyInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/observation.ListObservationsResponse"
+                            "$ref": "#/definitions/auth.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/observations/{id}": {
-            "get": {
-                "description": "Get the detail of an observation by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+        "/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
                 ],
+                "description": "Revoke an API key. It is kept in the list with its revocation time.",
                 "tags": [
-                    "observation"
+                    "auth"
                 ],
-                "summary": "Get Observation Detail",
+                "summary": "Revoke API key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID of the observation",
+                        "description": "ID of the API key",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/observation.Observation"
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/sites": {
-            "get": {
-                "description": "List sites",
+        "/auth/login": {
+            "post": {
+                "description": "Start a session. Send the returned token in an \"Authorization: Bearer \u003ctoken\u003e\" header.",
                 "consumes": [
                     "application/json"
                 ],
@@ -107,115 +184,152 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "site"
+                    "auth"
+                ],
+                "summary": "Log in",
+                "parameters": [
+                    {
+                        "description": "Email and password",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginRequest"
+                        }
+                    }
                 ],
-                "summary": "List sites",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/db.Site"
-                            }
+                            "$ref": "#/definitions/auth.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/sites/{code}": {
-            "get": {
-                "description": "Get the detail of a site by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "site"
-                ],
-                "summary": "Get Site Detail",
-                "parameters": [
+        "/auth/logout": {
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Code of the site",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
+                "description": "End the session of the bearer token",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/db.Site"
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/species": {
+        "/auth/me": {
             "get": {
-                "description": "list all species",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
                 ],
+                "description": "Get the user the request is authenticated as",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "species"
+                    "auth"
                 ],
-                "summary": "List species",
+                "summary": "Current user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/db.Species"
-                            }
+                            "$ref": "#/definitions/auth.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/species/by-common-name/{name}": {
-            "get": {
-                "description": "Get species detail by common name. Case insensitive. Underscores will be replaced with spaces.",
-                "consumes": [
-                    "application/json"
+        "/auth/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
                 ],
-                "produces": [
+                "description": "Change the password of the current user. Every session of the user ends, so log in again afterwards.",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "species"
+                    "auth"
                 ],
-                "summary": "Get species detail by common name",
+                "summary": "Change password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "name of the species. Case insensitive.",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
+                        "description": "The new password",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.PasswordInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/db.Species"
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/species/observed": {
+        "/deployments": {
             "get": {
-                "description": "List species observed within a date range, optionally filtered by site",
+                "description": "List the deployments of devices and surveys at the sites, by site and start time",
                 "consumes": [
                     "application/json"
                 ],
@@ -223,26 +337,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "species"
+                    "deployment"
                 ],
-                "summary": "List observed species",
+                "summary": "List deployments",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Site code",
-                        "name": "siteCode",
+                        "format": "date-time",
+                        "description": "Only deployments still running from this date",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Start timestamp (RFC3339 format)",
-                        "name": "from",
+                        "format": "date-time",
+                        "description": "Only deployments started by this date",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End timestamp (RFC3339 format)",
-                        "name": "to",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "audio",
+                            "camera",
+                            "observed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by method",
+                        "name": "method",
                         "in": "query"
                     }
                 ],
@@ -250,15 +377,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/species.ObservedSpeciesResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Deployment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
-            }
-        },
-        "/species/{id}": {
-            "get": {
-                "description": "Get species detail",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Record the period a device, or a survey, was recording at a site",
                 "consumes": [
                     "application/json"
                 ],
@@ -266,31 +408,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "species"
+                    "deployment"
                 ],
-                "summary": "Get species detail",
+                "summary": "Create deployment",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "id of the species",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "The deployment",
+                        "name": "deployment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/deployment.DeploymentInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/db.Species"
+                            "$ref": "#/definitions/db.Deployment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/stats/dashboard": {
+        "/deployments/{id}": {
             "get": {
-                "description": "Dashboard stats",
+                "description": "Get a deployment by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -298,38 +466,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "statistics"
+                    "deployment"
                 ],
-                "summary": "Dashboard stats",
+                "summary": "Get deployment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Search start from",
-                        "name": "from",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Search end to",
-                        "name": "to",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "ID of the deployment",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/stats.DashboardStatsResponse"
+                            "$ref": "#/definitions/db.Deployment"
                         }
-                    }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
                 }
-            }
-        },
-        "/stats/observations": {
-            "get": {
-                "description": "Observation overview",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Replace every value of a deployment",
                 "consumes": [
                     "application/json"
                 ],
@@ -337,72 +516,141 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "statistics"
+                    "deployment"
                 ],
-                "summary": "Observation overview",
+                "summary": "Replace deployment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Search start from",
-                        "name": "from",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "ID of the deployment",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Search end to",
-                        "name": "to",
-                        "in": "query"
+                        "description": "The deployment",
+                        "name": "deployment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/deployment.DeploymentInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Deployment"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "description": "Filter by site block",
-                        "name": "block",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by site code",
-                        "name": "siteCode",
-                        "in": "query"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Filter by taxa",
-                        "name": "taxa",
-                        "in": "query"
+                        "BearerAuth": []
                     },
                     {
-                        "type": "string",
-                        "description": "Filter by species common_name",
-                        "name": "commonName",
-                        "in": "query"
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete a deployment by ID",
+                "tags": [
+                    "deployment"
+                ],
+                "summary": "Delete deployment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the deployment",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/stats.ObservationOverviewResponse"
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/stats/observations/blocks": {
+        "/export/dwca": {
             "get": {
-                "description": "Observation stats group by blocks",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
                 ],
+                "description": "Export observations as a Darwin Core Archive (occurrence.txt, meta.xml and eml.xml) for the Atlas of Living Australia and GBIF",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "statistics"
+                    "export"
                 ],
-                "summary": "Observation stats group by blocks",
+                "summary": "Darwin Core Archive export",
                 "parameters": [
                     {
                         "type": "string",
@@ -424,6 +672,26 @@ const docTemplate = `{
                         "name": "block",
                         "in": "query"
                     },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Filter by site code",
@@ -441,44 +709,113 @@ const docTemplate = `{
                         "description": "Filter by species common name",
                         "name": "commonName",
                         "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only observations starting an independent event, after this many minutes without one of the species at the site by the same method",
+                        "name": "independence",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/stats.ObservationByBlocksResponse"
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/stats/observations/sites": {
+        "/export/occupancy": {
             "get": {
-                "description": "Observation stats group by sites",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
                 ],
+                "description": "Export the site by occasion detection history of a species for occupancy models, such as those of the R package unmarked. The species is given by speciesId, or by commonName when no other species shares it. Occasions of occasionDays run from the start of from to the end of to. Each site matching the filters gets 1 when the species was detected in an occasion, 0 when the site was surveyed without detecting it and NA when it was not surveyed, that is when none of its deployments overlap the occasion and it has no observation of any species in it. The site covariates (block, tenure and forest) come as a separate table. The csv format is a zip of detections.csv and covariates.csv.",
                 "produces": [
+                    "application/zip",
                     "application/json"
                 ],
                 "tags": [
-                    "statistics"
+                    "export"
                 ],
-                "summary": "Observation stats group by sites",
+                "summary": "Occupancy detection history export",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "date-time",
-                        "description": "Search start from",
+                        "format": "date",
+                        "description": "Start of the first occasion",
                         "name": "from",
-                        "in": "query"
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "format": "date-time",
-                        "description": "Search end to",
+                        "format": "date",
+                        "description": "Last day of the period",
                         "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the species",
+                        "name": "speciesId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Common name of the species, when speciesId is missing",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 366,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Length of each occasion in days",
+                        "name": "occasionDays",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "audio",
+                            "camera",
+                            "observed"
+                        ],
+                        "type": "string",
+                        "description": "Only count the surveys and observations of this method",
+                        "name": "method",
                         "in": "query"
                     },
                     {
@@ -487,6 +824,26 @@ const docTemplate = `{
                         "name": "block",
                         "in": "query"
                     },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Filter by site code",
@@ -495,14 +852,27 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Filter by taxa",
+                        "description": "Only count detections of the species if it is of this taxa",
                         "name": "taxa",
                         "in": "query"
                     },
                     {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only count detections starting an independent event, after this many minutes without one of the species at the site by the same method",
+                        "name": "independence",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
                         "type": "string",
-                        "description": "Filter by species common name",
-                        "name": "commonName",
+                        "default": "csv",
+                        "description": "Format of the export",
+                        "name": "format",
                         "in": "query"
                     }
                 ],
@@ -510,56 +880,241 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/stats.ObservationBySitesResponse"
+                            "$ref": "#/definitions/occupancy.History"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
                         }
                     }
                 }
             }
         },
-        "/stats/observations/timeseries": {
+        "/imports": {
             "get": {
-                "description": "Observation time series",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    },
+                    {
+                        "APIKeyAuth": []
+                    }
                 ],
+                "description": "List past import batches, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "statistics"
+                    "import"
                 ],
-                "summary": "Observation time series",
-                "parameters": [
+                "summary": "List imports",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/imports.Batch"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Search start from",
-                        "name": "from",
-                        "in": "query"
+                        "BearerAuth": []
                     },
                     {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Search end to",
-                        "name": "to",
-                        "in": "query"
+                        "BasicAuth": []
                     },
                     {
-                        "type": "integer",
-                        "description": "Filter by site block",
-                        "name": "block",
-                        "in": "query"
+                        "APIKeyAuth": []
+                    }
+                ],
+                "description": "Import an uploaded CSV, Darwin Core Archive, BirdNET results, MegaDetector output or deployments CSV file as one import batch, like the importer command. Every row is validated first: when any is rejected nothing is imported and the report lists the errors. With dryRun the file is only validated.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import a file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "The file to import",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     },
                     {
+                        "enum": [
+                            "csv",
+                            "dwca",
+                            "birdnet",
+                            "megadetector",
+                            "deployments"
+                        ],
                         "type": "string",
-                        "description": "Filter by site code",
-                        "name": "siteCode",
-                        "in": "query"
+                        "description": "Format of the file",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Filter by taxa",
-                        "name": "taxa",
+                        "type": "boolean",
+                        "description": "Only validate the file",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "update",
+                            "skip"
+                        ],
+                        "type": "string",
+                        "description": "What to do with rows already imported",
+                        "name": "onConflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/importer.Report"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/importer.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/importer.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/observations": {
+            "get": {
+                "description": "List the observations matching the filters, a page at a time. Pass the nextCursor of a page as the cursor of the next request; it is null on the last page. The offset parameter and the count field are deprecated, in favour of the cursor and total, and will be removed in the next release.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "observation"
+                ],
+                "summary": "List observations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
                         "in": "query"
                     },
                     {
@@ -567,84 +1122,3586 @@ const docTemplate = `{
                         "description": "Filter by species common name",
                         "name": "commonName",
                         "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only observations starting an independent event, after this many minutes without one of the species at the site by the same method",
+                        "name": "independence",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "audio",
+                            "camera",
+                            "observed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by observation method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only observations with at least this confidence",
+                        "name": "minConfidence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by native species",
+                        "name": "native",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by indicator species",
+                        "name": "indicator",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "timestamp",
+                            "-timestamp"
+                        ],
+                        "type": "string",
+                        "default": "timestamp",
+                        "description": "Order by timestamp, oldest or newest first",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Deprecated: number of observations to skip, not allowed with cursor",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nest the site and/or species in each observation, e.g. site,species",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/observation.ListObservationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Record a new observation of a known site and species",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "observation"
+                ],
+                "summary": "Create observation",
+                "parameters": [
+                    {
+                        "description": "The observation",
+                        "name": "observation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/observation.ObservationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/db.Observation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/observations/{id}": {
+            "get": {
+                "description": "Get the detail of an observation by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "observation"
+                ],
+                "summary": "Get Observation Detail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the observation",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nest the site and/or species in the observation, e.g. site,species",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/observation.ObservationDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Replace every value of an observation",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "observation"
+                ],
+                "summary": "Replace observation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the observation",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The observation",
+                        "name": "observation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/observation.ObservationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Observation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete an observation by ID",
+                "tags": [
+                    "observation"
+                ],
+                "summary": "Delete observation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the observation",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Change some values of an observation. Fields missing from the body are kept, fields set to null are cleared.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "observation"
+                ],
+                "summary": "Edit observation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the observation",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The values to change",
+                        "name": "observation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/observation.ObservationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Observation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Full-text search of site codes and names, species common and scientific names, and observation narratives. Every word of the query must match, as a word prefix and ignoring case and plural forms. Results are ranked and grouped by type, observations also matching by their site or species; limit and offset page each group.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Search",
+                "parameters": [
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "sites",
+                            "species",
+                            "observations"
+                        ],
+                        "type": "string",
+                        "description": "Type limits the search to one entity type",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/search.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites": {
+            "get": {
+                "description": "List sites",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "List sites",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Site"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Add a monitoring site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "Create site",
+                "parameters": [
+                    {
+                        "description": "The site",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/site.CreateSiteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/db.Site"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites/search": {
+            "get": {
+                "description": "Find the sites whose code or name contains the query, ignoring case",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "Search sites",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text to search for",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Site"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites/{code}": {
+            "get": {
+                "description": "Get the detail of a site by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "Get Site Detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of the site",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Site"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Replace the values of a site. Its code cannot change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "site"
+                ],
+                "summary": "Update site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of the site",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The site",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/site.SiteInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Site"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete a site. A site with observations or deployments is only deleted, along with them, when cascade is set.",
+                "tags": [
+                    "site"
+                ],
+                "summary": "Delete site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of the site",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete the observations and deployments of the site too",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/species": {
+            "get": {
+                "description": "list all species",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "List species",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Species"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Add a species to the catalogue",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "Create species",
+                "parameters": [
+                    {
+                        "description": "The species",
+                        "name": "species",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/species.SpeciesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/db.Species"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/species/by-common-name/{name}": {
+            "get": {
+                "description": "Get species detail by common name. Case insensitive. Underscores will be replaced with spaces.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "Get species detail by common name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the species. Case insensitive.",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Species"
+                        }
+                    }
+                }
+            }
+        },
+        "/species/observed": {
+            "get": {
+                "description": "List species observed within a date range, optionally filtered by site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "List observed species",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start timestamp (RFC3339 format)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End timestamp (RFC3339 format)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/species.ObservedSpeciesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/species/search": {
+            "get": {
+                "description": "Find the species whose scientific or common name contains the query, ignoring case",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "Search species",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text to search for",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Species"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/species/{id}": {
+            "get": {
+                "description": "Get species detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "Get species detail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the species",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Species"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Replace the values of a species",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "Update species",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the species",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The species",
+                        "name": "species",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/species.SpeciesInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Species"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete a species without observations. Merge a species with observations into another instead.",
+                "tags": [
+                    "species"
+                ],
+                "summary": "Delete species",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the species",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/species/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Move the observations of a duplicate species to the target species, then delete the duplicate. Observations the target already has are dropped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "species"
+                ],
+                "summary": "Merge species",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the duplicate species",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The species to merge into",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/species.MergeSpeciesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/species.MergeSpeciesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/activity": {
+            "get": {
+                "description": "Hour-of-day, and optionally month-of-year, histograms of the observations of each species or taxa. Density adds von Mises kernel density curves of the time of day. Giving speciesA and speciesB estimates the overlap of their activity following Ridout and Linkie (2009), with Δ̂₁ below 75 observations of either species and Δ̂₄ above. The overlap ignores the taxa and commonName filters, which only narrow the histograms.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Diel activity patterns",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "species",
+                            "taxa"
+                        ],
+                        "type": "string",
+                        "default": "species",
+                        "description": "Histogram per species or taxa",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add month-of-year histograms",
+                        "name": "months",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add kernel density curves",
+                        "name": "density",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of a species to compare the activity of, whatever its taxa and common name",
+                        "name": "speciesA",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the species to compare it with",
+                        "name": "speciesB",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.ActivityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/dashboard": {
+            "get": {
+                "description": "Dashboard stats",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Dashboard stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.DashboardStatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/diversity": {
+            "get": {
+                "description": "Shannon H′, Simpson's index of diversity (1 - D), Pielou's evenness and Chao1 estimated richness per site, per block and overall, taking the observation count of a species, or its independent event count, as its abundance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Diversity indices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.DiversityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/observations": {
+            "get": {
+                "description": "Observation overview",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Observation overview",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common_name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.ObservationOverviewResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/observations/accumulation": {
+            "get": {
+                "description": "Cumulative distinct species against sampling days, the calendar days with observations at a site, block or overall, or against observations. Rarefaction averages the curve over random orders of the samples, with a 95% interval.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Species accumulation curves",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "days",
+                            "observations"
+                        ],
+                        "type": "string",
+                        "default": "days",
+                        "description": "Sampling unit",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "overall",
+                            "site",
+                            "block"
+                        ],
+                        "type": "string",
+                        "default": "overall",
+                        "description": "Curve per site or block",
+                        "name": "by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add randomised rarefaction curves",
+                        "name": "rarefaction",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 10,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Random orders to average over",
+                        "name": "permutations",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seed of the random orders",
+                        "name": "seed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.AccumulationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/observations/blocks": {
+            "get": {
+                "description": "Observation stats group by blocks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Observation stats group by blocks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.ObservationByBlocksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/observations/forests": {
+            "get": {
+                "description": "Compare the detections and species richness of the sites in dry and wet forest, in total and per active site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Observation stats compared by forest type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.ObservationByForestsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/observations/sites": {
+            "get": {
+                "description": "Observation stats group by sites. Sites with deployments in the period also get their survey effort, with camera observations per 100 trap-nights and audio observations per recording hour, and are listed with zero counts and rates when nothing was observed there.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Observation stats group by sites",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.ObservationBySitesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/observations/tenures": {
+            "get": {
+                "description": "Compare the detections and species richness of the sites on public and private land, in total and per active site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Observation stats compared by tenure",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.ObservationByTenuresResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/observations/timeseries": {
+            "get": {
+                "description": "Observation and species counts per time bucket, in one series per native status, taxa, method, forest or tenure. Every series has a point for each bucket of the period, counting zero when there were no observations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "statistics"
+                ],
+                "summary": "Observation time series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search start from",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Search end to",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by site block",
+                        "name": "block",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "public",
+                            "private"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site tenure",
+                        "name": "tenure",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dry",
+                            "wet"
+                        ],
+                        "type": "string",
+                        "description": "Filter by site forest type",
+                        "name": "forest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by site code",
+                        "name": "siteCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by taxa",
+                        "name": "taxa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by species common name",
+                        "name": "commonName",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10080,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one",
+                        "name": "independence",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month",
+                            "quarter",
+                            "season",
+                            "year"
+                        ],
+                        "type": "string",
+                        "default": "year",
+                        "description": "Bucket width, austral seasons starting in December",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "native",
+                            "taxa",
+                            "method",
+                            "forest",
+                            "tenure"
+                        ],
+                        "type": "string",
+                        "default": "native",
+                        "description": "Series to split the counts into",
+                        "name": "groupBy",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.ObservationTimeSeriesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "List every user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.User"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Add a user with a role: viewer, ecologist or admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "The user",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.CreateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Change the name and role of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The user",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.UpdateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete a user and end their sessions",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Set the password of a user, ending their sessions",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The new password",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.PasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.HttpError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth.CreateAPIKeyInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expiresInDays": {
+                    "description": "ExpiresInDays leaves the key valid forever when missing",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "$ref": "#/definitions/auth.APIKey"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.CreateUserInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "bcrypt only uses the first 72 bytes",
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                },
+                "role": {
+                    "$ref": "#/definitions/db.UserRole"
+                }
+            }
+        },
+        "auth.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Token is sent back in an \"Authorization: Bearer \u003ctoken\u003e\" header",
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/auth.User"
+                }
+            }
+        },
+        "auth.PasswordInput": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "description": "bcrypt only uses the first 72 bytes",
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                }
+            }
+        },
+        "auth.UpdateUserInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/db.UserRole"
+                }
+            }
+        },
+        "auth.User": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/db.UserRole"
+                }
+            }
+        },
+        "db.Deployment": {
+            "type": "object",
+            "properties": {
+                "deviceId": {
+                    "type": "string"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "importBatchId": {
+                    "type": "integer"
+                },
+                "method": {
+                    "$ref": "#/definitions/db.ObservationMethod"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "siteId": {
+                    "type": "integer"
+                },
+                "startTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "db.ForestType": {
+            "type": "string",
+            "enum": [
+                "dry",
+                "wet"
+            ],
+            "x-enum-varnames": [
+                "ForestTypeDry",
+                "ForestTypeWet"
+            ]
+        },
+        "db.FullTextSearchObservationsRow": {
+            "type": "object",
+            "properties": {
+                "commonName": {
+                    "type": "string"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "file": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "$ref": "#/definitions/db.ObservationMethod"
+                },
+                "narrative": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "scientificName": {
+                    "type": "string"
+                },
+                "siteCode": {
+                    "type": "string"
+                },
+                "siteId": {
+                    "type": "integer"
+                },
+                "speciesId": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "db.FullTextSearchSitesRow": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "forest": {
+                    "$ref": "#/definitions/db.ForestType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "tenure": {
+                    "$ref": "#/definitions/db.TenureType"
+                }
+            }
+        },
+        "db.FullTextSearchSpeciesRow": {
+            "type": "object",
+            "properties": {
+                "commonName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "indicator": {
+                    "type": "boolean"
+                },
+                "native": {
+                    "type": "boolean"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "reportable": {
+                    "type": "boolean"
+                },
+                "scientificName": {
+                    "type": "string"
+                },
+                "taxa": {
+                    "$ref": "#/definitions/db.Taxa"
+                }
+            }
+        },
+        "db.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "running",
+                "completed",
+                "failed",
+                "reverted"
+            ],
+            "x-enum-varnames": [
+                "ImportStatusRunning",
+                "ImportStatusCompleted",
+                "ImportStatusFailed",
+                "ImportStatusReverted"
+            ]
+        },
+        "db.Observation": {
+            "type": "object",
+            "properties": {
+                "appearanceEnd": {
+                    "type": "integer"
+                },
+                "appearanceStart": {
+                    "type": "integer"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "file": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "importBatchId": {
+                    "type": "integer"
+                },
+                "method": {
+                    "$ref": "#/definitions/db.ObservationMethod"
+                },
+                "narrative": {
+                    "type": "string"
+                },
+                "siteId": {
+                    "type": "integer"
+                },
+                "speciesId": {
+                    "type": "integer"
+                },
+                "temperature": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "db.ObservationMethod": {
+            "type": "string",
+            "enum": [
+                "audio",
+                "camera",
+                "observed"
+            ],
+            "x-enum-varnames": [
+                "ObservationMethodAudio",
+                "ObservationMethodCamera",
+                "ObservationMethodObserved"
+            ]
+        },
+        "db.Site": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "forest": {
+                    "$ref": "#/definitions/db.ForestType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "importBatchId": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tenure": {
+                    "$ref": "#/definitions/db.TenureType"
+                }
+            }
+        },
+        "db.Species": {
+            "type": "object",
+            "properties": {
+                "commonName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "importBatchId": {
+                    "type": "integer"
+                },
+                "indicator": {
+                    "type": "boolean"
+                },
+                "native": {
+                    "type": "boolean"
+                },
+                "reportable": {
+                    "type": "boolean"
+                },
+                "scientificName": {
+                    "type": "string"
+                },
+                "taxa": {
+                    "$ref": "#/definitions/db.Taxa"
+                }
+            }
+        },
+        "db.Taxa": {
+            "type": "string",
+            "enum": [
+                "bird",
+                "mammal",
+                "reptile"
+            ],
+            "x-enum-varnames": [
+                "TaxaBird",
+                "TaxaMammal",
+                "TaxaReptile"
+            ]
+        },
+        "db.TenureType": {
+            "type": "string",
+            "enum": [
+                "public",
+                "private"
+            ],
+            "x-enum-varnames": [
+                "TenureTypePublic",
+                "TenureTypePrivate"
+            ]
+        },
+        "db.UserRole": {
+            "type": "string",
+            "enum": [
+                "viewer",
+                "ecologist",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "UserRoleViewer",
+                "UserRoleEcologist",
+                "UserRoleAdmin"
+            ]
+        },
+        "deployment.DeploymentInput": {
+            "type": "object",
+            "required": [
+                "endTime",
+                "method",
+                "siteId",
+                "startTime"
+            ],
+            "properties": {
+                "deviceId": {
+                    "type": "string"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "method": {
+                    "$ref": "#/definitions/db.ObservationMethod"
+                },
+                "notes": {
+                    "description": "Notes record the status of the device, e.g. a flat battery",
+                    "type": "string"
+                },
+                "siteId": {
+                    "type": "integer"
+                },
+                "startTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "importer.DuplicateRow": {
+            "type": "object",
+            "properties": {
+                "firstRow": {
+                    "type": "integer"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "importer.Report": {
+            "type": "object",
+            "properties": {
+                "batchId": {
+                    "type": "integer"
+                },
+                "columnsByPosition": {
+                    "description": "ColumnsByPosition is set when the CSV columns were read in the survey\nspreadsheet's fixed order rather than by header name.",
+                    "type": "boolean"
+                },
+                "committed": {
+                    "type": "boolean"
+                },
+                "deployments": {
+                    "description": "Deployments counts the deployments written, new or updated.",
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "duplicates": {
+                    "description": "Duplicates lists the rows repeating an earlier row's observation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/importer.DuplicateRow"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/importer.RowError"
+                    }
+                },
+                "file": {
+                    "type": "string"
+                },
+                "newSites": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "newSpecies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "observations": {
+                    "type": "integer"
+                },
+                "rejectedRows": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "Skipped counts the rows left out on purpose, by reason.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "totalRows": {
+                    "type": "integer"
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "unmappedTerms": {
+                    "description": "UnmappedTerms lists the Darwin Core terms of an archive that have no\nplace in the database and were ignored.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unsupportedClasses": {
+                    "description": "UnsupportedClasses lists the classes of the occurrences skipped for\nbeing neither birds, mammals nor reptiles.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated": {
+                    "type": "integer"
+                },
+                "validRows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "importer.RowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "imports.Batch": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "observationCount": {
+                    "type": "integer"
+                },
+                "rowCount": {
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/db.ImportStatus"
+                }
+            }
+        },
+        "observation.ListObservationsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count is Total under its former name. Deprecated: use Total, Count\ngoes in the next release.",
+                    "type": "integer"
+                },
+                "nextCursor": {
+                    "type": "string"
+                },
+                "observations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/observation.ObservationDetail"
+                    }
+                },
+                "total": {
+                    "description": "Total counts every matching observation, not only this page",
+                    "type": "integer"
+                }
+            }
+        },
+        "observation.ObservationDetail": {
+            "type": "object",
+            "properties": {
+                "appearanceEnd": {
+                    "type": "integer"
+                },
+                "appearanceStart": {
+                    "type": "integer"
+                },
+                "confidence": {
+                    "type": "number"
+                },
+                "file": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "importBatchId": {
+                    "type": "integer"
+                },
+                "method": {
+                    "$ref": "#/definitions/db.ObservationMethod"
+                },
+                "narrative": {
+                    "type": "string"
+                },
+                "site": {
+                    "$ref": "#/definitions/observation.ObservationSite"
+                },
+                "siteId": {
+                    "type": "integer"
+                },
+                "species": {
+                    "$ref": "#/definitions/observation.ObservationSpecies"
+                },
+                "speciesId": {
+                    "type": "integer"
+                },
+                "temperature": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "observation.ObservationInput": {
+            "type": "object",
+            "required": [
+                "method",
+                "siteId",
+                "speciesId",
+                "timestamp"
+            ],
+            "properties": {
+                "appearanceEnd": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "appearanceStart": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "confidence": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "file": {
+                    "type": "string"
+                },
+                "method": {
+                    "$ref": "#/definitions/db.ObservationMethod"
+                },
+                "narrative": {
+                    "type": "string"
+                },
+                "siteId": {
+                    "type": "integer"
+                },
+                "speciesId": {
+                    "type": "integer"
+                },
+                "temperature": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "observation.ObservationSite": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "forest": {
+                    "$ref": "#/definitions/db.ForestType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tenure": {
+                    "$ref": "#/definitions/db.TenureType"
+                }
+            }
+        },
+        "observation.ObservationSpecies": {
+            "type": "object",
+            "properties": {
+                "commonName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "indicator": {
+                    "type": "boolean"
+                },
+                "native": {
+                    "type": "boolean"
+                },
+                "reportable": {
+                    "type": "boolean"
+                },
+                "scientificName": {
+                    "type": "string"
+                },
+                "taxa": {
+                    "$ref": "#/definitions/db.Taxa"
+                }
+            }
+        },
+        "occupancy.History": {
+            "type": "object",
+            "properties": {
+                "covariates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/occupancy.SiteCovariates"
+                    }
+                },
+                "detections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/occupancy.SiteDetections"
                     }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/stats.ObservationTimeSeriesResponse"
-                        }
+                },
+                "occasions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/occupancy.Occasion"
                     }
+                },
+                "species": {
+                    "$ref": "#/definitions/db.Species"
                 }
             }
-        }
-    },
-    "definitions": {
-        "db.ForestType": {
-            "type": "string",
-            "enum": [
-                "dry",
-                "wet"
-            ],
-            "x-enum-varnames": [
-                "ForestTypeDry",
-                "ForestTypeWet"
-            ]
         },
-        "db.Observation": {
+        "occupancy.Occasion": {
             "type": "object",
             "properties": {
-                "appearanceEnd": {
-                    "type": "integer"
+                "end": {
+                    "type": "string"
                 },
-                "appearanceStart": {
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "occupancy.SiteCovariates": {
+            "type": "object",
+            "properties": {
+                "block": {
                     "type": "integer"
                 },
-                "confidence": {
-                    "type": "number"
+                "forest": {
+                    "$ref": "#/definitions/db.ForestType"
                 },
-                "file": {
+                "siteCode": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "method": {
-                    "$ref": "#/definitions/db.ObservationMethod"
+                "tenure": {
+                    "$ref": "#/definitions/db.TenureType"
+                }
+            }
+        },
+        "occupancy.SiteDetections": {
+            "type": "object",
+            "properties": {
+                "occasions": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "narrative": {
+                "siteCode": {
                     "type": "string"
+                }
+            }
+        },
+        "search.ObservationResults": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.FullTextSearchObservationsRow"
+                    }
                 },
-                "siteId": {
+                "total": {
                     "type": "integer"
+                }
+            }
+        },
+        "search.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "observations": {
+                    "$ref": "#/definitions/search.ObservationResults"
                 },
-                "speciesId": {
-                    "type": "integer"
+                "query": {
+                    "type": "string"
                 },
-                "temperature": {
-                    "type": "integer"
+                "sites": {
+                    "$ref": "#/definitions/search.SiteResults"
                 },
-                "timestamp": {
-                    "type": "string"
+                "species": {
+                    "$ref": "#/definitions/search.SpeciesResults"
                 }
             }
         },
-        "db.ObservationMethod": {
-            "type": "string",
-            "enum": [
-                "audio",
-                "camera",
-                "observed"
-            ],
-            "x-enum-varnames": [
-                "ObservationMethodAudio",
-                "ObservationMethodCamera",
-                "ObservationMethodObserved"
-            ]
+        "search.SiteResults": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.FullTextSearchSitesRow"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
         },
-        "db.Site": {
+        "search.SpeciesResults": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.FullTextSearchSpeciesRow"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "site.CreateSiteInput": {
             "type": "object",
+            "required": [
+                "block",
+                "code",
+                "forest",
+                "tenure"
+            ],
             "properties": {
                 "block": {
                     "type": "integer"
@@ -655,9 +4712,31 @@ const docTemplate = `{
                 "forest": {
                     "$ref": "#/definitions/db.ForestType"
                 },
-                "id": {
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tenure": {
+                    "$ref": "#/definitions/db.TenureType"
+                }
+            }
+        },
+        "site.SiteInput": {
+            "type": "object",
+            "required": [
+                "block",
+                "forest",
+                "tenure"
+            ],
+            "properties": {
+                "block": {
                     "type": "integer"
                 },
+                "forest": {
+                    "$ref": "#/definitions/db.ForestType"
+                },
                 "location": {
                     "type": "string"
                 },
@@ -669,175 +4748,372 @@ const docTemplate = `{
                 }
             }
         },
-        "db.Species": {
+        "species.MergeSpeciesRequest": {
             "type": "object",
+            "required": [
+                "targetId"
+            ],
             "properties": {
-                "commonName": {
+                "targetId": {
+                    "description": "TargetID is the species that keeps the observations",
+                    "type": "integer"
+                }
+            }
+        },
+        "species.MergeSpeciesResponse": {
+            "type": "object",
+            "properties": {
+                "duplicateObservations": {
+                    "type": "integer"
+                },
+                "movedObservations": {
+                    "type": "integer"
+                },
+                "species": {
+                    "$ref": "#/definitions/db.Species"
+                }
+            }
+        },
+        "species.ObservedSpecies": {
+            "type": "object",
+            "properties": {
+                "common_name": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "observation_count": {
+                    "type": "integer"
+                },
+                "scientific_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "species.ObservedSpeciesResponse": {
+            "type": "object",
+            "properties": {
+                "species": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/species.ObservedSpecies"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "species.SpeciesInput": {
+            "type": "object",
+            "required": [
+                "commonName",
+                "scientificName",
+                "taxa"
+            ],
+            "properties": {
+                "commonName": {
+                    "type": "string"
+                },
                 "indicator": {
                     "type": "boolean"
                 },
-                "native": {
-                    "type": "boolean"
+                "native": {
+                    "type": "boolean"
+                },
+                "reportable": {
+                    "type": "boolean"
+                },
+                "scientificName": {
+                    "type": "string"
+                },
+                "taxa": {
+                    "$ref": "#/definitions/db.Taxa"
+                }
+            }
+        },
+        "stats.AccumulationCurve": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "type": "integer"
+                },
+                "observed": {
+                    "description": "Observed counts the species found in time order. It only has the\npoints where new species were found, and the first and last ones.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.AccumulationPoint"
+                    }
+                },
+                "rarefied": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.RarefactionPoint"
+                    }
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "siteCode": {
+                    "type": "string"
+                },
+                "species": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stats.AccumulationPoint": {
+            "type": "object",
+            "properties": {
+                "samples": {
+                    "type": "integer"
+                },
+                "species": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stats.AccumulationResponse": {
+            "type": "object",
+            "properties": {
+                "curves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.AccumulationCurve"
+                    }
+                },
+                "seed": {
+                    "description": "Seed reproduces the rarefied curves when sent back",
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "stats.ActivityGroup": {
+            "type": "object",
+            "properties": {
+                "commonName": {
+                    "type": "string"
+                },
+                "density": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.DensityPoint"
+                    }
+                },
+                "hours": {
+                    "description": "Hours counts the observations in each hour of the day, local time",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "reportable": {
-                    "type": "boolean"
+                "months": {
+                    "description": "Months counts the observations in each month, January first",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "observationCount": {
+                    "type": "integer"
                 },
                 "scientificName": {
                     "type": "string"
                 },
+                "speciesId": {
+                    "type": "integer"
+                },
                 "taxa": {
                     "$ref": "#/definitions/db.Taxa"
                 }
             }
         },
-        "db.Taxa": {
-            "type": "string",
-            "enum": [
-                "bird",
-                "mammal",
-                "reptile"
-            ],
-            "x-enum-varnames": [
-                "TaxaBird",
-                "TaxaMammal",
-                "TaxaReptile"
-            ]
-        },
-        "db.TenureType": {
-            "type": "string",
-            "enum": [
-                "public",
-                "private"
-            ],
-            "x-enum-varnames": [
-                "TenureTypePublic",
-                "TenureTypePrivate"
-            ]
-        },
-        "observation.ListObservationsResponse": {
+        "stats.ActivityOverlap": {
             "type": "object",
             "properties": {
-                "count": {
+                "estimator": {
+                    "type": "string",
+                    "enum": [
+                        "Dhat1",
+                        "Dhat4"
+                    ]
+                },
+                "overlap": {
+                    "type": "number"
+                },
+                "speciesA": {
                     "type": "integer"
                 },
-                "observations": {
+                "speciesB": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stats.ActivityResponse": {
+            "type": "object",
+            "properties": {
+                "groups": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/db.Observation"
+                        "$ref": "#/definitions/stats.ActivityGroup"
                     }
+                },
+                "overlap": {
+                    "$ref": "#/definitions/stats.ActivityOverlap"
                 }
             }
         },
-        "observation.Observation": {
+        "stats.BlockDiversity": {
             "type": "object",
             "properties": {
-                "appearanceIime": {
-                    "type": "object",
-                    "properties": {
-                        "end": {
-                            "type": "integer"
-                        },
-                        "start": {
-                            "type": "integer"
-                        }
-                    }
+                "block": {
+                    "type": "integer"
                 },
-                "confidence": {
+                "chao1": {
+                    "description": "Chao1 estimates the total richness from the species observed once and\ntwice, bias-corrected when no species was observed twice.",
                     "type": "number"
                 },
-                "id": {
+                "observationCount": {
                     "type": "integer"
                 },
-                "indicator": {
-                    "type": "boolean"
-                },
-                "method": {
-                    "$ref": "#/definitions/db.ObservationMethod"
+                "pielou": {
+                    "description": "Pielou is Pielou's evenness J′ = H′ / ln S. It needs two species.",
+                    "type": "number"
                 },
-                "narrative": {
-                    "type": "string"
+                "richness": {
+                    "description": "Richness is the number of species observed",
+                    "type": "integer"
                 },
-                "reportable": {
-                    "type": "boolean"
+                "shannon": {
+                    "description": "Shannon is the Shannon index H′ = -Σ pᵢ ln pᵢ",
+                    "type": "number"
                 },
-                "siteId": {
+                "simpson": {
+                    "description": "Simpson is Simpson's index of diversity 1 - D, the probability that\ntwo observations drawn without replacement are of different species.\nIt needs two observations.",
+                    "type": "number"
+                }
+            }
+        },
+        "stats.BlockResponse": {
+            "type": "object",
+            "properties": {
+                "block": {
                     "type": "integer"
                 },
-                "speciesId": {
+                "observationCount": {
                     "type": "integer"
                 },
-                "temperature": {
+                "speciesCount": {
                     "type": "integer"
-                },
-                "timestamp": {
-                    "type": "string"
                 }
             }
         },
-        "species.ObservedSpecies": {
+        "stats.DashboardStatsResponse": {
             "type": "object",
             "properties": {
-                "common_name": {
-                    "type": "string"
+                "nativeSpeciesCount": {
+                    "type": "integer"
                 },
-                "id": {
+                "observationCount": {
                     "type": "integer"
                 },
-                "observation_count": {
+                "sitesCount": {
                     "type": "integer"
                 },
-                "scientific_name": {
-                    "type": "string"
+                "speciesCount": {
+                    "type": "integer"
                 }
             }
         },
-        "species.ObservedSpeciesResponse": {
+        "stats.DensityPoint": {
             "type": "object",
             "properties": {
-                "species": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/species.ObservedSpecies"
-                    }
+                "density": {
+                    "type": "number"
                 },
-                "total": {
-                    "type": "integer"
+                "hour": {
+                    "type": "number"
                 }
             }
         },
-        "stats.BlockResponse": {
+        "stats.DiversityIndices": {
             "type": "object",
             "properties": {
-                "block": {
-                    "type": "integer"
+                "chao1": {
+                    "description": "Chao1 estimates the total richness from the species observed once and\ntwice, bias-corrected when no species was observed twice.",
+                    "type": "number"
                 },
                 "observationCount": {
                     "type": "integer"
                 },
-                "speciesCount": {
+                "pielou": {
+                    "description": "Pielou is Pielou's evenness J′ = H′ / ln S. It needs two species.",
+                    "type": "number"
+                },
+                "richness": {
+                    "description": "Richness is the number of species observed",
                     "type": "integer"
+                },
+                "shannon": {
+                    "description": "Shannon is the Shannon index H′ = -Σ pᵢ ln pᵢ",
+                    "type": "number"
+                },
+                "simpson": {
+                    "description": "Simpson is Simpson's index of diversity 1 - D, the probability that\ntwo observations drawn without replacement are of different species.\nIt needs two observations.",
+                    "type": "number"
                 }
             }
         },
-        "stats.DashboardStatsResponse": {
+        "stats.DiversityResponse": {
             "type": "object",
             "properties": {
-                "nativeSpeciesCount": {
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.BlockDiversity"
+                    }
+                },
+                "overall": {
+                    "$ref": "#/definitions/stats.DiversityIndices"
+                },
+                "sites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.SiteDiversity"
+                    }
+                }
+            }
+        },
+        "stats.ForestComparison": {
+            "type": "object",
+            "properties": {
+                "activeSiteCount": {
+                    "description": "ActiveSiteCount counts those with observations in the period",
                     "type": "integer"
                 },
+                "forest": {
+                    "$ref": "#/definitions/db.ForestType"
+                },
                 "observationCount": {
                     "type": "integer"
                 },
-                "sitesCount": {
+                "observationsPerSite": {
+                    "description": "ObservationsPerSite and SpeciesPerSite are the means over the active\nsites, omitted without any.",
+                    "type": "number"
+                },
+                "siteCount": {
+                    "description": "SiteCount counts the sites of the group matching the filters",
                     "type": "integer"
                 },
                 "speciesCount": {
                     "type": "integer"
+                },
+                "speciesPerSite": {
+                    "type": "number"
                 }
             }
         },
@@ -852,6 +5128,21 @@ const docTemplate = `{
                 }
             }
         },
+        "stats.ObservationByForestsResponse": {
+            "type": "object",
+            "properties": {
+                "forests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.ForestComparison"
+                    }
+                },
+                "sharedSpeciesCount": {
+                    "description": "SharedSpeciesCount counts the species observed in both dry and wet\nforest",
+                    "type": "integer"
+                }
+            }
+        },
         "stats.ObservationBySitesResponse": {
             "type": "object",
             "properties": {
@@ -863,6 +5154,21 @@ const docTemplate = `{
                 }
             }
         },
+        "stats.ObservationByTenuresResponse": {
+            "type": "object",
+            "properties": {
+                "sharedSpeciesCount": {
+                    "description": "SharedSpeciesCount counts the species observed on both public and\nprivate land",
+                    "type": "integer"
+                },
+                "tenures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.TenureComparison"
+                    }
+                }
+            }
+        },
         "stats.ObservationOverviewResponse": {
             "type": "object",
             "properties": {
@@ -898,9 +5204,91 @@ const docTemplate = `{
                 }
             }
         },
+        "stats.RarefactionPoint": {
+            "type": "object",
+            "properties": {
+                "lower": {
+                    "type": "number"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "upper": {
+                    "type": "number"
+                }
+            }
+        },
+        "stats.SiteDiversity": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "type": "integer"
+                },
+                "chao1": {
+                    "description": "Chao1 estimates the total richness from the species observed once and\ntwice, bias-corrected when no species was observed twice.",
+                    "type": "number"
+                },
+                "observationCount": {
+                    "type": "integer"
+                },
+                "pielou": {
+                    "description": "Pielou is Pielou's evenness J′ = H′ / ln S. It needs two species.",
+                    "type": "number"
+                },
+                "richness": {
+                    "description": "Richness is the number of species observed",
+                    "type": "integer"
+                },
+                "shannon": {
+                    "description": "Shannon is the Shannon index H′ = -Σ pᵢ ln pᵢ",
+                    "type": "number"
+                },
+                "simpson": {
+                    "description": "Simpson is Simpson's index of diversity 1 - D, the probability that\ntwo observations drawn without replacement are of different species.\nIt needs two observations.",
+                    "type": "number"
+                },
+                "siteCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "stats.SiteEffort": {
+            "type": "object",
+            "properties": {
+                "audioObservations": {
+                    "type": "integer"
+                },
+                "cameraObservations": {
+                    "type": "integer"
+                },
+                "perHundredTrapNights": {
+                    "type": "number"
+                },
+                "perRecordingHour": {
+                    "type": "number"
+                },
+                "recordingHours": {
+                    "type": "number"
+                },
+                "trapNights": {
+                    "type": "number"
+                }
+            }
+        },
         "stats.SiteResponse": {
             "type": "object",
             "properties": {
+                "effort": {
+                    "description": "Effort is the survey effort of the site's deployments in the period,\nomitted when it has none.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/stats.SiteEffort"
+                        }
+                    ]
+                },
                 "observationCount": {
                     "type": "integer"
                 },
@@ -912,6 +5300,35 @@ const docTemplate = `{
                 }
             }
         },
+        "stats.TenureComparison": {
+            "type": "object",
+            "properties": {
+                "activeSiteCount": {
+                    "description": "ActiveSiteCount counts those with observations in the period",
+                    "type": "integer"
+                },
+                "observationCount": {
+                    "type": "integer"
+                },
+                "observationsPerSite": {
+                    "description": "ObservationsPerSite and SpeciesPerSite are the means over the active\nsites, omitted without any.",
+                    "type": "number"
+                },
+                "siteCount": {
+                    "description": "SiteCount counts the sites of the group matching the filters",
+                    "type": "integer"
+                },
+                "speciesCount": {
+                    "type": "integer"
+                },
+                "speciesPerSite": {
+                    "type": "number"
+                },
+                "tenure": {
+                    "$ref": "#/definitions/db.TenureType"
+                }
+            }
+        },
         "stats.TimeSeriesPoint": {
             "type": "object",
             "properties": {
@@ -925,11 +5342,37 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "utils.HttpError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "APIKeyAuth": {
+            "description": "API key from /api-keys, for scripts and partners",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BasicAuth": {
             "type": "basic"
+        },
+        "BearerAuth": {
+            "description": "Session token from /auth/login, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     },
     "externalDocs": {
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Scopes of API keys.
+const (
+	ScopeStatsRead = "stats:read"
+	ScopeExport    = "export"
+	ScopeImport    = "import"
+)
+
+var Scopes = []string{ScopeStatsRead, ScopeExport, ScopeImport}
+
+// APIKeyHeader is the header API keys are sent in.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix starts every key, so that leaked keys are easy to spot.
+const apiKeyPrefix = "nlk_"
+
+var ErrInvalidAPIKey = errors.New("invalid, expired or revoked API key")
+
+// CreateAPIKey issues a key with the given scopes, expiring after
+// expiresInDays unless that is nil. The key is only returned here, the
+// database keeps its hash.
+func CreateAPIKey(ctx context.Context, q db.Querier, name string, scopes []string, createdBy *int64, expiresInDays *int32) (key string, apiKey db.ApiKey, err error) {
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return "", db.ApiKey{}, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	key = apiKeyPrefix + newToken()
+	apiKey, err = q.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		Name:          name,
+		Prefix:        key[:len(apiKeyPrefix)+8],
+		KeyHash:       HashToken(key),
+		Scopes:        scopes,
+		CreatedBy:     createdBy,
+		ExpiresInDays: expiresInDays,
+	})
+	if err != nil {
+		return "", db.ApiKey{}, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return key, apiKey, nil
+}
+
+// AuthenticateKey returns the API key if it is valid, recording its use.
+func AuthenticateKey(ctx context.Context, q db.Querier, key string) (db.ApiKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return db.ApiKey{}, ErrInvalidAPIKey
+	}
+	apiKey, err := q.UseAPIKey(ctx, HashToken(key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.ApiKey{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return db.ApiKey{}, fmt.Errorf("failed to check API key: %w", err)
+	}
+	return apiKey, nil
+}
+
+const apiKeyKey = "auth.apiKey"
+
+// SetAPIKey records the API key the request is authenticated with.
+func SetAPIKey(c *gin.Context, key db.ApiKey) {
+	c.Set(apiKeyKey, key)
+}
+
+// CurrentAPIKey returns the API key of the request, if any.
+func CurrentAPIKey(c *gin.Context) (db.ApiKey, bool) {
+	key, ok := c.Get(apiKeyKey)
+	if !ok {
+		return db.ApiKey{}, false
+	}
+	return key.(db.ApiKey), true
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionLifetime is how long a login lasts.
+const SessionLifetime = 7 * 24 * time.Hour
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+)
+
+// roleRank orders the roles, each allowed everything the lower ones are.
+var roleRank = map[db.UserRole]int{
+	db.UserRoleViewer:    1,
+	db.UserRoleEcologist: 2,
+	db.UserRoleAdmin:     3,
+}
+
+// Allows reports whether a user with role may do what required is needed
+// for.
+func Allows(role, required db.UserRole) bool {
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[required]
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// dummyHash is checked against when there is no user with the email, so
+// that unknown emails take as long to reject as wrong passwords.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword(rand.Text())
+	return hash
+})
+
+// CheckCredentials returns the user with the email and password.
+func CheckCredentials(ctx context.Context, q db.Querier, email, password string) (db.User, error) {
+	user, err := q.GetUserByEmail(ctx, email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		CheckPassword(dummyHash(), password)
+		return db.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return db.User{}, fmt.Errorf("failed to get user by email: %w", err)
+	}
+	if !CheckPassword(user.PasswordHash, password) {
+		return db.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Login checks the credentials and starts a session, returning its token.
+// Only the hash of the token is stored. Expired sessions are cleared out on
+// the way.
+func Login(ctx context.Context, q db.Querier, email, password string) (token string, session db.Session, user db.User, err error) {
+	user, err = CheckCredentials(ctx, q, email, password)
+	if err != nil {
+		return
+	}
+	if _, err = q.DeleteExpiredSessions(ctx); err != nil {
+		err = fmt.Errorf("failed to delete expired sessions: %w", err)
+		return
+	}
+
+	token = newToken()
+	session, err = q.CreateSession(ctx, db.CreateSessionParams{
+		TokenHash: HashToken(token),
+		UserID:    user.ID,
+		Lifetime:  pgtype.Interval{Microseconds: SessionLifetime.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to create session: %w", err)
+	}
+	return
+}
+
+// Authenticate returns the user of an unexpired session token.
+func Authenticate(ctx context.Context, q db.Querier, token string) (db.User, error) {
+	user, err := q.GetSessionUser(ctx, HashToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.User{}, ErrInvalidToken
+	}
+	if err != nil {
+		return db.User{}, fmt.Errorf("failed to get session: %w", err)
+	}
+	return user, nil
+}
+
+// newToken returns a random 256-bit token.
+func newToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func HashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
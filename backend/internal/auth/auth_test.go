@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		role, required db.UserRole
+		want           bool
+	}{
+		{db.UserRoleViewer, db.UserRoleViewer, true},
+		{db.UserRoleViewer, db.UserRoleEcologist, false},
+		{db.UserRoleViewer, db.UserRoleAdmin, false},
+		{db.UserRoleEcologist, db.UserRoleViewer, true},
+		{db.UserRoleEcologist, db.UserRoleEcologist, true},
+		{db.UserRoleEcologist, db.UserRoleAdmin, false},
+		{db.UserRoleAdmin, db.UserRoleViewer, true},
+		{db.UserRoleAdmin, db.UserRoleEcologist, true},
+		{db.UserRoleAdmin, db.UserRoleAdmin, true},
+		// Roles the database does not know allow nothing
+		{"", db.UserRoleViewer, false},
+		{"superuser", db.UserRoleViewer, false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := Allows(tt.role, tt.required); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{"Bearer abc123", "abc123", true},
+		{"bearer abc123", "abc123", true},
+		{"Bearer  abc123 ", "abc123", true},
+		{"Bearer ", "", false},
+		{"Bearer", "", false},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", tt.header)
+		token, ok := BearerToken(r)
+		if token != tt.token || ok != tt.ok {
+			t.Errorf("BearerToken(%q) = %q, %v, want %q, %v", tt.header, token, ok, tt.token, tt.ok)
+		}
+	}
+}
+
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"correct horse", true},
+		{"Correct horse", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := CheckPassword(hash, tt.password); got != tt.want {
+			t.Errorf("CheckPassword(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestCreateAPIKeyUnknownScope(t *testing.T) {
+	// The scopes are checked before anything is written
+	var q db.Querier
+	for _, scopes := range [][]string{{"admin"}, {ScopeExport, "stats:write"}} {
+		if _, _, err := CreateAPIKey(context.Background(), q, "partner", scopes, nil, nil); err == nil {
+			t.Errorf("created a key with scopes %v", scopes)
+		}
+	}
+}
+
+func TestAuthenticateKeyPrefix(t *testing.T) {
+	// Keys without the prefix are rejected without a lookup
+	var q db.Querier
+	if _, err := AuthenticateKey(context.Background(), q, "sk_123"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("err = %v, want ErrInvalidAPIKey", err)
+	}
+}
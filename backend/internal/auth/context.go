@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const userKey = "auth.user"
+
+// SetUser records the authenticated user of the request.
+func SetUser(c *gin.Context, user db.User) {
+	c.Set(userKey, user)
+}
+
+// CurrentUser returns the authenticated user of the request, if any.
+func CurrentUser(c *gin.Context) (db.User, bool) {
+	user, ok := c.Get(userKey)
+	if !ok {
+		return db.User{}, false
+	}
+	return user.(db.User), true
+}
+
+// BearerToken returns the token of a "Bearer" Authorization header.
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,454 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type Controller struct {
+	q db.Querier
+}
+
+func NewController(queries db.Querier) *Controller {
+	return &Controller{
+		q: queries,
+	}
+}
+
+// Login godoc
+//
+//	@Summary		Log in
+//	@Description	Start a session. Send the returned token in an "Authorization: Bearer <token>" header.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			credentials	body		LoginRequest	true	"Email and password"
+//	@Success		200			{object}	LoginResponse
+//	@Failure		400			{object}	utils.HttpError
+//	@Failure		401			{object}	utils.HttpError
+//	@Router			/auth/login [post]
+func (u *Controller) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+
+	token, session, user, err := Login(c.Request.Context(), u.q, req.Email, req.Password)
+	if errors.Is(err, ErrInvalidCredentials) {
+		c.Error(utils.NewHttpError(401, "login failed", err))
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, LoginResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      NewUser(user),
+	})
+}
+
+// Logout godoc
+//
+//	@Summary		Log out
+//	@Description	End the session of the bearer token
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Success		204
+//	@Failure		401	{object}	utils.HttpError
+//	@Router			/auth/logout [post]
+func (u *Controller) Logout(c *gin.Context) {
+	if token, ok := BearerToken(c.Request); ok {
+		if err := u.q.DeleteSession(c.Request.Context(), HashToken(token)); err != nil {
+			c.Error(fmt.Errorf("failed to delete session: %w", err))
+			return
+		}
+	}
+	c.Status(204)
+}
+
+// Me godoc
+//
+//	@Summary		Current user
+//	@Description	Get the user the request is authenticated as
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Produce		json
+//	@Success		200	{object}	User
+//	@Failure		401	{object}	utils.HttpError
+//	@Router			/auth/me [get]
+func (u *Controller) Me(c *gin.Context) {
+	user, _ := CurrentUser(c)
+	c.JSON(200, NewUser(user))
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Change password
+//	@Description	Change the password of the current user. Every session of the user ends, so log in again afterwards.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Param			password	body	PasswordInput	true	"The new password"
+//	@Success		204
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Router			/auth/me/password [put]
+func (u *Controller) ChangePassword(c *gin.Context) {
+	user, _ := CurrentUser(c)
+	u.setPassword(c, user.ID)
+}
+
+// ListUsers godoc
+//
+//	@Summary		List users
+//	@Description	List every user
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Produce		json
+//	@Success		200	{object}	[]User
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Router			/users [get]
+func (u *Controller) ListUsers(c *gin.Context) {
+	users, err := u.q.ListUsers(c.Request.Context())
+	if err != nil {
+		c.Error(fmt.Errorf("failed to list users: %w", err))
+		return
+	}
+	c.JSON(200, utils.MapSlice(NewUser, users))
+}
+
+// CreateUser godoc
+//
+//	@Summary		Create user
+//	@Description	Add a user with a role: viewer, ecologist or admin
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			user	body		CreateUserInput	true	"The user"
+//	@Success		201		{object}	User
+//	@Failure		400		{object}	utils.HttpError
+//	@Failure		401		{object}	utils.HttpError
+//	@Failure		403		{object}	utils.HttpError
+//	@Failure		409		{object}	utils.HttpError
+//	@Router			/users [post]
+func (u *Controller) CreateUser(c *gin.Context) {
+	var input CreateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if !input.Role.Valid() {
+		c.Error(utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown role %q", input.Role)))
+		return
+	}
+
+	hash, err := HashPassword(input.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := u.q.CreateUser(c.Request.Context(), db.CreateUserParams{
+		Email:        input.Email,
+		Name:         input.Name,
+		PasswordHash: hash,
+		Role:         input.Role,
+	})
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "a user with this email already exists", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to create user: %w", err))
+		return
+	}
+
+	c.JSON(201, NewUser(user))
+}
+
+// UpdateUser godoc
+//
+//	@Summary		Update user
+//	@Description	Change the name and role of a user
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		integer			true	"ID of the user"
+//	@Param			user	body		UpdateUserInput	true	"The user"
+//	@Success		200		{object}	User
+//	@Failure		400		{object}	utils.HttpError
+//	@Failure		401		{object}	utils.HttpError
+//	@Failure		403		{object}	utils.HttpError
+//	@Failure		404		{object}	utils.HttpError
+//	@Failure		409		{object}	utils.HttpError
+//	@Router			/users/{id} [put]
+func (u *Controller) UpdateUser(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var input UpdateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if !input.Role.Valid() {
+		c.Error(utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown role %q", input.Role)))
+		return
+	}
+	if input.Role != db.UserRoleAdmin {
+		if err := u.keepAnAdmin(c, id); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	user, err := u.q.UpdateUser(c.Request.Context(), db.UpdateUserParams{
+		ID:   id,
+		Name: input.Name,
+		Role: input.Role,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "user not found", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to update user: %w", err))
+		return
+	}
+
+	c.JSON(200, NewUser(user))
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset password
+//	@Description	Set the password of a user, ending their sessions
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Param			id			path	integer			true	"ID of the user"
+//	@Param			password	body	PasswordInput	true	"The new password"
+//	@Success		204
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Router			/users/{id}/password [put]
+func (u *Controller) ResetPassword(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	u.setPassword(c, id)
+}
+
+// DeleteUser godoc
+//
+//	@Summary		Delete user
+//	@Description	Delete a user and end their sessions
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			id	path	integer	true	"ID of the user"
+//	@Success		204
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Failure		409	{object}	utils.HttpError
+//	@Router			/users/{id} [delete]
+func (u *Controller) DeleteUser(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := u.keepAnAdmin(c, id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	deleted, err := u.q.DeleteUser(c.Request.Context(), id)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to delete user: %w", err))
+		return
+	}
+	if deleted == 0 {
+		c.Error(utils.NewHttpError(404, "user not found", pgx.ErrNoRows))
+		return
+	}
+
+	c.Status(204)
+}
+
+func (u *Controller) setPassword(c *gin.Context, id int64) {
+	var input PasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+
+	hash, err := HashPassword(input.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	updated, err := u.q.UpdateUserPassword(c.Request.Context(), db.UpdateUserPasswordParams{
+		ID:           id,
+		PasswordHash: hash,
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("failed to update password: %w", err))
+		return
+	}
+	if updated == 0 {
+		c.Error(utils.NewHttpError(404, "user not found", pgx.ErrNoRows))
+		return
+	}
+	if err := u.q.DeleteUserSessions(c.Request.Context(), id); err != nil {
+		c.Error(fmt.Errorf("failed to end sessions: %w", err))
+		return
+	}
+
+	c.Status(204)
+}
+
+// keepAnAdmin refuses to demote or delete the last admin, which would
+// leave nobody able to manage users.
+func (u *Controller) keepAnAdmin(c *gin.Context, id int64) error {
+	user, err := u.q.GetUser(c.Request.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewHttpError(404, "user not found", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Role != db.UserRoleAdmin {
+		return nil
+	}
+
+	admins, err := u.q.CountUsersByRole(c.Request.Context(), db.UserRoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to count admins: %w", err)
+	}
+	if admins <= 1 {
+		return utils.NewHttpError(409, "cannot remove the last admin", errors.New("there must be an admin"))
+	}
+	return nil
+}
+
+func pathID(c *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, utils.NewHttpError(400, "invalid id", err)
+	}
+	return id, nil
+}
+
+// ListAPIKeys godoc
+//
+//	@Summary		List API keys
+//	@Description	List every API key, revoked and expired ones included
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Produce		json
+//	@Success		200	{object}	[]APIKey
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Router			/api-keys [get]
+func (u *Controller) ListAPIKeys(c *gin.Context) {
+	keys, err := u.q.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		c.Error(fmt.Errorf("failed to list API keys: %w", err))
+		return
+	}
+	c.JSON(200, utils.MapSlice(NewAPIKey, keys))
+}
+
+// CreateAPIKey godoc
+//
+//	@Summary		Create API key
+//	@Description	Issue an API key with scopes stats:read, export and/or import. Send it in an X-API-Key header. The key is only shown in this response.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			apiKey	body		CreateAPIKeyInput	true	"The API key"
+//	@Success		201		{object}	CreateAPIKeyResponse
+//	@Failure		400		{object}	utils.HttpError
+//	@Failure		401		{object}	utils.HttpError
+//	@Failure		403		{object}	utils.HttpError
+//	@Router			/api-keys [post]
+func (u *Controller) CreateAPIKey(c *gin.Context) {
+	var input CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+
+	user, _ := CurrentUser(c)
+	key, apiKey, err := CreateAPIKey(c.Request.Context(), u.q, input.Name, input.Scopes, &user.ID, input.ExpiresInDays)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(201, CreateAPIKeyResponse{
+		Key:    key,
+		APIKey: NewAPIKey(apiKey),
+	})
+}
+
+// RevokeAPIKey godoc
+//
+//	@Summary		Revoke API key
+//	@Description	Revoke an API key. It is kept in the list with its revocation time.
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			id	path	integer	true	"ID of the API key"
+//	@Success		204
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Router			/api-keys/{id} [delete]
+func (u *Controller) RevokeAPIKey(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	revoked, err := u.q.RevokeAPIKey(c.Request.Context(), id)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to revoke API key: %w", err))
+		return
+	}
+	if revoked == 0 {
+		c.Error(utils.NewHttpError(404, "API key not found or already revoked", pgx.ErrNoRows))
+		return
+	}
+
+	c.Status(204)
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+)
+
+// User is a user as the API shows them, without their password hash.
+type User struct {
+	ID        int64       `json:"id"`
+	Email     string      `json:"email"`
+	Name      string      `json:"name"`
+	Role      db.UserRole `json:"role"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+func NewUser(u db.User) User {
+	return User{
+		ID:        u.ID,
+		Email:     u.Email,
+		Name:      u.Name,
+		Role:      u.Role,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type LoginResponse struct {
+	// Token is sent back in an "Authorization: Bearer <token>" header
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      User      `json:"user"`
+}
+
+type PasswordInput struct {
+	// bcrypt only uses the first 72 bytes
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type CreateUserInput struct {
+	Email string      `json:"email" binding:"required,email"`
+	Name  string      `json:"name" binding:"required"`
+	Role  db.UserRole `json:"role" binding:"required"`
+	PasswordInput
+}
+
+type UpdateUserInput struct {
+	Name string      `json:"name" binding:"required"`
+	Role db.UserRole `json:"role" binding:"required"`
+}
+
+// APIKey is an API key as the API shows it, without its hash.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *int64     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+func NewAPIKey(k db.ApiKey) APIKey {
+	return APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedBy:  k.CreatedBy,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  utils.FromPgTimestamp(k.ExpiresAt),
+		LastUsedAt: utils.FromPgTimestamp(k.LastUsedAt),
+		RevokedAt:  utils.FromPgTimestamp(k.RevokedAt),
+	}
+}
+
+type CreateAPIKeyInput struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=stats:read export import"`
+	// ExpiresInDays leaves the key valid forever when missing
+	ExpiresInDays *int32 `json:"expiresInDays" binding:"omitempty,min=1"`
+}
+
+// CreateAPIKeyResponse holds the key itself, which cannot be shown again.
+type CreateAPIKeyResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"apiKey"`
+}
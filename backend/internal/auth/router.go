@@ -0,0 +1,27 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+// Register adds the login routes to r, the routes of the current user to
+// signedIn, and the management of users and API keys to admin.
+func Register(r, signedIn, admin gin.IRouter, ctl *Controller) {
+	g := r.Group("/auth")
+	g.POST("/login", ctl.Login)
+
+	me := signedIn.Group("/auth")
+	me.POST("/logout", ctl.Logout)
+	me.GET("/me", ctl.Me)
+	me.PUT("/me/password", ctl.ChangePassword)
+
+	users := admin.Group("/users")
+	users.GET("", ctl.ListUsers)
+	users.POST("", ctl.CreateUser)
+	users.PUT("/:id", ctl.UpdateUser)
+	users.PUT("/:id/password", ctl.ResetPassword)
+	users.DELETE("/:id", ctl.DeleteUser)
+
+	keys := admin.Group("/api-keys")
+	keys.GET("", ctl.ListAPIKeys)
+	keys.POST("", ctl.CreateAPIKey)
+	keys.DELETE("/:id", ctl.RevokeAPIKey)
+}
@@ -0,0 +1,155 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: batch.go
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrBatchAlreadyClosed = errors.New("batch already closed")
+)
+
+const upsertObservations = `-- name: UpsertObservations :batchone
+WITH previous AS (
+  SELECT o.id, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.import_batch_id
+  FROM observations o
+  WHERE o.site_id = $1
+    AND o.species_id = $2
+    AND o."timestamp" = $3
+    AND o.method = $4
+    AND COALESCE(o.file, '') = COALESCE($5::text, '')
+), upserted AS (
+  INSERT INTO observations (
+    site_id,
+    species_id,
+    "timestamp",
+    method,
+    appearance_start,
+    appearance_end,
+    temperature,
+    narrative,
+    confidence,
+    file,
+    import_batch_id
+  )
+  VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $5,
+    $11
+  )
+  ON CONFLICT (site_id, species_id, "timestamp", method, COALESCE(file, ''))
+  DO UPDATE SET
+    appearance_start = EXCLUDED.appearance_start,
+    appearance_end = EXCLUDED.appearance_end,
+    temperature = EXCLUDED.temperature,
+    narrative = EXCLUDED.narrative,
+    confidence = EXCLUDED.confidence
+  WHERE $12::boolean
+    AND (observations.appearance_start, observations.appearance_end, observations.temperature, observations.narrative, observations.confidence)
+      IS DISTINCT FROM (EXCLUDED.appearance_start, EXCLUDED.appearance_end, EXCLUDED.temperature, EXCLUDED.narrative, EXCLUDED.confidence)
+  RETURNING id, (xmax = 0)::boolean AS inserted
+), revision AS (
+  -- Only the first update of the batch holds the values from before it, and
+  -- observations the batch inserted are deleted when it is undone anyway
+  INSERT INTO observation_revisions (observation_id, import_batch_id, appearance_start, appearance_end, temperature, narrative, confidence)
+  SELECT p.id, $11::bigint, p.appearance_start, p.appearance_end, p.temperature, p.narrative, p.confidence
+  FROM previous p
+  JOIN upserted u ON u.id = p.id AND NOT u.inserted
+  WHERE $11::bigint IS NOT NULL
+    AND p.import_batch_id IS DISTINCT FROM $11::bigint
+  ON CONFLICT (import_batch_id, observation_id) DO NOTHING
+)
+SELECT id, inserted FROM upserted
+`
+
+type UpsertObservationsBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type UpsertObservationsParams struct {
+	SiteID          int64             `json:"siteId"`
+	SpeciesID       int64             `json:"speciesId"`
+	Timestamp       time.Time         `json:"timestamp"`
+	Method          ObservationMethod `json:"method"`
+	File            *string           `json:"file"`
+	AppearanceStart *int32            `json:"appearanceStart"`
+	AppearanceEnd   *int32            `json:"appearanceEnd"`
+	Temperature     *int32            `json:"temperature"`
+	Narrative       *string           `json:"narrative"`
+	Confidence      *float32          `json:"confidence"`
+	ImportBatchID   *int64            `json:"importBatchId"`
+	UpdateExisting  bool              `json:"updateExisting"`
+}
+
+type UpsertObservationsRow struct {
+	ID       int64 `json:"id"`
+	Inserted bool  `json:"inserted"`
+}
+
+// UpsertObservations inserts observations by their natural key (site, species,
+// timestamp, method, file). An existing observation is updated only when
+// update_existing is set and its values differ, otherwise no row is returned.
+// The values an update replaces are kept as a revision of the import batch.
+func (q *Queries) UpsertObservations(ctx context.Context, arg []UpsertObservationsParams) *UpsertObservationsBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.SiteID,
+			a.SpeciesID,
+			a.Timestamp,
+			a.Method,
+			a.File,
+			a.AppearanceStart,
+			a.AppearanceEnd,
+			a.Temperature,
+			a.Narrative,
+			a.Confidence,
+			a.ImportBatchID,
+			a.UpdateExisting,
+		}
+		batch.Queue(upsertObservations, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &UpsertObservationsBatchResults{br, len(arg), false}
+}
+
+func (b *UpsertObservationsBatchResults) QueryRow(f func(int, UpsertObservationsRow, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var i UpsertObservationsRow
+		if b.closed {
+			if f != nil {
+				f(t, i, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(&i.ID, &i.Inserted)
+		if f != nil {
+			f(t, i, err)
+		}
+	}
+}
+
+func (b *UpsertObservationsBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
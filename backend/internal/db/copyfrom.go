@@ -39,6 +39,7 @@ func (r iteratorForCreateObservations) Values() ([]interface{}, error) {
 		r.rows[0].Narrative,
 		r.rows[0].Confidence,
 		r.rows[0].File,
+		r.rows[0].ImportBatchID,
 	}, nil
 }
 
@@ -47,5 +48,5 @@ func (r iteratorForCreateObservations) Err() error {
 }
 
 func (q *Queries) CreateObservations(ctx context.Context, arg []CreateObservationsParams) (int64, error) {
-	return q.db.CopyFrom(ctx, []string{"observations"}, []string{"site_id", "species_id", "timestamp", "method", "appearance_start", "appearance_end", "temperature", "narrative", "confidence", "file"}, &iteratorForCreateObservations{rows: arg})
+	return q.db.CopyFrom(ctx, []string{"observations"}, []string{"site_id", "species_id", "timestamp", "method", "appearance_start", "appearance_end", "temperature", "narrative", "confidence", "file", "import_batch_id"}, &iteratorForCreateObservations{rows: arg})
 }
@@ -0,0 +1,291 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: deployment.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createDeployment = `-- name: CreateDeployment :one
+INSERT INTO deployments (site_id, method, device_id, start_time, end_time, notes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, site_id, method, device_id, start_time, end_time, notes, import_batch_id
+`
+
+type CreateDeploymentParams struct {
+	SiteID    int64             `json:"siteId"`
+	Method    ObservationMethod `json:"method"`
+	DeviceID  *string           `json:"deviceId"`
+	StartTime time.Time         `json:"startTime"`
+	EndTime   time.Time         `json:"endTime"`
+	Notes     *string           `json:"notes"`
+}
+
+func (q *Queries) CreateDeployment(ctx context.Context, arg CreateDeploymentParams) (Deployment, error) {
+	row := q.db.QueryRow(ctx, createDeployment,
+		arg.SiteID,
+		arg.Method,
+		arg.DeviceID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Notes,
+	)
+	var i Deployment
+	err := row.Scan(
+		&i.ID,
+		&i.SiteID,
+		&i.Method,
+		&i.DeviceID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Notes,
+		&i.ImportBatchID,
+	)
+	return i, err
+}
+
+const deleteDeployment = `-- name: DeleteDeployment :execrows
+DELETE FROM deployments
+WHERE id = $1
+`
+
+func (q *Queries) DeleteDeployment(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDeployment, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deploymentEffortBySite = `-- name: DeploymentEffortBySite :many
+SELECT s.code AS site_code, d.method,
+    SUM(EXTRACT(epoch FROM
+        LEAST(d.end_time, COALESCE($1::timestamp, d.end_time))
+        - GREATEST(d.start_time, COALESCE($2::timestamp, d.start_time))
+    ))::float8 AS seconds
+FROM deployments d
+JOIN sites s ON d.site_id = s.id
+WHERE ($2::timestamp IS NULL OR d.end_time > $2::timestamp)
+  AND ($1::timestamp IS NULL OR d.start_time < $1::timestamp)
+  AND ($3::int IS NULL OR s.block = $3::int)
+  AND ($4::tenure_type IS NULL OR s.tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR s.forest = $5::forest_type)
+  AND ($6::text IS NULL OR s.code = $6)
+GROUP BY s.code, d.method
+ORDER BY s.code, d.method
+`
+
+type DeploymentEffortBySiteParams struct {
+	To       pgtype.Timestamp `json:"to"`
+	From     pgtype.Timestamp `json:"from"`
+	Block    *int32           `json:"block"`
+	Tenure   NullTenureType   `json:"tenure"`
+	Forest   NullForestType   `json:"forest"`
+	SiteCode *string          `json:"siteCode"`
+}
+
+type DeploymentEffortBySiteRow struct {
+	SiteCode string            `json:"siteCode"`
+	Method   ObservationMethod `json:"method"`
+	Seconds  float64           `json:"seconds"`
+}
+
+// DeploymentEffortBySite sums the time each site was surveyed by each
+// method within the period, in seconds.
+func (q *Queries) DeploymentEffortBySite(ctx context.Context, arg DeploymentEffortBySiteParams) ([]DeploymentEffortBySiteRow, error) {
+	rows, err := q.db.Query(ctx, deploymentEffortBySite,
+		arg.To,
+		arg.From,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DeploymentEffortBySiteRow{}
+	for rows.Next() {
+		var i DeploymentEffortBySiteRow
+		if err := rows.Scan(&i.SiteCode, &i.Method, &i.Seconds); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeployment = `-- name: GetDeployment :one
+SELECT id, site_id, method, device_id, start_time, end_time, notes, import_batch_id FROM deployments
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetDeployment(ctx context.Context, id int64) (Deployment, error) {
+	row := q.db.QueryRow(ctx, getDeployment, id)
+	var i Deployment
+	err := row.Scan(
+		&i.ID,
+		&i.SiteID,
+		&i.Method,
+		&i.DeviceID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Notes,
+		&i.ImportBatchID,
+	)
+	return i, err
+}
+
+const listDeployments = `-- name: ListDeployments :many
+SELECT d.id, d.site_id, d.method, d.device_id, d.start_time, d.end_time, d.notes, d.import_batch_id FROM deployments d
+JOIN sites s ON d.site_id = s.id
+WHERE ($1::text IS NULL OR s.code = $1)
+  AND ($2::observation_method IS NULL OR d.method = $2)
+  AND ($3::timestamp IS NULL OR d.end_time > $3::timestamp)
+  AND ($4::timestamp IS NULL OR d.start_time < $4::timestamp)
+ORDER BY s.code, d.start_time, d.id
+`
+
+type ListDeploymentsParams struct {
+	SiteCode *string               `json:"siteCode"`
+	Method   NullObservationMethod `json:"method"`
+	From     pgtype.Timestamp      `json:"from"`
+	To       pgtype.Timestamp      `json:"to"`
+}
+
+// ListDeployments lists the deployments of a site and method, or of all of
+// them, that overlap the period.
+func (q *Queries) ListDeployments(ctx context.Context, arg ListDeploymentsParams) ([]Deployment, error) {
+	rows, err := q.db.Query(ctx, listDeployments,
+		arg.SiteCode,
+		arg.Method,
+		arg.From,
+		arg.To,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Deployment{}
+	for rows.Next() {
+		var i Deployment
+		if err := rows.Scan(
+			&i.ID,
+			&i.SiteID,
+			&i.Method,
+			&i.DeviceID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Notes,
+			&i.ImportBatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateDeployment = `-- name: UpdateDeployment :one
+UPDATE deployments
+SET site_id = $2, method = $3, device_id = $4, start_time = $5, end_time = $6, notes = $7
+WHERE id = $1
+RETURNING id, site_id, method, device_id, start_time, end_time, notes, import_batch_id
+`
+
+type UpdateDeploymentParams struct {
+	ID        int64             `json:"id"`
+	SiteID    int64             `json:"siteId"`
+	Method    ObservationMethod `json:"method"`
+	DeviceID  *string           `json:"deviceId"`
+	StartTime time.Time         `json:"startTime"`
+	EndTime   time.Time         `json:"endTime"`
+	Notes     *string           `json:"notes"`
+}
+
+func (q *Queries) UpdateDeployment(ctx context.Context, arg UpdateDeploymentParams) (Deployment, error) {
+	row := q.db.QueryRow(ctx, updateDeployment,
+		arg.ID,
+		arg.SiteID,
+		arg.Method,
+		arg.DeviceID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Notes,
+	)
+	var i Deployment
+	err := row.Scan(
+		&i.ID,
+		&i.SiteID,
+		&i.Method,
+		&i.DeviceID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Notes,
+		&i.ImportBatchID,
+	)
+	return i, err
+}
+
+const upsertDeployment = `-- name: UpsertDeployment :execrows
+INSERT INTO deployments (site_id, method, device_id, start_time, end_time, notes, import_batch_id)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+ON CONFLICT (site_id, method, start_time, COALESCE(device_id, ''))
+DO UPDATE SET
+  end_time = EXCLUDED.end_time,
+  notes = EXCLUDED.notes
+WHERE $8::boolean
+  AND (deployments.end_time, deployments.notes) IS DISTINCT FROM (EXCLUDED.end_time, EXCLUDED.notes)
+`
+
+type UpsertDeploymentParams struct {
+	SiteID         int64             `json:"siteId"`
+	Method         ObservationMethod `json:"method"`
+	DeviceID       *string           `json:"deviceId"`
+	StartTime      time.Time         `json:"startTime"`
+	EndTime        time.Time         `json:"endTime"`
+	Notes          *string           `json:"notes"`
+	ImportBatchID  *int64            `json:"importBatchId"`
+	UpdateExisting bool              `json:"updateExisting"`
+}
+
+// UpsertDeployment imports a deployment, identified by site, method, device
+// and start. It affects no row when the deployment exists and is unchanged,
+// or update_existing is false.
+func (q *Queries) UpsertDeployment(ctx context.Context, arg UpsertDeploymentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, upsertDeployment,
+		arg.SiteID,
+		arg.Method,
+		arg.DeviceID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Notes,
+		arg.ImportBatchID,
+		arg.UpdateExisting,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,218 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: export.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const listObservationDetails = `-- name: ListObservationDetails :many
+SELECT id, site_id, species_id, timestamp, method, appearance_start, appearance_end, temperature, narrative, confidence, file, native, taxa, scientific_name, common_name, indicator, reportable, block, site_code, site_name, tenure, forest
+FROM observations_with_details
+WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
+  AND ($3::int IS NULL OR block = $3::int)
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
+ORDER BY "timestamp", id
+`
+
+type ListObservationDetailsParams struct {
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
+}
+
+func (q *Queries) ListObservationDetails(ctx context.Context, arg ListObservationDetailsParams) ([]ObservationsWithDetail, error) {
+	rows, err := q.db.Query(ctx, listObservationDetails,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ObservationsWithDetail{}
+	for rows.Next() {
+		var i ObservationsWithDetail
+		if err := rows.Scan(
+			&i.ID,
+			&i.SiteID,
+			&i.SpeciesID,
+			&i.Timestamp,
+			&i.Method,
+			&i.AppearanceStart,
+			&i.AppearanceEnd,
+			&i.Temperature,
+			&i.Narrative,
+			&i.Confidence,
+			&i.File,
+			&i.Native,
+			&i.Taxa,
+			&i.ScientificName,
+			&i.CommonName,
+			&i.Indicator,
+			&i.Reportable,
+			&i.Block,
+			&i.SiteCode,
+			&i.SiteName,
+			&i.Tenure,
+			&i.Forest,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOccupancyOccasions = `-- name: ListOccupancyOccasions :many
+SELECT site_id,
+    FLOOR(EXTRACT(epoch FROM "timestamp" - $1::timestamp) / ($2::int * 86400))::int AS occasion,
+    BOOL_OR(species_id = $3::bigint
+        AND ($4::taxa IS NULL OR taxa = $4::taxa)
+        AND ($5::int IS NULL OR starts_event(id, $5::int))
+    ) AS detected
+FROM observations_with_details
+WHERE "timestamp" >= $1::timestamp
+  AND "timestamp" < $6::timestamp
+  AND ($7::observation_method IS NULL OR method = $7)
+  AND ($8::int IS NULL OR block = $8::int)
+  AND ($9::tenure_type IS NULL OR tenure = $9::tenure_type)
+  AND ($10::forest_type IS NULL OR forest = $10::forest_type)
+  AND ($11::text IS NULL OR site_code = $11)
+GROUP BY site_id, occasion
+ORDER BY site_id, occasion
+`
+
+type ListOccupancyOccasionsParams struct {
+	StartTime    time.Time             `json:"startTime"`
+	OccasionDays int32                 `json:"occasionDays"`
+	SpeciesID    int64                 `json:"speciesId"`
+	Taxa         NullTaxa              `json:"taxa"`
+	Independence *int32                `json:"independence"`
+	EndTime      time.Time             `json:"endTime"`
+	Method       NullObservationMethod `json:"method"`
+	Block        *int32                `json:"block"`
+	Tenure       NullTenureType        `json:"tenure"`
+	Forest       NullForestType        `json:"forest"`
+	SiteCode     *string               `json:"siteCode"`
+}
+
+type ListOccupancyOccasionsRow struct {
+	SiteID   int64 `json:"siteId"`
+	Occasion int32 `json:"occasion"`
+	Detected bool  `json:"detected"`
+}
+
+// ListOccupancyOccasions lists the occasions of the period each site has
+// observations in, of any species, which show it was surveyed, and whether
+// the species was detected in them. The taxa and independence filters only
+// narrow down the detections, not the survey effort.
+func (q *Queries) ListOccupancyOccasions(ctx context.Context, arg ListOccupancyOccasionsParams) ([]ListOccupancyOccasionsRow, error) {
+	rows, err := q.db.Query(ctx, listOccupancyOccasions,
+		arg.StartTime,
+		arg.OccasionDays,
+		arg.SpeciesID,
+		arg.Taxa,
+		arg.Independence,
+		arg.EndTime,
+		arg.Method,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOccupancyOccasionsRow{}
+	for rows.Next() {
+		var i ListOccupancyOccasionsRow
+		if err := rows.Scan(&i.SiteID, &i.Occasion, &i.Detected); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOccupancySites = `-- name: ListOccupancySites :many
+SELECT id, code, block, name, location, tenure, forest, import_batch_id FROM sites
+WHERE ($1::int IS NULL OR block = $1::int)
+  AND ($2::tenure_type IS NULL OR tenure = $2::tenure_type)
+  AND ($3::forest_type IS NULL OR forest = $3::forest_type)
+  AND ($4::text IS NULL OR code = $4)
+ORDER BY code
+`
+
+type ListOccupancySitesParams struct {
+	Block    *int32         `json:"block"`
+	Tenure   NullTenureType `json:"tenure"`
+	Forest   NullForestType `json:"forest"`
+	SiteCode *string        `json:"siteCode"`
+}
+
+func (q *Queries) ListOccupancySites(ctx context.Context, arg ListOccupancySitesParams) ([]Site, error) {
+	rows, err := q.db.Query(ctx, listOccupancySites,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Site{}
+	for rows.Next() {
+		var i Site
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Block,
+			&i.Name,
+			&i.Location,
+			&i.Tenure,
+			&i.Forest,
+			&i.ImportBatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
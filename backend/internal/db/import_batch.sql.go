@@ -0,0 +1,268 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: import_batch.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createImportBatch = `-- name: CreateImportBatch :one
+INSERT INTO import_batches (file_name, checksum)
+VALUES ($1, $2)
+RETURNING id, file_name, checksum, row_count, started_at, finished_at, status
+`
+
+type CreateImportBatchParams struct {
+	FileName string `json:"fileName"`
+	Checksum string `json:"checksum"`
+}
+
+func (q *Queries) CreateImportBatch(ctx context.Context, arg CreateImportBatchParams) (ImportBatch, error) {
+	row := q.db.QueryRow(ctx, createImportBatch, arg.FileName, arg.Checksum)
+	var i ImportBatch
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.Checksum,
+		&i.RowCount,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const deleteDeploymentsByImportBatch = `-- name: DeleteDeploymentsByImportBatch :execrows
+DELETE FROM deployments
+WHERE import_batch_id = $1::bigint
+`
+
+func (q *Queries) DeleteDeploymentsByImportBatch(ctx context.Context, importBatchID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDeploymentsByImportBatch, importBatchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteObservationRevisionsByImportBatch = `-- name: DeleteObservationRevisionsByImportBatch :exec
+DELETE FROM observation_revisions
+WHERE import_batch_id = $1::bigint
+`
+
+func (q *Queries) DeleteObservationRevisionsByImportBatch(ctx context.Context, importBatchID int64) error {
+	_, err := q.db.Exec(ctx, deleteObservationRevisionsByImportBatch, importBatchID)
+	return err
+}
+
+const deleteObservationsByImportBatch = `-- name: DeleteObservationsByImportBatch :execrows
+DELETE FROM observations
+WHERE import_batch_id = $1::bigint
+`
+
+func (q *Queries) DeleteObservationsByImportBatch(ctx context.Context, importBatchID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteObservationsByImportBatch, importBatchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSitesByImportBatch = `-- name: DeleteSitesByImportBatch :execrows
+DELETE FROM sites si
+WHERE si.import_batch_id = $1::bigint
+  AND NOT EXISTS (SELECT 1 FROM observations o WHERE o.site_id = si.id)
+  AND NOT EXISTS (SELECT 1 FROM deployments d WHERE d.site_id = si.id)
+`
+
+// Sites still used by other imports are kept.
+func (q *Queries) DeleteSitesByImportBatch(ctx context.Context, importBatchID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSitesByImportBatch, importBatchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSpeciesByImportBatch = `-- name: DeleteSpeciesByImportBatch :execrows
+DELETE FROM species sp
+WHERE sp.import_batch_id = $1::bigint
+  AND NOT EXISTS (SELECT 1 FROM observations o WHERE o.species_id = sp.id)
+`
+
+// Species still observed by other imports are kept.
+func (q *Queries) DeleteSpeciesByImportBatch(ctx context.Context, importBatchID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSpeciesByImportBatch, importBatchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const finishImportBatch = `-- name: FinishImportBatch :one
+UPDATE import_batches
+SET status = $2, row_count = $3, finished_at = now()
+WHERE id = $1
+RETURNING id, file_name, checksum, row_count, started_at, finished_at, status
+`
+
+type FinishImportBatchParams struct {
+	ID       int64        `json:"id"`
+	Status   ImportStatus `json:"status"`
+	RowCount int32        `json:"rowCount"`
+}
+
+func (q *Queries) FinishImportBatch(ctx context.Context, arg FinishImportBatchParams) (ImportBatch, error) {
+	row := q.db.QueryRow(ctx, finishImportBatch, arg.ID, arg.Status, arg.RowCount)
+	var i ImportBatch
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.Checksum,
+		&i.RowCount,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getImportBatch = `-- name: GetImportBatch :one
+SELECT id, file_name, checksum, row_count, started_at, finished_at, status FROM import_batches
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetImportBatch(ctx context.Context, id int64) (ImportBatch, error) {
+	row := q.db.QueryRow(ctx, getImportBatch, id)
+	var i ImportBatch
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.Checksum,
+		&i.RowCount,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const listImportBatches = `-- name: ListImportBatches :many
+SELECT b.id, b.file_name, b.checksum, b.row_count, b.started_at, b.finished_at, b.status, (SELECT COUNT(*) FROM observations o WHERE o.import_batch_id = b.id) AS observation_count
+FROM import_batches b
+ORDER BY b.started_at DESC
+`
+
+type ListImportBatchesRow struct {
+	ID               int64            `json:"id"`
+	FileName         string           `json:"fileName"`
+	Checksum         string           `json:"checksum"`
+	RowCount         int32            `json:"rowCount"`
+	StartedAt        time.Time        `json:"startedAt"`
+	FinishedAt       pgtype.Timestamp `json:"finishedAt"`
+	Status           ImportStatus     `json:"status"`
+	ObservationCount int64            `json:"observationCount"`
+}
+
+func (q *Queries) ListImportBatches(ctx context.Context) ([]ListImportBatchesRow, error) {
+	rows, err := q.db.Query(ctx, listImportBatches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListImportBatchesRow{}
+	for rows.Next() {
+		var i ListImportBatchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileName,
+			&i.Checksum,
+			&i.RowCount,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Status,
+			&i.ObservationCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const passOnObservationRevisionsByImportBatch = `-- name: PassOnObservationRevisionsByImportBatch :exec
+UPDATE observation_revisions later
+SET
+  appearance_start = r.appearance_start,
+  appearance_end = r.appearance_end,
+  temperature = r.temperature,
+  narrative = r.narrative,
+  confidence = r.confidence
+FROM observation_revisions r
+WHERE r.import_batch_id = $1::bigint
+  AND later.observation_id = r.observation_id
+  AND later.id = (
+    SELECT MIN(n.id) FROM observation_revisions n
+    WHERE n.observation_id = r.observation_id AND n.id > r.id
+  )
+`
+
+// The next revision of an observation the batch updated, made by a later
+// import, takes the values from before the batch, so that undoing the later
+// import too restores them.
+func (q *Queries) PassOnObservationRevisionsByImportBatch(ctx context.Context, importBatchID int64) error {
+	_, err := q.db.Exec(ctx, passOnObservationRevisionsByImportBatch, importBatchID)
+	return err
+}
+
+const restoreObservationsByImportBatch = `-- name: RestoreObservationsByImportBatch :execrows
+UPDATE observations o
+SET
+  appearance_start = r.appearance_start,
+  appearance_end = r.appearance_end,
+  temperature = r.temperature,
+  narrative = r.narrative,
+  confidence = r.confidence
+FROM observation_revisions r
+WHERE r.import_batch_id = $1::bigint
+  AND o.id = r.observation_id
+  AND NOT EXISTS (
+    SELECT 1 FROM observation_revisions later
+    WHERE later.observation_id = r.observation_id AND later.id > r.id
+  )
+`
+
+// Observations the batch updated get back the values they had before it,
+// unless a later import updated them again.
+func (q *Queries) RestoreObservationsByImportBatch(ctx context.Context, importBatchID int64) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreObservationsByImportBatch, importBatchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setImportBatchStatus = `-- name: SetImportBatchStatus :exec
+UPDATE import_batches
+SET status = $2
+WHERE id = $1
+`
+
+type SetImportBatchStatusParams struct {
+	ID     int64        `json:"id"`
+	Status ImportStatus `json:"status"`
+}
+
+func (q *Queries) SetImportBatchStatus(ctx context.Context, arg SetImportBatchStatusParams) error {
+	_, err := q.db.Exec(ctx, setImportBatchStatus, arg.ID, arg.Status)
+	return err
+}
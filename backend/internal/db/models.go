@@ -8,6 +8,8 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type ForestType string
@@ -68,6 +70,70 @@ func AllForestTypeValues() []ForestType {
 	}
 }
 
+type ImportStatus string
+
+const (
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+	ImportStatusReverted  ImportStatus = "reverted"
+)
+
+func (e *ImportStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ImportStatus(s)
+	case string:
+		*e = ImportStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ImportStatus: %T", src)
+	}
+	return nil
+}
+
+type NullImportStatus struct {
+	ImportStatus ImportStatus `json:"importStatus"`
+	Valid        bool         `json:"valid"` // Valid is true if ImportStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullImportStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ImportStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ImportStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullImportStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ImportStatus), nil
+}
+
+func (e ImportStatus) Valid() bool {
+	switch e {
+	case ImportStatusRunning,
+		ImportStatusCompleted,
+		ImportStatusFailed,
+		ImportStatusReverted:
+		return true
+	}
+	return false
+}
+
+func AllImportStatusValues() []ImportStatus {
+	return []ImportStatus{
+		ImportStatusRunning,
+		ImportStatusCompleted,
+		ImportStatusFailed,
+		ImportStatusReverted,
+	}
+}
+
 type ObservationMethod string
 
 const (
@@ -248,6 +314,101 @@ func AllTenureTypeValues() []TenureType {
 	}
 }
 
+type UserRole string
+
+const (
+	UserRoleViewer    UserRole = "viewer"
+	UserRoleEcologist UserRole = "ecologist"
+	UserRoleAdmin     UserRole = "admin"
+)
+
+func (e *UserRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = UserRole(s)
+	case string:
+		*e = UserRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for UserRole: %T", src)
+	}
+	return nil
+}
+
+type NullUserRole struct {
+	UserRole UserRole `json:"userRole"`
+	Valid    bool     `json:"valid"` // Valid is true if UserRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullUserRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.UserRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.UserRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullUserRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.UserRole), nil
+}
+
+func (e UserRole) Valid() bool {
+	switch e {
+	case UserRoleViewer,
+		UserRoleEcologist,
+		UserRoleAdmin:
+		return true
+	}
+	return false
+}
+
+func AllUserRoleValues() []UserRole {
+	return []UserRole{
+		UserRoleViewer,
+		UserRoleEcologist,
+		UserRoleAdmin,
+	}
+}
+
+type ApiKey struct {
+	ID         int64            `json:"id"`
+	Name       string           `json:"name"`
+	Prefix     string           `json:"prefix"`
+	KeyHash    []byte           `json:"keyHash"`
+	Scopes     []string         `json:"scopes"`
+	CreatedBy  *int64           `json:"createdBy"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	ExpiresAt  pgtype.Timestamp `json:"expiresAt"`
+	LastUsedAt pgtype.Timestamp `json:"lastUsedAt"`
+	RevokedAt  pgtype.Timestamp `json:"revokedAt"`
+}
+
+type Deployment struct {
+	ID            int64             `json:"id"`
+	SiteID        int64             `json:"siteId"`
+	Method        ObservationMethod `json:"method"`
+	DeviceID      *string           `json:"deviceId"`
+	StartTime     time.Time         `json:"startTime"`
+	EndTime       time.Time         `json:"endTime"`
+	Notes         *string           `json:"notes"`
+	ImportBatchID *int64            `json:"importBatchId"`
+}
+
+type ImportBatch struct {
+	ID         int64            `json:"id"`
+	FileName   string           `json:"fileName"`
+	Checksum   string           `json:"checksum"`
+	RowCount   int32            `json:"rowCount"`
+	StartedAt  time.Time        `json:"startedAt"`
+	FinishedAt pgtype.Timestamp `json:"finishedAt"`
+	Status     ImportStatus     `json:"status"`
+}
+
 type Observation struct {
 	ID              int64             `json:"id"`
 	SiteID          int64             `json:"siteId"`
@@ -260,6 +421,33 @@ type Observation struct {
 	Narrative       *string           `json:"narrative"`
 	Confidence      *float32          `json:"confidence"`
 	File            *string           `json:"file"`
+	ImportBatchID   *int64            `json:"importBatchId"`
+}
+
+type ObservationDuplicate struct {
+	ID              int64             `json:"id"`
+	SiteID          int64             `json:"siteId"`
+	SpeciesID       int64             `json:"speciesId"`
+	Timestamp       time.Time         `json:"timestamp"`
+	Method          ObservationMethod `json:"method"`
+	AppearanceStart *int32            `json:"appearanceStart"`
+	AppearanceEnd   *int32            `json:"appearanceEnd"`
+	Temperature     *int32            `json:"temperature"`
+	Narrative       *string           `json:"narrative"`
+	Confidence      *float32          `json:"confidence"`
+	File            *string           `json:"file"`
+	ImportBatchID   *int64            `json:"importBatchId"`
+}
+
+type ObservationRevision struct {
+	ID              int64    `json:"id"`
+	ObservationID   int64    `json:"observationId"`
+	ImportBatchID   int64    `json:"importBatchId"`
+	AppearanceStart *int32   `json:"appearanceStart"`
+	AppearanceEnd   *int32   `json:"appearanceEnd"`
+	Temperature     *int32   `json:"temperature"`
+	Narrative       *string  `json:"narrative"`
+	Confidence      *float32 `json:"confidence"`
 }
 
 type ObservationsWithDetail struct {
@@ -287,14 +475,22 @@ type ObservationsWithDetail struct {
 	Forest          ForestType        `json:"forest"`
 }
 
+type Session struct {
+	TokenHash []byte    `json:"tokenHash"`
+	UserID    int64     `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 type Site struct {
-	ID       int64      `json:"id"`
-	Code     string     `json:"code"`
-	Block    int32      `json:"block"`
-	Name     *string    `json:"name"`
-	Location *string    `json:"location"`
-	Tenure   TenureType `json:"tenure"`
-	Forest   ForestType `json:"forest"`
+	ID            int64      `json:"id"`
+	Code          string     `json:"code"`
+	Block         int32      `json:"block"`
+	Name          *string    `json:"name"`
+	Location      *string    `json:"location"`
+	Tenure        TenureType `json:"tenure"`
+	Forest        ForestType `json:"forest"`
+	ImportBatchID *int64     `json:"importBatchId"`
 }
 
 type Species struct {
@@ -305,4 +501,14 @@ type Species struct {
 	Taxa           Taxa   `json:"taxa"`
 	Indicator      bool   `json:"indicator"`
 	Reportable     bool   `json:"reportable"`
+	ImportBatchID  *int64 `json:"importBatchId"`
+}
+
+type User struct {
+	ID           int64     `json:"id"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"passwordHash"`
+	Role         UserRole  `json:"role"`
+	CreatedAt    time.Time `json:"createdAt"`
 }
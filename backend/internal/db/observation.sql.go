@@ -8,14 +8,62 @@ package db
 import (
 	"context"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const countObservations = `-- name: CountObservations :one
-SELECT COUNT(*) FROM observations
+SELECT COUNT(*)
+FROM observations o
+JOIN sites si ON o.site_id = si.id
+JOIN species sp ON o.species_id = sp.id
+WHERE ($1::timestamp IS NULL OR o."timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR o."timestamp" <= $2::timestamp)
+  AND ($3::int IS NULL OR si.block = $3::int)
+  AND ($4::tenure_type IS NULL OR si.tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR si.forest = $5::forest_type)
+  AND ($6::text IS NULL OR si.code = $6)
+  AND ($7::taxa IS NULL OR sp.taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(sp.common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(o.id, $9::int))
+  AND ($10::observation_method IS NULL OR o.method = $10::observation_method)
+  AND ($11::real IS NULL OR o.confidence >= $11::real)
+  AND ($12::bool IS NULL OR sp.native = $12::bool)
+  AND ($13::bool IS NULL OR sp.indicator = $13::bool)
 `
 
-func (q *Queries) CountObservations(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countObservations)
+type CountObservationsParams struct {
+	From          pgtype.Timestamp      `json:"from"`
+	To            pgtype.Timestamp      `json:"to"`
+	Block         *int32                `json:"block"`
+	Tenure        NullTenureType        `json:"tenure"`
+	Forest        NullForestType        `json:"forest"`
+	SiteCode      *string               `json:"siteCode"`
+	Taxa          NullTaxa              `json:"taxa"`
+	CommonName    *string               `json:"commonName"`
+	Independence  *int32                `json:"independence"`
+	Method        NullObservationMethod `json:"method"`
+	MinConfidence *float32              `json:"minConfidence"`
+	Native        *bool                 `json:"native"`
+	Indicator     *bool                 `json:"indicator"`
+}
+
+func (q *Queries) CountObservations(ctx context.Context, arg CountObservationsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countObservations,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+		arg.Method,
+		arg.MinConfidence,
+		arg.Native,
+		arg.Indicator,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -32,10 +80,11 @@ INSERT INTO observations (
   temperature,
   narrative,
   confidence,
-  file
+  file,
+  import_batch_id
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file, import_batch_id
 `
 
 type CreateObservationParams struct {
@@ -49,6 +98,7 @@ type CreateObservationParams struct {
 	Narrative       *string           `json:"narrative"`
 	Confidence      *float32          `json:"confidence"`
 	File            *string           `json:"file"`
+	ImportBatchID   *int64            `json:"importBatchId"`
 }
 
 func (q *Queries) CreateObservation(ctx context.Context, arg CreateObservationParams) (Observation, error) {
@@ -63,6 +113,7 @@ func (q *Queries) CreateObservation(ctx context.Context, arg CreateObservationPa
 		arg.Narrative,
 		arg.Confidence,
 		arg.File,
+		arg.ImportBatchID,
 	)
 	var i Observation
 	err := row.Scan(
@@ -77,6 +128,7 @@ func (q *Queries) CreateObservation(ctx context.Context, arg CreateObservationPa
 		&i.Narrative,
 		&i.Confidence,
 		&i.File,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
@@ -92,20 +144,24 @@ type CreateObservationsParams struct {
 	Narrative       *string           `json:"narrative"`
 	Confidence      *float32          `json:"confidence"`
 	File            *string           `json:"file"`
+	ImportBatchID   *int64            `json:"importBatchId"`
 }
 
-const deleteObservation = `-- name: DeleteObservation :exec
+const deleteObservation = `-- name: DeleteObservation :execrows
 DELETE FROM observations
 WHERE id = $1
 `
 
-func (q *Queries) DeleteObservation(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteObservation, id)
-	return err
+func (q *Queries) DeleteObservation(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteObservation, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getObservation = `-- name: GetObservation :one
-SELECT id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file
+SELECT id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file, import_batch_id
 FROM observations
 WHERE id = $1 LIMIT 1
 `
@@ -125,25 +181,174 @@ func (q *Queries) GetObservation(ctx context.Context, id int64) (Observation, er
 		&i.Narrative,
 		&i.Confidence,
 		&i.File,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
 
 const listObservations = `-- name: ListObservations :many
-SELECT id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file
-FROM observations
-ORDER BY timestamp
-LIMIT $1
-OFFSET $2
+SELECT o.id, o.site_id, o.species_id, o."timestamp", o.method, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.file, o.import_batch_id
+FROM observations o
+JOIN sites si ON o.site_id = si.id
+JOIN species sp ON o.species_id = sp.id
+WHERE ($1::timestamp IS NULL OR o."timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR o."timestamp" <= $2::timestamp)
+  AND ($3::int IS NULL OR si.block = $3::int)
+  AND ($4::tenure_type IS NULL OR si.tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR si.forest = $5::forest_type)
+  AND ($6::text IS NULL OR si.code = $6)
+  AND ($7::taxa IS NULL OR sp.taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(sp.common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(o.id, $9::int))
+  AND ($10::observation_method IS NULL OR o.method = $10::observation_method)
+  AND ($11::real IS NULL OR o.confidence >= $11::real)
+  AND ($12::bool IS NULL OR sp.native = $12::bool)
+  AND ($13::bool IS NULL OR sp.indicator = $13::bool)
+  AND ($14::timestamp IS NULL
+    OR (o."timestamp", o.id) > ($14::timestamp, $15::bigint))
+ORDER BY o."timestamp", o.id
+LIMIT $17 OFFSET $16
 `
 
 type ListObservationsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	From           pgtype.Timestamp      `json:"from"`
+	To             pgtype.Timestamp      `json:"to"`
+	Block          *int32                `json:"block"`
+	Tenure         NullTenureType        `json:"tenure"`
+	Forest         NullForestType        `json:"forest"`
+	SiteCode       *string               `json:"siteCode"`
+	Taxa           NullTaxa              `json:"taxa"`
+	CommonName     *string               `json:"commonName"`
+	Independence   *int32                `json:"independence"`
+	Method         NullObservationMethod `json:"method"`
+	MinConfidence  *float32              `json:"minConfidence"`
+	Native         *bool                 `json:"native"`
+	Indicator      *bool                 `json:"indicator"`
+	AfterTimestamp pgtype.Timestamp      `json:"afterTimestamp"`
+	AfterID        int64                 `json:"afterId"`
+	Offset         int32                 `json:"offset"`
+	Limit          int32                 `json:"limit"`
 }
 
+// ListObservations pages through the matching observations in timestamp
+// order, starting after the cursor (after_timestamp, after_id) if given.
+// Offset paging is deprecated: the cursor stays fast on late pages.
 func (q *Queries) ListObservations(ctx context.Context, arg ListObservationsParams) ([]Observation, error) {
-	rows, err := q.db.Query(ctx, listObservations, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listObservations,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+		arg.Method,
+		arg.MinConfidence,
+		arg.Native,
+		arg.Indicator,
+		arg.AfterTimestamp,
+		arg.AfterID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Observation{}
+	for rows.Next() {
+		var i Observation
+		if err := rows.Scan(
+			&i.ID,
+			&i.SiteID,
+			&i.SpeciesID,
+			&i.Timestamp,
+			&i.Method,
+			&i.AppearanceStart,
+			&i.AppearanceEnd,
+			&i.Temperature,
+			&i.Narrative,
+			&i.Confidence,
+			&i.File,
+			&i.ImportBatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listObservationsDesc = `-- name: ListObservationsDesc :many
+SELECT o.id, o.site_id, o.species_id, o."timestamp", o.method, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.file, o.import_batch_id
+FROM observations o
+JOIN sites si ON o.site_id = si.id
+JOIN species sp ON o.species_id = sp.id
+WHERE ($1::timestamp IS NULL OR o."timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR o."timestamp" <= $2::timestamp)
+  AND ($3::int IS NULL OR si.block = $3::int)
+  AND ($4::tenure_type IS NULL OR si.tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR si.forest = $5::forest_type)
+  AND ($6::text IS NULL OR si.code = $6)
+  AND ($7::taxa IS NULL OR sp.taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(sp.common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(o.id, $9::int))
+  AND ($10::observation_method IS NULL OR o.method = $10::observation_method)
+  AND ($11::real IS NULL OR o.confidence >= $11::real)
+  AND ($12::bool IS NULL OR sp.native = $12::bool)
+  AND ($13::bool IS NULL OR sp.indicator = $13::bool)
+  AND ($14::timestamp IS NULL
+    OR (o."timestamp", o.id) < ($14::timestamp, $15::bigint))
+ORDER BY o."timestamp" DESC, o.id DESC
+LIMIT $17 OFFSET $16
+`
+
+type ListObservationsDescParams struct {
+	From           pgtype.Timestamp      `json:"from"`
+	To             pgtype.Timestamp      `json:"to"`
+	Block          *int32                `json:"block"`
+	Tenure         NullTenureType        `json:"tenure"`
+	Forest         NullForestType        `json:"forest"`
+	SiteCode       *string               `json:"siteCode"`
+	Taxa           NullTaxa              `json:"taxa"`
+	CommonName     *string               `json:"commonName"`
+	Independence   *int32                `json:"independence"`
+	Method         NullObservationMethod `json:"method"`
+	MinConfidence  *float32              `json:"minConfidence"`
+	Native         *bool                 `json:"native"`
+	Indicator      *bool                 `json:"indicator"`
+	AfterTimestamp pgtype.Timestamp      `json:"afterTimestamp"`
+	AfterID        int64                 `json:"afterId"`
+	Offset         int32                 `json:"offset"`
+	Limit          int32                 `json:"limit"`
+}
+
+// ListObservationsDesc is ListObservations, newest first.
+func (q *Queries) ListObservationsDesc(ctx context.Context, arg ListObservationsDescParams) ([]Observation, error) {
+	rows, err := q.db.Query(ctx, listObservationsDesc,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+		arg.Method,
+		arg.MinConfidence,
+		arg.Native,
+		arg.Indicator,
+		arg.AfterTimestamp,
+		arg.AfterID,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +368,7 @@ func (q *Queries) ListObservations(ctx context.Context, arg ListObservationsPara
 			&i.Narrative,
 			&i.Confidence,
 			&i.File,
+			&i.ImportBatchID,
 		); err != nil {
 			return nil, err
 		}
@@ -175,7 +381,7 @@ func (q *Queries) ListObservations(ctx context.Context, arg ListObservationsPara
 }
 
 const searchObservations = `-- name: SearchObservations :many
-SELECT o.id, o.site_id, o.species_id, o.timestamp, o.method, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.file, s.code as site_code, s.name as site_name, sp.scientific_name, sp.common_name, sp.taxa
+SELECT o.id, o.site_id, o.species_id, o.timestamp, o.method, o.appearance_start, o.appearance_end, o.temperature, o.narrative, o.confidence, o.file, o.import_batch_id, s.code as site_code, s.name as site_name, sp.scientific_name, sp.common_name, sp.taxa
 FROM observations o
 JOIN sites s ON o.site_id = s.id
 JOIN species sp ON o.species_id = sp.id
@@ -195,6 +401,7 @@ type SearchObservationsRow struct {
 	Narrative       *string           `json:"narrative"`
 	Confidence      *float32          `json:"confidence"`
 	File            *string           `json:"file"`
+	ImportBatchID   *int64            `json:"importBatchId"`
 	SiteCode        string            `json:"siteCode"`
 	SiteName        *string           `json:"siteName"`
 	ScientificName  string            `json:"scientificName"`
@@ -223,6 +430,7 @@ func (q *Queries) SearchObservations(ctx context.Context, scientificName string)
 			&i.Narrative,
 			&i.Confidence,
 			&i.File,
+			&i.ImportBatchID,
 			&i.SiteCode,
 			&i.SiteName,
 			&i.ScientificName,
@@ -252,7 +460,7 @@ SET site_id = $2,
     confidence = $10,
     file = $11
 WHERE id = $1
-RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file
+RETURNING id, site_id, species_id, "timestamp", method, appearance_start, appearance_end, temperature, narrative, confidence, file, import_batch_id
 `
 
 type UpdateObservationParams struct {
@@ -296,6 +504,7 @@ func (q *Queries) UpdateObservation(ctx context.Context, arg UpdateObservationPa
 		&i.Narrative,
 		&i.Confidence,
 		&i.File,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
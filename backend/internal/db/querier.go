@@ -11,43 +11,156 @@ import (
 type Querier interface {
 	CountActiveSites(ctx context.Context, arg CountActiveSitesParams) (int64, error)
 	CountDistinctSpeciesObserved(ctx context.Context, arg CountDistinctSpeciesObservedParams) (int64, error)
-	CountObservations(ctx context.Context) (int64, error)
+	CountObservations(ctx context.Context, arg CountObservationsParams) (int64, error)
+	// CountSearchObservations counts the matches of FullTextSearchObservations.
+	CountSearchObservations(ctx context.Context, query string) (int64, error)
+	CountSearchSites(ctx context.Context, query string) (int64, error)
+	CountSearchSpecies(ctx context.Context, query string) (int64, error)
 	CountSites(ctx context.Context) (int64, error)
 	CountSpecies(ctx context.Context) (int64, error)
 	CountSpeciesByNative(ctx context.Context, arg CountSpeciesByNativeParams) ([]CountSpeciesByNativeRow, error)
+	CountUsersByRole(ctx context.Context, role UserRole) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateDeployment(ctx context.Context, arg CreateDeploymentParams) (Deployment, error)
+	CreateImportBatch(ctx context.Context, arg CreateImportBatchParams) (ImportBatch, error)
 	CreateObservation(ctx context.Context, arg CreateObservationParams) (Observation, error)
 	CreateObservations(ctx context.Context, arg []CreateObservationsParams) (int64, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateSite(ctx context.Context, arg CreateSiteParams) (Site, error)
 	CreateSpecies(ctx context.Context, arg CreateSpeciesParams) (Species, error)
-	DeleteObservation(ctx context.Context, id int64) error
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteDeployment(ctx context.Context, id int64) (int64, error)
+	DeleteDeploymentsByImportBatch(ctx context.Context, importBatchID int64) (int64, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	DeleteObservation(ctx context.Context, id int64) (int64, error)
+	DeleteObservationRevisionsByImportBatch(ctx context.Context, importBatchID int64) error
+	DeleteObservationsByImportBatch(ctx context.Context, importBatchID int64) (int64, error)
+	DeleteSession(ctx context.Context, tokenHash []byte) error
 	DeleteSite(ctx context.Context, id int64) error
-	DeleteSiteByCode(ctx context.Context, code string) error
-	DeleteSpecies(ctx context.Context, id int64) error
+	DeleteSiteByCode(ctx context.Context, code string) (int64, error)
+	// DeleteSiteByCodeCascade deletes a site together with its observations and
+	// deployments.
+	DeleteSiteByCodeCascade(ctx context.Context, code string) (int64, error)
+	// Sites still used by other imports are kept.
+	DeleteSitesByImportBatch(ctx context.Context, importBatchID int64) (int64, error)
+	DeleteSpecies(ctx context.Context, id int64) (int64, error)
+	// Species still observed by other imports are kept.
+	DeleteSpeciesByImportBatch(ctx context.Context, importBatchID int64) (int64, error)
+	DeleteUser(ctx context.Context, id int64) (int64, error)
+	DeleteUserSessions(ctx context.Context, userID int64) error
+	// DeploymentEffortBySite sums the time each site was surveyed by each
+	// method within the period, in seconds.
+	DeploymentEffortBySite(ctx context.Context, arg DeploymentEffortBySiteParams) ([]DeploymentEffortBySiteRow, error)
+	FinishImportBatch(ctx context.Context, arg FinishImportBatchParams) (ImportBatch, error)
+	// FullTextSearchObservations ranks the observations whose narrative, species
+	// or site match the tsquery, newest first among equal ranks. Each table is
+	// matched on its own, so that every match can use its index, and the
+	// matching observations are joined up afterwards.
+	FullTextSearchObservations(ctx context.Context, arg FullTextSearchObservationsParams) ([]FullTextSearchObservationsRow, error)
+	// FullTextSearchSites ranks the sites whose code or name match the
+	// tsquery.
+	FullTextSearchSites(ctx context.Context, arg FullTextSearchSitesParams) ([]FullTextSearchSitesRow, error)
+	// FullTextSearchSpecies ranks the species whose common or scientific name
+	// match the tsquery.
+	FullTextSearchSpecies(ctx context.Context, arg FullTextSearchSpeciesParams) ([]FullTextSearchSpeciesRow, error)
+	GetDeployment(ctx context.Context, id int64) (Deployment, error)
+	GetImportBatch(ctx context.Context, id int64) (ImportBatch, error)
 	GetObservation(ctx context.Context, id int64) (Observation, error)
+	// GetSessionUser returns the user of an unexpired session.
+	GetSessionUser(ctx context.Context, tokenHash []byte) (User, error)
 	GetSite(ctx context.Context, id int64) (Site, error)
 	GetSiteByCode(ctx context.Context, code string) (Site, error)
 	GetSiteIDByCode(ctx context.Context, code string) (int64, error)
 	GetSpecies(ctx context.Context, id int64) (Species, error)
 	GetSpeciesByCommonName(ctx context.Context, lower string) (Species, error)
 	GetSpeciesByScientificName(ctx context.Context, lower string) (Species, error)
+	GetUser(ctx context.Context, id int64) (User, error)
+	GetUserByEmail(ctx context.Context, lower string) (User, error)
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	// ListDeployments lists the deployments of a site and method, or of all of
+	// them, that overlap the period.
+	ListDeployments(ctx context.Context, arg ListDeploymentsParams) ([]Deployment, error)
+	ListImportBatches(ctx context.Context) ([]ListImportBatchesRow, error)
+	ListObservationDetails(ctx context.Context, arg ListObservationDetailsParams) ([]ObservationsWithDetail, error)
+	// ListObservations pages through the matching observations in timestamp
+	// order, starting after the cursor (after_timestamp, after_id) if given.
+	// Offset paging is deprecated: the cursor stays fast on late pages.
 	ListObservations(ctx context.Context, arg ListObservationsParams) ([]Observation, error)
+	// ListObservationsDesc is ListObservations, newest first.
+	ListObservationsDesc(ctx context.Context, arg ListObservationsDescParams) ([]Observation, error)
 	// ListObservedSpecies returns species observed within a time range.
 	// If site_code is NULL, results include all sites.
 	// Returns species details along with observation count.
 	ListObservedSpecies(ctx context.Context, arg ListObservedSpeciesParams) ([]ListObservedSpeciesRow, error)
+	// ListOccupancyOccasions lists the occasions of the period each site has
+	// observations in, of any species, which show it was surveyed, and whether
+	// the species was detected in them. The taxa and independence filters only
+	// narrow down the detections, not the survey effort.
+	ListOccupancyOccasions(ctx context.Context, arg ListOccupancyOccasionsParams) ([]ListOccupancyOccasionsRow, error)
+	ListOccupancySites(ctx context.Context, arg ListOccupancySitesParams) ([]Site, error)
 	ListSites(ctx context.Context) ([]Site, error)
+	ListSitesByID(ctx context.Context, ids []int64) ([]Site, error)
 	ListSpecies(ctx context.Context) ([]Species, error)
+	ListSpeciesByCommonName(ctx context.Context, commonName string) ([]Species, error)
+	ListSpeciesByID(ctx context.Context, ids []int64) ([]Species, error)
 	ListSpeciesCountByTaxa(ctx context.Context, arg ListSpeciesCountByTaxaParams) ([]ListSpeciesCountByTaxaRow, error)
+	ListUsers(ctx context.Context) ([]User, error)
+	// MergeSpecies moves the observations of the source species to the target
+	// and deletes the source. Source observations that the target already has,
+	// by site, timestamp, method and file, are deleted as duplicates.
+	MergeSpecies(ctx context.Context, arg MergeSpeciesParams) (MergeSpeciesRow, error)
+	// ObservationAbundanceBySite counts the observations of each species at each
+	// site, the abundances diversity indices are computed from.
+	ObservationAbundanceBySite(ctx context.Context, arg ObservationAbundanceBySiteParams) ([]ObservationAbundanceBySiteRow, error)
+	// ObservationActivityByMinute counts the observations of each species by
+	// minute of the day, local time, optionally only of the species_ids.
+	ObservationActivityByMinute(ctx context.Context, arg ObservationActivityByMinuteParams) ([]ObservationActivityByMinuteRow, error)
+	// ObservationActivityByMonth counts the observations of each species by
+	// month of the year.
+	ObservationActivityByMonth(ctx context.Context, arg ObservationActivityByMonthParams) ([]ObservationActivityByMonthRow, error)
 	ObservationGroupByBlocks(ctx context.Context, arg ObservationGroupByBlocksParams) ([]ObservationGroupByBlocksRow, error)
 	ObservationGroupBySites(ctx context.Context, arg ObservationGroupBySitesParams) ([]ObservationGroupBySitesRow, error)
-	ObservationTimeSeriesGroupByNative(ctx context.Context, arg ObservationTimeSeriesGroupByNativeParams) ([]ObservationTimeSeriesGroupByNativeRow, error)
+	// ObservationSpeciesBySite lists every site matching the site filters with
+	// its tenure and forest, its observations and the species among them, so
+	// that groups of sites can be compared.
+	ObservationSpeciesBySite(ctx context.Context, arg ObservationSpeciesBySiteParams) ([]ObservationSpeciesBySiteRow, error)
+	// ObservationSpeciesSequence lists the species of each observation in time
+	// order, to accumulate species over the observations or days of a survey.
+	ObservationSpeciesSequence(ctx context.Context, arg ObservationSpeciesSequenceParams) ([]ObservationSpeciesSequenceRow, error)
+	// ObservationTimeSeries counts observations per time bucket and series. The
+	// interval is a date_trunc field or "season", the austral seasons starting
+	// on the first of December, March, June and September.
+	ObservationTimeSeries(ctx context.Context, arg ObservationTimeSeriesParams) ([]ObservationTimeSeriesRow, error)
+	// The next revision of an observation the batch updated, made by a later
+	// import, takes the values from before the batch, so that undoing the later
+	// import too restores them.
+	PassOnObservationRevisionsByImportBatch(ctx context.Context, importBatchID int64) error
+	// Observations the batch updated get back the values they had before it,
+	// unless a later import updated them again.
+	RestoreObservationsByImportBatch(ctx context.Context, importBatchID int64) (int64, error)
+	RevokeAPIKey(ctx context.Context, id int64) (int64, error)
 	SearchObservations(ctx context.Context, scientificName string) ([]SearchObservationsRow, error)
 	SearchSites(ctx context.Context, code string) ([]Site, error)
 	SearchSpecies(ctx context.Context, scientificName string) ([]Species, error)
+	SetImportBatchStatus(ctx context.Context, arg SetImportBatchStatusParams) error
+	UpdateDeployment(ctx context.Context, arg UpdateDeploymentParams) (Deployment, error)
 	UpdateObservation(ctx context.Context, arg UpdateObservationParams) (Observation, error)
 	UpdateSite(ctx context.Context, arg UpdateSiteParams) (Site, error)
 	UpdateSiteByCode(ctx context.Context, arg UpdateSiteByCodeParams) (Site, error)
 	UpdateSpecies(ctx context.Context, arg UpdateSpeciesParams) (Species, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error)
+	// UpsertDeployment imports a deployment, identified by site, method, device
+	// and start. It affects no row when the deployment exists and is unchanged,
+	// or update_existing is false.
+	UpsertDeployment(ctx context.Context, arg UpsertDeploymentParams) (int64, error)
+	// UpsertObservations inserts observations by their natural key (site, species,
+	// timestamp, method, file). An existing observation is updated only when
+	// update_existing is set and its values differ, otherwise no row is returned.
+	// The values an update replaces are kept as a revision of the import batch.
+	UpsertObservations(ctx context.Context, arg []UpsertObservationsParams) *UpsertObservationsBatchResults
+	// UseAPIKey returns an unrevoked and unexpired key, recording its use.
+	UseAPIKey(ctx context.Context, keyHash []byte) (ApiKey, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,270 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: search.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const countSearchObservations = `-- name: CountSearchObservations :one
+SELECT COUNT(*)
+FROM (
+    SELECT o.id
+    FROM observations o, to_tsquery('english', $1) q
+    WHERE to_tsvector('english', COALESCE(o.narrative, '')) @@ q
+    UNION
+    SELECT o.id
+    FROM species sp
+    JOIN observations o ON o.species_id = sp.id,
+        to_tsquery('english', $1) q
+    WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q
+    UNION
+    SELECT o.id
+    FROM sites s
+    JOIN observations o ON o.site_id = s.id,
+        to_tsquery('english', $1) q
+    WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q
+) matches
+`
+
+// CountSearchObservations counts the matches of FullTextSearchObservations.
+func (q *Queries) CountSearchObservations(ctx context.Context, query string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchObservations, query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchSites = `-- name: CountSearchSites :one
+SELECT COUNT(*)
+FROM sites s, to_tsquery('english', $1) q
+WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q
+`
+
+func (q *Queries) CountSearchSites(ctx context.Context, query string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchSites, query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchSpecies = `-- name: CountSearchSpecies :one
+SELECT COUNT(*)
+FROM species sp, to_tsquery('english', $1) q
+WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q
+`
+
+func (q *Queries) CountSearchSpecies(ctx context.Context, query string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchSpecies, query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const fullTextSearchObservations = `-- name: FullTextSearchObservations :many
+WITH matches AS (
+    SELECT o.id
+    FROM observations o, to_tsquery('english', $1) q
+    WHERE to_tsvector('english', COALESCE(o.narrative, '')) @@ q
+    UNION
+    SELECT o.id
+    FROM species sp
+    JOIN observations o ON o.species_id = sp.id,
+        to_tsquery('english', $1) q
+    WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q
+    UNION
+    SELECT o.id
+    FROM sites s
+    JOIN observations o ON o.site_id = s.id,
+        to_tsquery('english', $1) q
+    WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q
+)
+SELECT o.id, o.site_id, o.species_id, o."timestamp", o.method, o.narrative, o.confidence, o.file,
+    s.code AS site_code, sp.scientific_name, sp.common_name,
+    (ts_rank(to_tsvector('english', COALESCE(o.narrative, '')), q)
+        + ts_rank(to_tsvector('english', sp.common_name || ' ' || sp.scientific_name), q)
+        + ts_rank(to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')), q))::real AS rank
+FROM matches m
+JOIN observations o ON o.id = m.id
+JOIN sites s ON o.site_id = s.id
+JOIN species sp ON o.species_id = sp.id,
+    to_tsquery('english', $1) q
+ORDER BY rank DESC, o."timestamp" DESC, o.id DESC
+LIMIT $3 OFFSET $2
+`
+
+type FullTextSearchObservationsParams struct {
+	Query  string `json:"query"`
+	Offset int32  `json:"offset"`
+	Limit  int32  `json:"limit"`
+}
+
+type FullTextSearchObservationsRow struct {
+	ID             int64             `json:"id"`
+	SiteID         int64             `json:"siteId"`
+	SpeciesID      int64             `json:"speciesId"`
+	Timestamp      time.Time         `json:"timestamp"`
+	Method         ObservationMethod `json:"method"`
+	Narrative      *string           `json:"narrative"`
+	Confidence     *float32          `json:"confidence"`
+	File           *string           `json:"file"`
+	SiteCode       string            `json:"siteCode"`
+	ScientificName string            `json:"scientificName"`
+	CommonName     string            `json:"commonName"`
+	Rank           float32           `json:"rank"`
+}
+
+// FullTextSearchObservations ranks the observations whose narrative, species
+// or site match the tsquery, newest first among equal ranks. Each table is
+// matched on its own, so that every match can use its index, and the
+// matching observations are joined up afterwards.
+func (q *Queries) FullTextSearchObservations(ctx context.Context, arg FullTextSearchObservationsParams) ([]FullTextSearchObservationsRow, error) {
+	rows, err := q.db.Query(ctx, fullTextSearchObservations, arg.Query, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FullTextSearchObservationsRow{}
+	for rows.Next() {
+		var i FullTextSearchObservationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SiteID,
+			&i.SpeciesID,
+			&i.Timestamp,
+			&i.Method,
+			&i.Narrative,
+			&i.Confidence,
+			&i.File,
+			&i.SiteCode,
+			&i.ScientificName,
+			&i.CommonName,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fullTextSearchSites = `-- name: FullTextSearchSites :many
+SELECT s.id, s.code, s.block, s.name, s.location, s.tenure, s.forest,
+    ts_rank(to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')), q)::real AS rank
+FROM sites s, to_tsquery('english', $1) q
+WHERE to_tsvector('english', s.code || ' ' || COALESCE(s.name, '')) @@ q
+ORDER BY rank DESC, s.code
+LIMIT $3 OFFSET $2
+`
+
+type FullTextSearchSitesParams struct {
+	Query  string `json:"query"`
+	Offset int32  `json:"offset"`
+	Limit  int32  `json:"limit"`
+}
+
+type FullTextSearchSitesRow struct {
+	ID       int64      `json:"id"`
+	Code     string     `json:"code"`
+	Block    int32      `json:"block"`
+	Name     *string    `json:"name"`
+	Location *string    `json:"location"`
+	Tenure   TenureType `json:"tenure"`
+	Forest   ForestType `json:"forest"`
+	Rank     float32    `json:"rank"`
+}
+
+// FullTextSearchSites ranks the sites whose code or name match the
+// tsquery.
+func (q *Queries) FullTextSearchSites(ctx context.Context, arg FullTextSearchSitesParams) ([]FullTextSearchSitesRow, error) {
+	rows, err := q.db.Query(ctx, fullTextSearchSites, arg.Query, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FullTextSearchSitesRow{}
+	for rows.Next() {
+		var i FullTextSearchSitesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Block,
+			&i.Name,
+			&i.Location,
+			&i.Tenure,
+			&i.Forest,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fullTextSearchSpecies = `-- name: FullTextSearchSpecies :many
+SELECT sp.id, sp.scientific_name, sp.common_name, sp.native, sp.taxa, sp.indicator, sp.reportable,
+    ts_rank(to_tsvector('english', sp.common_name || ' ' || sp.scientific_name), q)::real AS rank
+FROM species sp, to_tsquery('english', $1) q
+WHERE to_tsvector('english', sp.common_name || ' ' || sp.scientific_name) @@ q
+ORDER BY rank DESC, sp.scientific_name
+LIMIT $3 OFFSET $2
+`
+
+type FullTextSearchSpeciesParams struct {
+	Query  string `json:"query"`
+	Offset int32  `json:"offset"`
+	Limit  int32  `json:"limit"`
+}
+
+type FullTextSearchSpeciesRow struct {
+	ID             int64   `json:"id"`
+	ScientificName string  `json:"scientificName"`
+	CommonName     string  `json:"commonName"`
+	Native         bool    `json:"native"`
+	Taxa           Taxa    `json:"taxa"`
+	Indicator      bool    `json:"indicator"`
+	Reportable     bool    `json:"reportable"`
+	Rank           float32 `json:"rank"`
+}
+
+// FullTextSearchSpecies ranks the species whose common or scientific name
+// match the tsquery.
+func (q *Queries) FullTextSearchSpecies(ctx context.Context, arg FullTextSearchSpeciesParams) ([]FullTextSearchSpeciesRow, error) {
+	rows, err := q.db.Query(ctx, fullTextSearchSpecies, arg.Query, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FullTextSearchSpeciesRow{}
+	for rows.Next() {
+		var i FullTextSearchSpeciesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScientificName,
+			&i.CommonName,
+			&i.Native,
+			&i.Taxa,
+			&i.Indicator,
+			&i.Reportable,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
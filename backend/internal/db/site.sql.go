@@ -21,18 +21,19 @@ func (q *Queries) CountSites(ctx context.Context) (int64, error) {
 }
 
 const createSite = `-- name: CreateSite :one
-INSERT INTO sites (code, block, name, location, tenure, forest)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, code, block, name, location, tenure, forest
+INSERT INTO sites (code, block, name, location, tenure, forest, import_batch_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, code, block, name, location, tenure, forest, import_batch_id
 `
 
 type CreateSiteParams struct {
-	Code     string     `json:"code"`
-	Block    int32      `json:"block"`
-	Name     *string    `json:"name"`
-	Location *string    `json:"location"`
-	Tenure   TenureType `json:"tenure"`
-	Forest   ForestType `json:"forest"`
+	Code          string     `json:"code"`
+	Block         int32      `json:"block"`
+	Name          *string    `json:"name"`
+	Location      *string    `json:"location"`
+	Tenure        TenureType `json:"tenure"`
+	Forest        ForestType `json:"forest"`
+	ImportBatchID *int64     `json:"importBatchId"`
 }
 
 func (q *Queries) CreateSite(ctx context.Context, arg CreateSiteParams) (Site, error) {
@@ -43,6 +44,7 @@ func (q *Queries) CreateSite(ctx context.Context, arg CreateSiteParams) (Site, e
 		arg.Location,
 		arg.Tenure,
 		arg.Forest,
+		arg.ImportBatchID,
 	)
 	var i Site
 	err := row.Scan(
@@ -53,6 +55,7 @@ func (q *Queries) CreateSite(ctx context.Context, arg CreateSiteParams) (Site, e
 		&i.Location,
 		&i.Tenure,
 		&i.Forest,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
@@ -67,18 +70,45 @@ func (q *Queries) DeleteSite(ctx context.Context, id int64) error {
 	return err
 }
 
-const deleteSiteByCode = `-- name: DeleteSiteByCode :exec
+const deleteSiteByCode = `-- name: DeleteSiteByCode :execrows
 DELETE FROM sites
 WHERE code = $1
 `
 
-func (q *Queries) DeleteSiteByCode(ctx context.Context, code string) error {
-	_, err := q.db.Exec(ctx, deleteSiteByCode, code)
-	return err
+func (q *Queries) DeleteSiteByCode(ctx context.Context, code string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSiteByCode, code)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSiteByCodeCascade = `-- name: DeleteSiteByCodeCascade :execrows
+WITH site AS (
+    SELECT s.id FROM sites s WHERE s.code = $1
+), deleted_observations AS (
+    DELETE FROM observations
+    WHERE site_id IN (SELECT id FROM site)
+), deleted_deployments AS (
+    DELETE FROM deployments
+    WHERE site_id IN (SELECT id FROM site)
+)
+DELETE FROM sites
+WHERE id IN (SELECT id FROM site)
+`
+
+// DeleteSiteByCodeCascade deletes a site together with its observations and
+// deployments.
+func (q *Queries) DeleteSiteByCodeCascade(ctx context.Context, code string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSiteByCodeCascade, code)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getSite = `-- name: GetSite :one
-SELECT id, code, block, name, location, tenure, forest FROM sites
+SELECT id, code, block, name, location, tenure, forest, import_batch_id FROM sites
 WHERE id = $1 LIMIT 1
 `
 
@@ -93,12 +123,13 @@ func (q *Queries) GetSite(ctx context.Context, id int64) (Site, error) {
 		&i.Location,
 		&i.Tenure,
 		&i.Forest,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
 
 const getSiteByCode = `-- name: GetSiteByCode :one
-SELECT id, code, block, name, location, tenure, forest FROM sites
+SELECT id, code, block, name, location, tenure, forest, import_batch_id FROM sites
 WHERE code = $1 LIMIT 1
 `
 
@@ -113,6 +144,7 @@ func (q *Queries) GetSiteByCode(ctx context.Context, code string) (Site, error)
 		&i.Location,
 		&i.Tenure,
 		&i.Forest,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
@@ -129,7 +161,7 @@ func (q *Queries) GetSiteIDByCode(ctx context.Context, code string) (int64, erro
 }
 
 const listSites = `-- name: ListSites :many
-SELECT id, code, block, name, location, tenure, forest FROM sites
+SELECT id, code, block, name, location, tenure, forest, import_batch_id FROM sites
 ORDER BY code
 `
 
@@ -150,6 +182,41 @@ func (q *Queries) ListSites(ctx context.Context) ([]Site, error) {
 			&i.Location,
 			&i.Tenure,
 			&i.Forest,
+			&i.ImportBatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSitesByID = `-- name: ListSitesByID :many
+SELECT id, code, block, name, location, tenure, forest, import_batch_id FROM sites
+WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) ListSitesByID(ctx context.Context, ids []int64) ([]Site, error) {
+	rows, err := q.db.Query(ctx, listSitesByID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Site{}
+	for rows.Next() {
+		var i Site
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Block,
+			&i.Name,
+			&i.Location,
+			&i.Tenure,
+			&i.Forest,
+			&i.ImportBatchID,
 		); err != nil {
 			return nil, err
 		}
@@ -162,7 +229,7 @@ func (q *Queries) ListSites(ctx context.Context) ([]Site, error) {
 }
 
 const searchSites = `-- name: SearchSites :many
-SELECT id, code, block, name, location, tenure, forest FROM sites
+SELECT id, code, block, name, location, tenure, forest, import_batch_id FROM sites
 WHERE code ILIKE $1 OR name ILIKE $1
 ORDER BY code
 `
@@ -184,6 +251,7 @@ func (q *Queries) SearchSites(ctx context.Context, code string) ([]Site, error)
 			&i.Location,
 			&i.Tenure,
 			&i.Forest,
+			&i.ImportBatchID,
 		); err != nil {
 			return nil, err
 		}
@@ -199,7 +267,7 @@ const updateSite = `-- name: UpdateSite :one
 UPDATE sites
 SET code = $2, block = $3, name = $4, location = $5, tenure = $6, forest = $7
 WHERE id = $1
-RETURNING id, code, block, name, location, tenure, forest
+RETURNING id, code, block, name, location, tenure, forest, import_batch_id
 `
 
 type UpdateSiteParams struct {
@@ -231,6 +299,7 @@ func (q *Queries) UpdateSite(ctx context.Context, arg UpdateSiteParams) (Site, e
 		&i.Location,
 		&i.Tenure,
 		&i.Forest,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
@@ -239,7 +308,7 @@ const updateSiteByCode = `-- name: UpdateSiteByCode :one
 UPDATE sites
 SET block = $2, name = $3, location = $4, tenure = $5, forest = $6
 WHERE code = $1
-RETURNING id, code, block, name, location, tenure, forest
+RETURNING id, code, block, name, location, tenure, forest, import_batch_id
 `
 
 type UpdateSiteByCodeParams struct {
@@ -269,6 +338,7 @@ func (q *Queries) UpdateSiteByCode(ctx context.Context, arg UpdateSiteByCodePara
 		&i.Location,
 		&i.Tenure,
 		&i.Forest,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
@@ -23,9 +23,9 @@ func (q *Queries) CountSpecies(ctx context.Context) (int64, error) {
 }
 
 const createSpecies = `-- name: CreateSpecies :one
-INSERT INTO species (scientific_name, common_name, native, taxa, indicator, reportable)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable
+INSERT INTO species (scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 `
 
 type CreateSpeciesParams struct {
@@ -35,6 +35,7 @@ type CreateSpeciesParams struct {
 	Taxa           Taxa   `json:"taxa"`
 	Indicator      bool   `json:"indicator"`
 	Reportable     bool   `json:"reportable"`
+	ImportBatchID  *int64 `json:"importBatchId"`
 }
 
 func (q *Queries) CreateSpecies(ctx context.Context, arg CreateSpeciesParams) (Species, error) {
@@ -45,6 +46,7 @@ func (q *Queries) CreateSpecies(ctx context.Context, arg CreateSpeciesParams) (S
 		arg.Taxa,
 		arg.Indicator,
 		arg.Reportable,
+		arg.ImportBatchID,
 	)
 	var i Species
 	err := row.Scan(
@@ -55,22 +57,26 @@ func (q *Queries) CreateSpecies(ctx context.Context, arg CreateSpeciesParams) (S
 		&i.Taxa,
 		&i.Indicator,
 		&i.Reportable,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
 
-const deleteSpecies = `-- name: DeleteSpecies :exec
+const deleteSpecies = `-- name: DeleteSpecies :execrows
 DELETE FROM species
 WHERE id = $1
 `
 
-func (q *Queries) DeleteSpecies(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteSpecies, id)
-	return err
+func (q *Queries) DeleteSpecies(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSpecies, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getSpecies = `-- name: GetSpecies :one
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE id = $1 LIMIT 1
 `
@@ -86,12 +92,13 @@ func (q *Queries) GetSpecies(ctx context.Context, id int64) (Species, error) {
 		&i.Taxa,
 		&i.Indicator,
 		&i.Reportable,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
 
 const getSpeciesByCommonName = `-- name: GetSpeciesByCommonName :one
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE lower(common_name) = LOWER($1) LIMIT 1
 `
@@ -107,12 +114,13 @@ func (q *Queries) GetSpeciesByCommonName(ctx context.Context, lower string) (Spe
 		&i.Taxa,
 		&i.Indicator,
 		&i.Reportable,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
 
 const getSpeciesByScientificName = `-- name: GetSpeciesByScientificName :one
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE lower(scientific_name) = LOWER($1) LIMIT 1
 `
@@ -128,6 +136,7 @@ func (q *Queries) GetSpeciesByScientificName(ctx context.Context, lower string)
 		&i.Taxa,
 		&i.Indicator,
 		&i.Reportable,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
@@ -194,7 +203,7 @@ func (q *Queries) ListObservedSpecies(ctx context.Context, arg ListObservedSpeci
 }
 
 const listSpecies = `-- name: ListSpecies :many
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 ORDER BY scientific_name
 `
@@ -216,6 +225,43 @@ func (q *Queries) ListSpecies(ctx context.Context) ([]Species, error) {
 			&i.Taxa,
 			&i.Indicator,
 			&i.Reportable,
+			&i.ImportBatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSpeciesByCommonName = `-- name: ListSpeciesByCommonName :many
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
+FROM species
+WHERE lower(common_name) = LOWER($1)
+ORDER BY id
+`
+
+func (q *Queries) ListSpeciesByCommonName(ctx context.Context, commonName string) ([]Species, error) {
+	rows, err := q.db.Query(ctx, listSpeciesByCommonName, commonName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Species{}
+	for rows.Next() {
+		var i Species
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScientificName,
+			&i.CommonName,
+			&i.Native,
+			&i.Taxa,
+			&i.Indicator,
+			&i.Reportable,
+			&i.ImportBatchID,
 		); err != nil {
 			return nil, err
 		}
@@ -227,8 +273,94 @@ func (q *Queries) ListSpecies(ctx context.Context) ([]Species, error) {
 	return items, nil
 }
 
+const listSpeciesByID = `-- name: ListSpeciesByID :many
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
+FROM species
+WHERE id = ANY($1::bigint[])
+`
+
+func (q *Queries) ListSpeciesByID(ctx context.Context, ids []int64) ([]Species, error) {
+	rows, err := q.db.Query(ctx, listSpeciesByID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Species{}
+	for rows.Next() {
+		var i Species
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScientificName,
+			&i.CommonName,
+			&i.Native,
+			&i.Taxa,
+			&i.Indicator,
+			&i.Reportable,
+			&i.ImportBatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mergeSpecies = `-- name: MergeSpecies :one
+WITH moved AS (
+    UPDATE observations o
+    SET species_id = $1
+    WHERE o.species_id = $2
+      AND NOT EXISTS (
+        SELECT 1 FROM observations t
+        WHERE t.species_id = $1
+          AND t.site_id = o.site_id
+          AND t."timestamp" = o."timestamp"
+          AND t.method = o.method
+          AND COALESCE(t.file, '') = COALESCE(o.file, '')
+      )
+    RETURNING o.id
+), duplicates AS (
+    DELETE FROM observations o
+    WHERE o.species_id = $2
+      AND o.id NOT IN (SELECT id FROM moved)
+    RETURNING o.id
+), deleted AS (
+    DELETE FROM species
+    WHERE id = $2
+    RETURNING id
+)
+SELECT
+    (SELECT COUNT(*) FROM moved) AS moved_observations,
+    (SELECT COUNT(*) FROM duplicates) AS duplicate_observations,
+    (SELECT COUNT(*) FROM deleted) AS deleted_species
+`
+
+type MergeSpeciesParams struct {
+	TargetID int64 `json:"targetId"`
+	SourceID int64 `json:"sourceId"`
+}
+
+type MergeSpeciesRow struct {
+	MovedObservations     int64 `json:"movedObservations"`
+	DuplicateObservations int64 `json:"duplicateObservations"`
+	DeletedSpecies        int64 `json:"deletedSpecies"`
+}
+
+// MergeSpecies moves the observations of the source species to the target
+// and deletes the source. Source observations that the target already has,
+// by site, timestamp, method and file, are deleted as duplicates.
+func (q *Queries) MergeSpecies(ctx context.Context, arg MergeSpeciesParams) (MergeSpeciesRow, error) {
+	row := q.db.QueryRow(ctx, mergeSpecies, arg.TargetID, arg.SourceID)
+	var i MergeSpeciesRow
+	err := row.Scan(&i.MovedObservations, &i.DuplicateObservations, &i.DeletedSpecies)
+	return i, err
+}
+
 const searchSpecies = `-- name: SearchSpecies :many
-SELECT id, scientific_name, common_name, native, taxa, indicator, reportable
+SELECT id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 FROM species
 WHERE scientific_name ILIKE $1 OR common_name ILIKE $1
 ORDER BY scientific_name
@@ -251,6 +383,7 @@ func (q *Queries) SearchSpecies(ctx context.Context, scientificName string) ([]S
 			&i.Taxa,
 			&i.Indicator,
 			&i.Reportable,
+			&i.ImportBatchID,
 		); err != nil {
 			return nil, err
 		}
@@ -267,7 +400,7 @@ UPDATE species
 SET scientific_name = $2, common_name = $3, native = $4,
     taxa = $5, indicator = $6, reportable = $7
 WHERE id = $1
-RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable
+RETURNING id, scientific_name, common_name, native, taxa, indicator, reportable, import_batch_id
 `
 
 type UpdateSpeciesParams struct {
@@ -299,6 +432,7 @@ func (q *Queries) UpdateSpecies(ctx context.Context, arg UpdateSpeciesParams) (S
 		&i.Taxa,
 		&i.Indicator,
 		&i.Reportable,
+		&i.ImportBatchID,
 	)
 	return i, err
 }
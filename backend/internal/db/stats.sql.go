@@ -56,19 +56,25 @@ FROM observations_with_details
 WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
   AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
   AND ($3::int IS NULL OR block = $3::int)
-  AND ($4::text IS NULL OR site_code = $4)
-  AND ($5::taxa IS NULL OR taxa = $5::taxa)
-  AND ($6::text IS NULL OR LOWER(common_name) = LOWER($6::text))
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
 GROUP BY native
 `
 
 type CountSpeciesByNativeParams struct {
-	From       pgtype.Timestamp `json:"from"`
-	To         pgtype.Timestamp `json:"to"`
-	Block      *int32           `json:"block"`
-	SiteCode   *string          `json:"siteCode"`
-	Taxa       NullTaxa         `json:"taxa"`
-	CommonName *string          `json:"commonName"`
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
 }
 
 type CountSpeciesByNativeRow struct {
@@ -82,9 +88,12 @@ func (q *Queries) CountSpeciesByNative(ctx context.Context, arg CountSpeciesByNa
 		arg.From,
 		arg.To,
 		arg.Block,
+		arg.Tenure,
+		arg.Forest,
 		arg.SiteCode,
 		arg.Taxa,
 		arg.CommonName,
+		arg.Independence,
 	)
 	if err != nil {
 		return nil, err
@@ -110,19 +119,25 @@ FROM observations_with_details
 WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
   AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
   AND ($3::int IS NULL OR block = $3::int)
-  AND ($4::text IS NULL OR site_code = $4)
-  AND ($5::taxa IS NULL OR taxa = $5::taxa)
-  AND ($6::text IS NULL OR LOWER(common_name) = LOWER($6::text))
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
 GROUP BY taxa
 `
 
 type ListSpeciesCountByTaxaParams struct {
-	From       pgtype.Timestamp `json:"from"`
-	To         pgtype.Timestamp `json:"to"`
-	Block      *int32           `json:"block"`
-	SiteCode   *string          `json:"siteCode"`
-	Taxa       NullTaxa         `json:"taxa"`
-	CommonName *string          `json:"commonName"`
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
 }
 
 type ListSpeciesCountByTaxaRow struct {
@@ -135,9 +150,12 @@ func (q *Queries) ListSpeciesCountByTaxa(ctx context.Context, arg ListSpeciesCou
 		arg.From,
 		arg.To,
 		arg.Block,
+		arg.Tenure,
+		arg.Forest,
 		arg.SiteCode,
 		arg.Taxa,
 		arg.CommonName,
+		arg.Independence,
 	)
 	if err != nil {
 		return nil, err
@@ -157,26 +175,251 @@ func (q *Queries) ListSpeciesCountByTaxa(ctx context.Context, arg ListSpeciesCou
 	return items, nil
 }
 
+const observationAbundanceBySite = `-- name: ObservationAbundanceBySite :many
+SELECT site_code, block, species_id, COUNT(*) AS observation_count
+FROM observations_with_details
+WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
+  AND ($3::int IS NULL OR block = $3::int)
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
+GROUP BY site_code, block, species_id
+ORDER BY site_code, species_id
+`
+
+type ObservationAbundanceBySiteParams struct {
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
+}
+
+type ObservationAbundanceBySiteRow struct {
+	SiteCode         string `json:"siteCode"`
+	Block            int32  `json:"block"`
+	SpeciesID        int64  `json:"speciesId"`
+	ObservationCount int64  `json:"observationCount"`
+}
+
+// ObservationAbundanceBySite counts the observations of each species at each
+// site, the abundances diversity indices are computed from.
+func (q *Queries) ObservationAbundanceBySite(ctx context.Context, arg ObservationAbundanceBySiteParams) ([]ObservationAbundanceBySiteRow, error) {
+	rows, err := q.db.Query(ctx, observationAbundanceBySite,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ObservationAbundanceBySiteRow{}
+	for rows.Next() {
+		var i ObservationAbundanceBySiteRow
+		if err := rows.Scan(
+			&i.SiteCode,
+			&i.Block,
+			&i.SpeciesID,
+			&i.ObservationCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const observationActivityByMinute = `-- name: ObservationActivityByMinute :many
+SELECT species_id, scientific_name, common_name, taxa,
+    (EXTRACT(hour FROM "timestamp") * 60 + EXTRACT(minute FROM "timestamp"))::int AS minute_of_day,
+    COUNT(*) AS observation_count
+FROM observations_with_details
+WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
+  AND ($3::int IS NULL OR block = $3::int)
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
+  AND ($10::bigint[] IS NULL OR species_id = ANY($10::bigint[]))
+GROUP BY species_id, scientific_name, common_name, taxa, minute_of_day
+ORDER BY species_id, minute_of_day
+`
+
+type ObservationActivityByMinuteParams struct {
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
+	SpeciesIds   []int64          `json:"speciesIds"`
+}
+
+type ObservationActivityByMinuteRow struct {
+	SpeciesID        int64  `json:"speciesId"`
+	ScientificName   string `json:"scientificName"`
+	CommonName       string `json:"commonName"`
+	Taxa             Taxa   `json:"taxa"`
+	MinuteOfDay      int32  `json:"minuteOfDay"`
+	ObservationCount int64  `json:"observationCount"`
+}
+
+// ObservationActivityByMinute counts the observations of each species by
+// minute of the day, local time, optionally only of the species_ids.
+func (q *Queries) ObservationActivityByMinute(ctx context.Context, arg ObservationActivityByMinuteParams) ([]ObservationActivityByMinuteRow, error) {
+	rows, err := q.db.Query(ctx, observationActivityByMinute,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+		arg.SpeciesIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ObservationActivityByMinuteRow{}
+	for rows.Next() {
+		var i ObservationActivityByMinuteRow
+		if err := rows.Scan(
+			&i.SpeciesID,
+			&i.ScientificName,
+			&i.CommonName,
+			&i.Taxa,
+			&i.MinuteOfDay,
+			&i.ObservationCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const observationActivityByMonth = `-- name: ObservationActivityByMonth :many
+SELECT species_id, EXTRACT(month FROM "timestamp")::int AS month, COUNT(*) AS observation_count
+FROM observations_with_details
+WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
+  AND ($3::int IS NULL OR block = $3::int)
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
+GROUP BY species_id, month
+ORDER BY species_id, month
+`
+
+type ObservationActivityByMonthParams struct {
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
+}
+
+type ObservationActivityByMonthRow struct {
+	SpeciesID        int64 `json:"speciesId"`
+	Month            int32 `json:"month"`
+	ObservationCount int64 `json:"observationCount"`
+}
+
+// ObservationActivityByMonth counts the observations of each species by
+// month of the year.
+func (q *Queries) ObservationActivityByMonth(ctx context.Context, arg ObservationActivityByMonthParams) ([]ObservationActivityByMonthRow, error) {
+	rows, err := q.db.Query(ctx, observationActivityByMonth,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ObservationActivityByMonthRow{}
+	for rows.Next() {
+		var i ObservationActivityByMonthRow
+		if err := rows.Scan(&i.SpeciesID, &i.Month, &i.ObservationCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const observationGroupByBlocks = `-- name: ObservationGroupByBlocks :many
 SELECT block, COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count
 FROM observations_with_details
 WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
   AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
   AND ($3::int IS NULL OR block = $3::int)
-  AND ($4::text IS NULL OR site_code = $4)
-  AND ($5::taxa IS NULL OR taxa = $5::taxa)
-  AND ($6::text IS NULL OR LOWER(common_name) = LOWER($6::text))
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
 GROUP BY block
 ORDER BY block
 `
 
 type ObservationGroupByBlocksParams struct {
-	From       pgtype.Timestamp `json:"from"`
-	To         pgtype.Timestamp `json:"to"`
-	Block      *int32           `json:"block"`
-	SiteCode   *string          `json:"siteCode"`
-	Taxa       NullTaxa         `json:"taxa"`
-	CommonName *string          `json:"commonName"`
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
 }
 
 type ObservationGroupByBlocksRow struct {
@@ -190,9 +433,12 @@ func (q *Queries) ObservationGroupByBlocks(ctx context.Context, arg ObservationG
 		arg.From,
 		arg.To,
 		arg.Block,
+		arg.Tenure,
+		arg.Forest,
 		arg.SiteCode,
 		arg.Taxa,
 		arg.CommonName,
+		arg.Independence,
 	)
 	if err != nil {
 		return nil, err
@@ -213,31 +459,41 @@ func (q *Queries) ObservationGroupByBlocks(ctx context.Context, arg ObservationG
 }
 
 const observationGroupBySites = `-- name: ObservationGroupBySites :many
-SELECT site_code, COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count
+SELECT site_code, COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count,
+    COUNT(*) FILTER (WHERE method = 'camera') AS camera_count,
+    COUNT(*) FILTER (WHERE method = 'audio') AS audio_count
 FROM observations_with_details
 WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
   AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
   AND ($3::int IS NULL OR block = $3::int)
-  AND ($4::text IS NULL OR site_code = $4)
-  AND ($5::taxa IS NULL OR taxa = $5::taxa)
-  AND ($6::text IS NULL OR LOWER(common_name) = LOWER($6::text))
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
 GROUP BY site_code
 ORDER BY site_code
 `
 
 type ObservationGroupBySitesParams struct {
-	From       pgtype.Timestamp `json:"from"`
-	To         pgtype.Timestamp `json:"to"`
-	Block      *int32           `json:"block"`
-	SiteCode   *string          `json:"siteCode"`
-	Taxa       NullTaxa         `json:"taxa"`
-	CommonName *string          `json:"commonName"`
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
 }
 
 type ObservationGroupBySitesRow struct {
 	SiteCode         string `json:"siteCode"`
 	SpeciesCount     int64  `json:"speciesCount"`
 	ObservationCount int64  `json:"observationCount"`
+	CameraCount      int64  `json:"cameraCount"`
+	AudioCount       int64  `json:"audioCount"`
 }
 
 func (q *Queries) ObservationGroupBySites(ctx context.Context, arg ObservationGroupBySitesParams) ([]ObservationGroupBySitesRow, error) {
@@ -245,9 +501,12 @@ func (q *Queries) ObservationGroupBySites(ctx context.Context, arg ObservationGr
 		arg.From,
 		arg.To,
 		arg.Block,
+		arg.Tenure,
+		arg.Forest,
 		arg.SiteCode,
 		arg.Taxa,
 		arg.CommonName,
+		arg.Independence,
 	)
 	if err != nil {
 		return nil, err
@@ -256,7 +515,90 @@ func (q *Queries) ObservationGroupBySites(ctx context.Context, arg ObservationGr
 	items := []ObservationGroupBySitesRow{}
 	for rows.Next() {
 		var i ObservationGroupBySitesRow
-		if err := rows.Scan(&i.SiteCode, &i.SpeciesCount, &i.ObservationCount); err != nil {
+		if err := rows.Scan(
+			&i.SiteCode,
+			&i.SpeciesCount,
+			&i.ObservationCount,
+			&i.CameraCount,
+			&i.AudioCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const observationSpeciesBySite = `-- name: ObservationSpeciesBySite :many
+SELECT s.code AS site_code, s.tenure, s.forest, COUNT(o.id) AS observation_count,
+    COALESCE(ARRAY_AGG(DISTINCT o.species_id) FILTER (WHERE o.species_id IS NOT NULL), '{}')::bigint[] AS species_ids
+FROM sites s
+LEFT JOIN observations_with_details o ON o.site_id = s.id
+  AND ($1::timestamp IS NULL OR o."timestamp" >= $1::timestamp)
+  AND ($2::timestamp IS NULL OR o."timestamp" <= $2::timestamp)
+  AND ($3::taxa IS NULL OR o.taxa = $3::taxa)
+  AND ($4::text IS NULL OR LOWER(o.common_name) = LOWER($4::text))
+  AND ($5::int IS NULL OR starts_event(o.id, $5::int))
+WHERE ($6::int IS NULL OR s.block = $6::int)
+  AND ($7::tenure_type IS NULL OR s.tenure = $7::tenure_type)
+  AND ($8::forest_type IS NULL OR s.forest = $8::forest_type)
+  AND ($9::text IS NULL OR s.code = $9)
+GROUP BY s.id
+ORDER BY s.code
+`
+
+type ObservationSpeciesBySiteParams struct {
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+}
+
+type ObservationSpeciesBySiteRow struct {
+	SiteCode         string     `json:"siteCode"`
+	Tenure           TenureType `json:"tenure"`
+	Forest           ForestType `json:"forest"`
+	ObservationCount int64      `json:"observationCount"`
+	SpeciesIds       []int64    `json:"speciesIds"`
+}
+
+// ObservationSpeciesBySite lists every site matching the site filters with
+// its tenure and forest, its observations and the species among them, so
+// that groups of sites can be compared.
+func (q *Queries) ObservationSpeciesBySite(ctx context.Context, arg ObservationSpeciesBySiteParams) ([]ObservationSpeciesBySiteRow, error) {
+	rows, err := q.db.Query(ctx, observationSpeciesBySite,
+		arg.From,
+		arg.To,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ObservationSpeciesBySiteRow{}
+	for rows.Next() {
+		var i ObservationSpeciesBySiteRow
+		if err := rows.Scan(
+			&i.SiteCode,
+			&i.Tenure,
+			&i.Forest,
+			&i.ObservationCount,
+			&i.SpeciesIds,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -267,54 +609,153 @@ func (q *Queries) ObservationGroupBySites(ctx context.Context, arg ObservationGr
 	return items, nil
 }
 
-const observationTimeSeriesGroupByNative = `-- name: ObservationTimeSeriesGroupByNative :many
-SELECT native as is_native, date_trunc('year', "timestamp")::timestamp AS year, COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count
+const observationSpeciesSequence = `-- name: ObservationSpeciesSequence :many
+SELECT site_code, block, "timestamp", species_id
 FROM observations_with_details
 WHERE ($1::timestamp IS NULL OR "timestamp" >= $1::timestamp)
   AND ($2::timestamp IS NULL OR "timestamp" <= $2::timestamp)
   AND ($3::int IS NULL OR block = $3::int)
-  AND ($4::text IS NULL OR site_code = $4)
-  AND ($5::taxa IS NULL OR taxa = $5::taxa)
-  AND ($6::text IS NULL OR LOWER(common_name) = LOWER($6::text))
-GROUP BY year, native
-ORDER BY year
+  AND ($4::tenure_type IS NULL OR tenure = $4::tenure_type)
+  AND ($5::forest_type IS NULL OR forest = $5::forest_type)
+  AND ($6::text IS NULL OR site_code = $6)
+  AND ($7::taxa IS NULL OR taxa = $7::taxa)
+  AND ($8::text IS NULL OR LOWER(common_name) = LOWER($8::text))
+  AND ($9::int IS NULL OR starts_event(id, $9::int))
+ORDER BY "timestamp", id
+`
+
+type ObservationSpeciesSequenceParams struct {
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
+}
+
+type ObservationSpeciesSequenceRow struct {
+	SiteCode  string    `json:"siteCode"`
+	Block     int32     `json:"block"`
+	Timestamp time.Time `json:"timestamp"`
+	SpeciesID int64     `json:"speciesId"`
+}
+
+// ObservationSpeciesSequence lists the species of each observation in time
+// order, to accumulate species over the observations or days of a survey.
+func (q *Queries) ObservationSpeciesSequence(ctx context.Context, arg ObservationSpeciesSequenceParams) ([]ObservationSpeciesSequenceRow, error) {
+	rows, err := q.db.Query(ctx, observationSpeciesSequence,
+		arg.From,
+		arg.To,
+		arg.Block,
+		arg.Tenure,
+		arg.Forest,
+		arg.SiteCode,
+		arg.Taxa,
+		arg.CommonName,
+		arg.Independence,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ObservationSpeciesSequenceRow{}
+	for rows.Next() {
+		var i ObservationSpeciesSequenceRow
+		if err := rows.Scan(
+			&i.SiteCode,
+			&i.Block,
+			&i.Timestamp,
+			&i.SpeciesID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const observationTimeSeries = `-- name: ObservationTimeSeries :many
+SELECT
+    (CASE WHEN $1::text = 'season'
+        THEN date_trunc('quarter', "timestamp" + interval '1 month') - interval '1 month'
+        ELSE date_trunc($1::text, "timestamp")
+    END)::timestamp AS bucket,
+    (CASE $2::text
+        WHEN 'taxa' THEN taxa::text
+        WHEN 'method' THEN method::text
+        WHEN 'forest' THEN forest::text
+        WHEN 'tenure' THEN tenure::text
+        ELSE CASE WHEN native THEN 'native' ELSE 'non-native' END
+    END)::text AS series,
+    COUNT(DISTINCT species_id) AS species_count, COUNT(*) AS observation_count
+FROM observations_with_details
+WHERE ($3::timestamp IS NULL OR "timestamp" >= $3::timestamp)
+  AND ($4::timestamp IS NULL OR "timestamp" <= $4::timestamp)
+  AND ($5::int IS NULL OR block = $5::int)
+  AND ($6::tenure_type IS NULL OR tenure = $6::tenure_type)
+  AND ($7::forest_type IS NULL OR forest = $7::forest_type)
+  AND ($8::text IS NULL OR site_code = $8)
+  AND ($9::taxa IS NULL OR taxa = $9::taxa)
+  AND ($10::text IS NULL OR LOWER(common_name) = LOWER($10::text))
+  AND ($11::int IS NULL OR starts_event(id, $11::int))
+GROUP BY bucket, series
+ORDER BY bucket, series
 `
 
-type ObservationTimeSeriesGroupByNativeParams struct {
-	From       pgtype.Timestamp `json:"from"`
-	To         pgtype.Timestamp `json:"to"`
-	Block      *int32           `json:"block"`
-	SiteCode   *string          `json:"siteCode"`
-	Taxa       NullTaxa         `json:"taxa"`
-	CommonName *string          `json:"commonName"`
+type ObservationTimeSeriesParams struct {
+	Interval     string           `json:"interval"`
+	GroupBy      string           `json:"groupBy"`
+	From         pgtype.Timestamp `json:"from"`
+	To           pgtype.Timestamp `json:"to"`
+	Block        *int32           `json:"block"`
+	Tenure       NullTenureType   `json:"tenure"`
+	Forest       NullForestType   `json:"forest"`
+	SiteCode     *string          `json:"siteCode"`
+	Taxa         NullTaxa         `json:"taxa"`
+	CommonName   *string          `json:"commonName"`
+	Independence *int32           `json:"independence"`
 }
 
-type ObservationTimeSeriesGroupByNativeRow struct {
-	IsNative         bool      `json:"isNative"`
-	Year             time.Time `json:"year"`
+type ObservationTimeSeriesRow struct {
+	Bucket           time.Time `json:"bucket"`
+	Series           string    `json:"series"`
 	SpeciesCount     int64     `json:"speciesCount"`
 	ObservationCount int64     `json:"observationCount"`
 }
 
-func (q *Queries) ObservationTimeSeriesGroupByNative(ctx context.Context, arg ObservationTimeSeriesGroupByNativeParams) ([]ObservationTimeSeriesGroupByNativeRow, error) {
-	rows, err := q.db.Query(ctx, observationTimeSeriesGroupByNative,
+// ObservationTimeSeries counts observations per time bucket and series. The
+// interval is a date_trunc field or "season", the austral seasons starting
+// on the first of December, March, June and September.
+func (q *Queries) ObservationTimeSeries(ctx context.Context, arg ObservationTimeSeriesParams) ([]ObservationTimeSeriesRow, error) {
+	rows, err := q.db.Query(ctx, observationTimeSeries,
+		arg.Interval,
+		arg.GroupBy,
 		arg.From,
 		arg.To,
 		arg.Block,
+		arg.Tenure,
+		arg.Forest,
 		arg.SiteCode,
 		arg.Taxa,
 		arg.CommonName,
+		arg.Independence,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []ObservationTimeSeriesGroupByNativeRow{}
+	items := []ObservationTimeSeriesRow{}
 	for rows.Next() {
-		var i ObservationTimeSeriesGroupByNativeRow
+		var i ObservationTimeSeriesRow
 		if err := rows.Scan(
-			&i.IsNative,
-			&i.Year,
+			&i.Bucket,
+			&i.Series,
 			&i.SpeciesCount,
 			&i.ObservationCount,
 		); err != nil {
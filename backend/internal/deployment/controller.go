@@ -0,0 +1,262 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type Controller struct {
+	q db.Querier
+}
+
+func NewController(queries db.Querier) *Controller {
+	return &Controller{
+		q: queries,
+	}
+}
+
+// ListDeployments godoc
+//
+//	@Summary		List deployments
+//	@Description	List the deployments of devices and surveys at the sites, by site and start time
+//	@Tags			deployment
+//	@Param			from		query	string	False	"Only deployments still running from this date"	format(date-time)
+//	@Param			to			query	string	False	"Only deployments started by this date"			format(date-time)
+//	@Param			siteCode	query	string	False	"Filter by site code"
+//	@Param			method		query	string	False	"Filter by method"	Enums(audio, camera, observed)
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]db.Deployment
+//	@Failure		400	{object}	utils.HttpError
+//	@Router			/deployments [get]
+func (u *Controller) ListDeployments(c *gin.Context) {
+	var req ListDeploymentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	params := db.ListDeploymentsParams{
+		SiteCode: req.SiteCode,
+		Method:   db.NullObservationMethod{Valid: req.Method != nil},
+		From:     req.From.ToPGTime(),
+		To:       req.To.ToPGTime(),
+	}
+	if req.Method != nil {
+		if !req.Method.Valid() {
+			c.Error(utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown observation method %q", *req.Method)))
+			return
+		}
+		params.Method.ObservationMethod = *req.Method
+	}
+
+	deployments, err := u.q.ListDeployments(c.Request.Context(), params)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to list deployments: %w", err))
+		return
+	}
+	c.JSON(200, deployments)
+}
+
+// GetDeployment godoc
+//
+//	@Summary		Get deployment
+//	@Description	Get a deployment by ID
+//	@Tags			deployment
+//	@Param			id	path	integer	True	"ID of the deployment"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	db.Deployment
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Router			/deployments/{id} [get]
+func (u *Controller) GetDeployment(c *gin.Context) {
+	id, err := deploymentID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	d, err := u.q.GetDeployment(c.Request.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "deployment not found", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to get deployment: %w", err))
+		return
+	}
+	c.JSON(200, d)
+}
+
+// CreateDeployment godoc
+//
+//	@Summary		Create deployment
+//	@Description	Record the period a device, or a survey, was recording at a site
+//	@Tags			deployment
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			deployment	body		DeploymentInput	true	"The deployment"
+//	@Success		201			{object}	db.Deployment
+//	@Failure		400			{object}	utils.HttpError
+//	@Failure		401			{object}	utils.HttpError
+//	@Failure		403			{object}	utils.HttpError
+//	@Failure		409			{object}	utils.HttpError
+//	@Router			/deployments [post]
+func (u *Controller) CreateDeployment(c *gin.Context) {
+	var input DeploymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if err := u.validate(c.Request.Context(), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	d, err := u.q.CreateDeployment(c.Request.Context(), db.CreateDeploymentParams{
+		SiteID:    input.SiteID,
+		Method:    input.Method,
+		DeviceID:  input.DeviceID,
+		StartTime: localTime(input.StartTime),
+		EndTime:   localTime(input.EndTime),
+		Notes:     input.Notes,
+	})
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "deployment already exists", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to create deployment: %w", err))
+		return
+	}
+
+	c.JSON(201, d)
+}
+
+// UpdateDeployment godoc
+//
+//	@Summary		Replace deployment
+//	@Description	Replace every value of a deployment
+//	@Tags			deployment
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		integer			True	"ID of the deployment"
+//	@Param			deployment	body		DeploymentInput	true	"The deployment"
+//	@Success		200			{object}	db.Deployment
+//	@Failure		400			{object}	utils.HttpError
+//	@Failure		401			{object}	utils.HttpError
+//	@Failure		403			{object}	utils.HttpError
+//	@Failure		404			{object}	utils.HttpError
+//	@Failure		409			{object}	utils.HttpError
+//	@Router			/deployments/{id} [put]
+func (u *Controller) UpdateDeployment(c *gin.Context) {
+	id, err := deploymentID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var input DeploymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if err := u.validate(c.Request.Context(), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	d, err := u.q.UpdateDeployment(c.Request.Context(), db.UpdateDeploymentParams{
+		ID:        id,
+		SiteID:    input.SiteID,
+		Method:    input.Method,
+		DeviceID:  input.DeviceID,
+		StartTime: localTime(input.StartTime),
+		EndTime:   localTime(input.EndTime),
+		Notes:     input.Notes,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "deployment not found", err))
+		return
+	}
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "another deployment has the same site, method, device and start time", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to update deployment: %w", err))
+		return
+	}
+
+	c.JSON(200, d)
+}
+
+// DeleteDeployment godoc
+//
+//	@Summary		Delete deployment
+//	@Description	Delete a deployment by ID
+//	@Tags			deployment
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			id	path	integer	True	"ID of the deployment"
+//	@Success		204
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Router			/deployments/{id} [delete]
+func (u *Controller) DeleteDeployment(c *gin.Context) {
+	id, err := deploymentID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	deleted, err := u.q.DeleteDeployment(c.Request.Context(), id)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to delete deployment: %w", err))
+		return
+	}
+	if deleted == 0 {
+		c.Error(utils.NewHttpError(404, "deployment not found", pgx.ErrNoRows))
+		return
+	}
+
+	c.Status(204)
+}
+
+// validate checks what binding cannot: the method, that the deployment ends
+// after it starts and that the site exists.
+func (u *Controller) validate(ctx context.Context, input DeploymentInput) error {
+	if !input.Method.Valid() {
+		return utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown observation method %q", input.Method))
+	}
+	if !localTime(input.EndTime).After(localTime(input.StartTime)) {
+		return utils.NewHttpError(400, "validation failed", errors.New("endTime is not after startTime"))
+	}
+
+	_, err := u.q.GetSite(ctx, input.SiteID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewHttpError(400, "validation failed", fmt.Errorf("site %d not found", input.SiteID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get site: %w", err)
+	}
+	return nil
+}
+
+func deploymentID(c *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, utils.NewHttpError(400, "Invalid id", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,34 @@
+package deployment
+
+import (
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/config"
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/models"
+)
+
+type ListDeploymentsRequest struct {
+	// From and To keep the deployments overlapping the period
+	models.TimePeriodRequest
+	SiteCode *string               `form:"siteCode"`
+	Method   *db.ObservationMethod `form:"method"`
+}
+
+// DeploymentInput is the body of requests creating or editing a deployment.
+// The times are local time: their offset is ignored, as the times the API
+// returns carry none.
+type DeploymentInput struct {
+	SiteID    int64                `json:"siteId" binding:"required"`
+	Method    db.ObservationMethod `json:"method" binding:"required"`
+	DeviceID  *string              `json:"deviceId"`
+	StartTime time.Time            `json:"startTime" binding:"required"`
+	EndTime   time.Time            `json:"endTime" binding:"required"`
+	// Notes record the status of the device, e.g. a flat battery
+	Notes *string `json:"notes"`
+}
+
+// localTime keeps the wall clock of t, in the monitoring time zone.
+func localTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), config.TIMEZONE)
+}
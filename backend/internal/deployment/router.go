@@ -0,0 +1,15 @@
+package deployment
+
+import "github.com/gin-gonic/gin"
+
+// Register adds the read routes to r and the editing routes to editor.
+func Register(r, editor gin.IRouter, ctl *Controller) {
+	g := r.Group("/deployments")
+	g.GET("", ctl.ListDeployments)
+	g.GET("/:id", ctl.GetDeployment)
+
+	e := editor.Group("/deployments")
+	e.POST("", ctl.CreateDeployment)
+	e.PUT("/:id", ctl.UpdateDeployment)
+	e.DELETE("/:id", ctl.DeleteDeployment)
+}
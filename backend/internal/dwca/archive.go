@@ -0,0 +1,109 @@
+// Package dwca reads and writes Darwin Core Archives, the format the Atlas of
+// Living Australia and GBIF expect occurrence data in.
+package dwca
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// Metadata describes the dataset in eml.xml.
+type Metadata struct {
+	Title       string
+	Creator     string
+	Description string
+	Rights      string
+	PubDate     time.Time
+}
+
+// DefaultMetadata describes the Nillumbik biodiversity monitoring dataset.
+func DefaultMetadata() Metadata {
+	return Metadata{
+		Title:       "Nillumbik Shire biodiversity monitoring",
+		Creator:     "Nillumbik Shire Council",
+		Description: "Bird, mammal and reptile detections from audio recorders, camera traps and field observations at monitoring sites across Nillumbik Shire, Victoria.",
+		Rights:      "This work is licensed under a Creative Commons Attribution (CC-BY 4.0) License.",
+		PubDate:     time.Now(),
+	}
+}
+
+// Export writes the observations matching params as a Darwin Core Archive.
+func Export(ctx context.Context, q db.Querier, params db.ListObservationDetailsParams, w io.Writer) error {
+	observations, err := q.ListObservationDetails(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to list observations: %w", err)
+	}
+	return Write(w, observations, DefaultMetadata())
+}
+
+// Write writes a Darwin Core Archive zip holding occurrence.txt, meta.xml
+// and eml.xml.
+func Write(w io.Writer, observations []db.ObservationsWithDetail, metadata Metadata) error {
+	archive := zip.NewWriter(w)
+
+	f, err := archive.Create("occurrence.txt")
+	if err != nil {
+		return err
+	}
+	if err := writeOccurrences(f, observations); err != nil {
+		return fmt.Errorf("failed to write occurrence.txt: %w", err)
+	}
+
+	f, err = archive.Create("meta.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeXML(f, newMeta()); err != nil {
+		return fmt.Errorf("failed to write meta.xml: %w", err)
+	}
+
+	f, err = archive.Create("eml.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeXML(f, newEML(metadata)); err != nil {
+		return fmt.Errorf("failed to write eml.xml: %w", err)
+	}
+
+	return archive.Close()
+}
+
+func writeOccurrences(w io.Writer, observations []db.ObservationsWithDetail) error {
+	fields := make([]string, len(occurrenceColumns))
+	for i, col := range occurrenceColumns {
+		fields[i] = col.term
+	}
+	if _, err := io.WriteString(w, strings.Join(fields, "\t")+"\n"); err != nil {
+		return err
+	}
+
+	for _, o := range observations {
+		for i, col := range occurrenceColumns {
+			fields[i] = cleanField(col.value(o))
+		}
+		if _, err := io.WriteString(w, strings.Join(fields, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,53 @@
+package dwca
+
+import (
+	"encoding/xml"
+)
+
+type eml struct {
+	XMLName        xml.Name   `xml:"eml:eml"`
+	XmlnsEML       string     `xml:"xmlns:eml,attr"`
+	XmlnsXSI       string     `xml:"xmlns:xsi,attr"`
+	SchemaLocation string     `xml:"xsi:schemaLocation,attr"`
+	PackageID      string     `xml:"packageId,attr"`
+	System         string     `xml:"system,attr"`
+	Dataset        emlDataset `xml:"dataset"`
+}
+
+type emlDataset struct {
+	Title              string   `xml:"title"`
+	Creator            emlParty `xml:"creator"`
+	MetadataProvider   emlParty `xml:"metadataProvider"`
+	PubDate            string   `xml:"pubDate"`
+	Language           string   `xml:"language"`
+	Abstract           string   `xml:"abstract>para"`
+	IntellectualRights string   `xml:"intellectualRights>para"`
+	Coverage           string   `xml:"coverage>geographicCoverage>geographicDescription"`
+	Contact            emlParty `xml:"contact"`
+}
+
+type emlParty struct {
+	OrganizationName string `xml:"organizationName"`
+}
+
+func newEML(m Metadata) eml {
+	party := emlParty{OrganizationName: m.Creator}
+	return eml{
+		XmlnsEML:       "eml://ecoinformatics.org/eml-2.1.1",
+		XmlnsXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "eml://ecoinformatics.org/eml-2.1.1 http://rs.gbif.org/schema/eml-gbif-profile/1.1/eml.xsd",
+		PackageID:      "nillumbik-biodiversity-monitoring/" + m.PubDate.Format("20060102"),
+		System:         "nillumbik",
+		Dataset: emlDataset{
+			Title:              m.Title,
+			Creator:            party,
+			MetadataProvider:   party,
+			PubDate:            m.PubDate.Format("2006-01-02"),
+			Language:           "en",
+			Abstract:           m.Description,
+			IntellectualRights: m.Rights,
+			Coverage:           "Nillumbik Shire, Victoria, Australia",
+			Contact:            party,
+		},
+	}
+}
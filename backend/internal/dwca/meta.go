@@ -0,0 +1,68 @@
+package dwca
+
+import (
+	"encoding/xml"
+)
+
+// Meta is the meta.xml descriptor of an archive.
+type Meta struct {
+	XMLName  xml.Name `xml:"archive"`
+	Xmlns    string   `xml:"xmlns,attr,omitempty"`
+	Metadata string   `xml:"metadata,attr,omitempty"`
+	Core     File     `xml:"core"`
+	// Extensions are data files whose rows refer to a core row, e.g.
+	// multimedia or measurements.
+	Extensions []File `xml:"extension"`
+}
+
+// File describes a data file of the archive.
+type File struct {
+	Encoding           string `xml:"encoding,attr"`
+	FieldsTerminatedBy string `xml:"fieldsTerminatedBy,attr"`
+	LinesTerminatedBy  string `xml:"linesTerminatedBy,attr"`
+	// FieldsEnclosedBy is nil when meta.xml leaves it out, which means
+	// fields may be quoted with '"', and "" when fields are never quoted.
+	FieldsEnclosedBy  *string `xml:"fieldsEnclosedBy,attr"`
+	IgnoreHeaderLines int     `xml:"ignoreHeaderLines,attr"`
+	RowType           string  `xml:"rowType,attr"`
+	Location          string  `xml:"files>location"`
+	ID                *Index  `xml:"id"`
+	CoreID            *Index  `xml:"coreid"`
+	Fields            []Field `xml:"field"`
+}
+
+// Index points at a column of a data file.
+type Index struct {
+	Index int `xml:"index,attr"`
+}
+
+// Field maps a column, or a default value for every row, to a term.
+type Field struct {
+	Index   *int   `xml:"index,attr"`
+	Term    string `xml:"term,attr"`
+	Default string `xml:"default,attr,omitempty"`
+}
+
+func newMeta() Meta {
+	// Our fields are never quoted, tabs and line breaks are cleaned out
+	noQuotes := ""
+	fields := make([]Field, len(occurrenceColumns))
+	for i, col := range occurrenceColumns {
+		fields[i] = Field{Index: &i, Term: dwcNamespace + col.term}
+	}
+	return Meta{
+		Xmlns:    "http://rs.tdwg.org/dwc/text/",
+		Metadata: "eml.xml",
+		Core: File{
+			Encoding:           "UTF-8",
+			FieldsTerminatedBy: `\t`,
+			LinesTerminatedBy:  `\n`,
+			FieldsEnclosedBy:   &noQuotes,
+			IgnoreHeaderLines:  1,
+			RowType:            occurrence,
+			Location:           "occurrence.txt",
+			ID:                 &Index{Index: 0},
+			Fields:             fields,
+		},
+	}
+}
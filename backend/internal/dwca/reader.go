@@ -0,0 +1,197 @@
+package dwca
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Archive is an open Darwin Core Archive.
+type Archive struct {
+	Meta Meta
+	zip  *zip.ReadCloser
+}
+
+// Open opens the archive zip and parses its meta.xml.
+func Open(filename string) (*Archive, error) {
+	z, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	a := &Archive{zip: z}
+
+	f, err := z.Open("meta.xml")
+	if err != nil {
+		z.Close()
+		return nil, fmt.Errorf("failed to open meta.xml: %w", err)
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(f).Decode(&a.Meta); err != nil {
+		z.Close()
+		return nil, fmt.Errorf("failed to parse meta.xml: %w", err)
+	}
+	return a, nil
+}
+
+func (a *Archive) Close() error {
+	return a.zip.Close()
+}
+
+// Core opens the core data file of the archive.
+func (a *Archive) Core() (*Reader, error) {
+	return a.open(a.Meta.Core)
+}
+
+func (a *Archive) open(file File) (*Reader, error) {
+	if file.Encoding != "" && !strings.EqualFold(file.Encoding, "UTF-8") {
+		return nil, fmt.Errorf("%s: unsupported encoding %s", file.Location, file.Encoding)
+	}
+	f, err := a.zip.Open(file.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file.Location, err)
+	}
+
+	r := &Reader{
+		File:   file,
+		fields: make(map[string]Field, len(file.Fields)),
+		f:      f,
+	}
+	for _, field := range file.Fields {
+		r.fields[Term(field.Term)] = field
+	}
+
+	sep := unescape(file.FieldsTerminatedBy)
+	if sep == "" {
+		sep = ","
+	}
+	// The Darwin Core text guide defaults to quoting with '"'
+	quote := `"`
+	if file.FieldsEnclosedBy != nil {
+		quote = unescape(*file.FieldsEnclosedBy)
+	}
+	if quote == "" {
+		r.rows = &splitReader{r: bufio.NewReader(f), sep: sep}
+	} else {
+		c := csv.NewReader(f)
+		c.Comma = []rune(sep)[0]
+		c.FieldsPerRecord = -1
+		c.LazyQuotes = true
+		r.rows = c
+	}
+	return r, nil
+}
+
+// Reader reads the rows of a data file.
+type Reader struct {
+	File   File
+	fields map[string]Field
+	f      io.ReadCloser
+	rows   interface{ Read() ([]string, error) }
+	line   int
+}
+
+// Terms lists the short names of the terms the file has a column or a
+// default value for.
+func (r *Reader) Terms() []string {
+	terms := make([]string, len(r.File.Fields))
+	for i, field := range r.File.Fields {
+		terms[i] = Term(field.Term)
+	}
+	return terms
+}
+
+// Header names the columns of the file by their term, so that rows can be
+// written back out as CSV.
+func (r *Reader) Header() []string {
+	var header []string
+	for _, field := range r.File.Fields {
+		if field.Index == nil {
+			continue
+		}
+		for len(header) <= *field.Index {
+			header = append(header, "")
+		}
+		header[*field.Index] = Term(field.Term)
+	}
+	return header
+}
+
+// Read returns the next row, skipping header lines, or io.EOF at the end of
+// the file.
+func (r *Reader) Read() (Row, error) {
+	for {
+		values, err := r.rows.Read()
+		if err != nil {
+			return Row{}, err
+		}
+		r.line++
+		if r.line <= r.File.IgnoreHeaderLines {
+			continue
+		}
+		return Row{Values: values, Line: r.line, fields: r.fields}, nil
+	}
+}
+
+func (r *Reader) Close() error {
+	return r.f.Close()
+}
+
+// Row is a row of a data file.
+type Row struct {
+	Values []string
+	// Line is the 1-based line number of the row, counting header lines.
+	Line   int
+	fields map[string]Field
+}
+
+// Get returns the value of the term, given by its short name (e.g.
+// "scientificName"). Empty values fall back to the default of the field.
+func (row Row) Get(term string) string {
+	field, ok := row.fields[term]
+	if !ok {
+		return ""
+	}
+	if field.Index != nil && *field.Index < len(row.Values) {
+		if v := strings.TrimSpace(row.Values[*field.Index]); v != "" {
+			return v
+		}
+	}
+	return field.Default
+}
+
+// Term returns the short name of a term URI, e.g. "eventDate" for
+// http://rs.tdwg.org/dwc/terms/eventDate.
+func Term(uri string) string {
+	return uri[strings.LastIndexAny(uri, "/#")+1:]
+}
+
+// unescape turns the escaped delimiters of meta.xml, such as `\t`, into the
+// characters they stand for.
+var unescape = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\r`, "\r").Replace
+
+// splitReader reads lines of delimited values that are never quoted.
+type splitReader struct {
+	r   *bufio.Reader
+	sep string
+}
+
+func (s *splitReader) Read() ([]string, error) {
+	for {
+		line, err := s.r.ReadString('\n')
+		if errors.Is(err, io.EOF) && line != "" {
+			err = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Skip blank lines, as encoding/csv does
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			return strings.Split(line, s.sep), nil
+		}
+	}
+}
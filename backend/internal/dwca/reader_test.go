@@ -0,0 +1,252 @@
+package dwca
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/config"
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// writeArchive writes a zip of the files into a temporary directory and
+// returns its path.
+func writeArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "dwca.zip")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	archive := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// readAll opens the archive and reads every row of its core file.
+func readAll(t *testing.T, filename string) []Row {
+	t.Helper()
+	archive, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	core, err := archive.Core()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer core.Close()
+	var rows []Row
+	for {
+		row, err := core.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	narrative := "Calling \"wark\"\tfrom the gully,\nthen flew off"
+	siteName := "Smiths Gully"
+	file := "IMG_0042.JPG"
+	confidence := float32(0.9)
+	observations := []db.ObservationsWithDetail{
+		{
+			ID:             1,
+			Timestamp:      time.Date(2024, 10, 5, 6, 30, 0, 0, time.UTC),
+			Method:         db.ObservationMethodCamera,
+			Narrative:      &narrative,
+			Confidence:     &confidence,
+			File:           &file,
+			Native:         true,
+			Taxa:           db.TaxaBird,
+			ScientificName: "Menura novaehollandiae",
+			CommonName:     "Superb Lyrebird",
+			Block:          2,
+			SiteCode:       "SG01",
+			SiteName:       &siteName,
+			Tenure:         db.TenureTypePublic,
+			Forest:         db.ForestTypeWet,
+		},
+		{
+			ID:             2,
+			Timestamp:      time.Date(2024, 1, 20, 21, 0, 0, 0, time.UTC),
+			Method:         db.ObservationMethodObserved,
+			Taxa:           db.TaxaMammal,
+			ScientificName: "Vulpes vulpes",
+			CommonName:     "Red Fox",
+			Block:          1,
+			SiteCode:       "KW02",
+			Tenure:         db.TenureTypePrivate,
+			Forest:         db.ForestTypeDry,
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "dwca.zip")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(f, observations, DefaultMetadata()); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := readAll(t, filename)
+	if len(rows) != len(observations) {
+		t.Fatalf("read %d rows, want %d", len(rows), len(observations))
+	}
+	tests := []struct {
+		row  int
+		term string
+		want string
+	}{
+		{0, "occurrenceID", "nillumbik:observation:1"},
+		{0, "basisOfRecord", "MachineObservation"},
+		{0, "eventDate", time.Date(2024, 10, 5, 6, 30, 0, 0, config.TIMEZONE).Format(time.RFC3339)},
+		{0, "samplingProtocol", "camera"},
+		{0, "scientificName", "Menura novaehollandiae"},
+		{0, "class", "Aves"},
+		{0, "establishmentMeans", "native"},
+		{0, "locationID", "SG01"},
+		{0, "locality", "Smiths Gully"},
+		// Tabs and line breaks are cleaned out, quotes kept as they are
+		{0, "occurrenceRemarks", "Calling \"wark\" from the gully, then flew off"},
+		{0, "associatedMedia", "IMG_0042.JPG"},
+		{0, "dynamicProperties", `{"block":2,"confidence":0.9,"forest":"wet","tenure":"public"}`},
+		{1, "basisOfRecord", "HumanObservation"},
+		{1, "class", "Mammalia"},
+		{1, "establishmentMeans", "introduced"},
+		{1, "locality", ""},
+		{1, "occurrenceRemarks", ""},
+		{1, "unknownTerm", ""},
+	}
+	for _, tt := range tests {
+		if got := rows[tt.row].Get(tt.term); got != tt.want {
+			t.Errorf("row %d %s = %q, want %q", tt.row, tt.term, got, tt.want)
+		}
+	}
+	if rows[0].Line != 2 {
+		t.Errorf("first row is on line %d, want 2 after the header", rows[0].Line)
+	}
+}
+
+func TestOpenFieldsEnclosedBy(t *testing.T) {
+	const fields = `<id index="0"/>
+    <field index="0" term="http://rs.tdwg.org/dwc/terms/occurrenceID"/>
+    <field index="1" term="http://rs.tdwg.org/dwc/terms/occurrenceRemarks"/>
+    <field term="http://rs.tdwg.org/dwc/terms/countryCode" default="AU"/>
+  </core>
+</archive>`
+	meta := func(attrs string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<archive xmlns="http://rs.tdwg.org/dwc/text/">
+  <core encoding="UTF-8" rowType="http://rs.tdwg.org/dwc/terms/Occurrence" ` + attrs + `>
+    <files><location>occurrence.txt</location></files>
+    ` + fields
+	}
+
+	tests := []struct {
+		name    string
+		attrs   string
+		data    string
+		remarks []string
+	}{
+		{
+			"omitted means quoted with double quotes",
+			`fieldsTerminatedBy="," ignoreHeaderLines="1"`,
+			"id,remarks\n1,\"in the gully, calling\"\n2,plain\n",
+			[]string{"in the gully, calling", "plain"},
+		},
+		{
+			"double quotes",
+			`fieldsTerminatedBy="," fieldsEnclosedBy="&quot;" ignoreHeaderLines="1"`,
+			"id,remarks\n1,\"in the gully, calling\"\n",
+			[]string{"in the gully, calling"},
+		},
+		{
+			"empty means never quoted",
+			`fieldsTerminatedBy="\t" fieldsEnclosedBy="" ignoreHeaderLines="1"`,
+			"id\tremarks\n1\t\"wark\" twice\n\n2\tsaid \"hello\n",
+			[]string{`"wark" twice`, `said "hello`},
+		},
+		{
+			"tabs without a header",
+			`fieldsTerminatedBy="\t" fieldsEnclosedBy=""`,
+			"1\tfirst\r\n2\tsecond",
+			[]string{"first", "second"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := readAll(t, writeArchive(t, map[string]string{
+				"meta.xml":       meta(tt.attrs),
+				"occurrence.txt": tt.data,
+			}))
+			var remarks []string
+			for _, row := range rows {
+				remarks = append(remarks, row.Get("occurrenceRemarks"))
+				if got := row.Get("countryCode"); got != "AU" {
+					t.Errorf("countryCode = %q, want the default AU", got)
+				}
+			}
+			if !slices.Equal(remarks, tt.remarks) {
+				t.Errorf("remarks = %q, want %q", remarks, tt.remarks)
+			}
+		})
+	}
+}
+
+func TestOpenUnsupportedEncoding(t *testing.T) {
+	filename := writeArchive(t, map[string]string{
+		"meta.xml":       `<archive><core encoding="ISO-8859-1"><files><location>occurrence.txt</location></files></core></archive>`,
+		"occurrence.txt": "",
+	})
+	archive, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	if _, err := archive.Core(); err == nil {
+		t.Error("opened a core file that is not UTF-8")
+	}
+}
+
+func TestTerm(t *testing.T) {
+	tests := []struct {
+		uri, want string
+	}{
+		{"http://rs.tdwg.org/dwc/terms/eventDate", "eventDate"},
+		{"http://purl.org/dc/terms/modified", "modified"},
+		{"http://example.org/vocab#colour", "colour"},
+		{"eventDate", "eventDate"},
+	}
+	for _, tt := range tests {
+		if got := Term(tt.uri); got != tt.want {
+			t.Errorf("Term(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package dwca
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/config"
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+const (
+	dwcNamespace = "http://rs.tdwg.org/dwc/terms/"
+	occurrence   = dwcNamespace + "Occurrence"
+)
+
+// OccurrenceRowType is the row type of occurrence data files.
+const OccurrenceRowType = occurrence
+
+// column is a Darwin Core term of occurrence.txt and how to fill it from an
+// observation.
+type column struct {
+	term  string
+	value func(o db.ObservationsWithDetail) string
+}
+
+// occurrenceColumns lists the columns of occurrence.txt, occurrenceID first
+// as the core id.
+var occurrenceColumns = []column{
+	{"occurrenceID", func(o db.ObservationsWithDetail) string { return OccurrenceID(o.ID) }},
+	{"basisOfRecord", func(o db.ObservationsWithDetail) string { return basisOfRecord(o.Method) }},
+	{"occurrenceStatus", constant("present")},
+	{"eventDate", func(o db.ObservationsWithDetail) string { return eventDate(o.Timestamp) }},
+	{"samplingProtocol", func(o db.ObservationsWithDetail) string { return string(o.Method) }},
+	{"scientificName", func(o db.ObservationsWithDetail) string { return o.ScientificName }},
+	{"vernacularName", func(o db.ObservationsWithDetail) string { return o.CommonName }},
+	{"kingdom", constant("Animalia")},
+	{"phylum", constant("Chordata")},
+	{"class", func(o db.ObservationsWithDetail) string { return taxaClasses[o.Taxa] }},
+	{"establishmentMeans", func(o db.ObservationsWithDetail) string { return establishmentMeans(o.Native) }},
+	{"locationID", func(o db.ObservationsWithDetail) string { return o.SiteCode }},
+	{"locality", func(o db.ObservationsWithDetail) string { return deref(o.SiteName) }},
+	{"county", constant("Nillumbik Shire")},
+	{"stateProvince", constant("Victoria")},
+	{"countryCode", constant("AU")},
+	{"occurrenceRemarks", func(o db.ObservationsWithDetail) string { return deref(o.Narrative) }},
+	{"associatedMedia", func(o db.ObservationsWithDetail) string { return deref(o.File) }},
+	{"dynamicProperties", dynamicProperties},
+}
+
+// taxaClasses maps taxa to their Darwin Core class.
+var taxaClasses = map[db.Taxa]string{
+	db.TaxaBird:    "Aves",
+	db.TaxaMammal:  "Mammalia",
+	db.TaxaReptile: "Reptilia",
+}
+
+// ClassTaxa returns the taxa of a Darwin Core class, if it is one of the
+// taxa we record.
+func ClassTaxa(class string) (db.Taxa, bool) {
+	for taxa, c := range taxaClasses {
+		if strings.EqualFold(c, class) {
+			return taxa, true
+		}
+	}
+	return "", false
+}
+
+// OccurrenceID is the stable Darwin Core occurrenceID of an observation.
+func OccurrenceID(id int64) string {
+	return "nillumbik:observation:" + strconv.FormatInt(id, 10)
+}
+
+func constant(value string) func(db.ObservationsWithDetail) string {
+	return func(db.ObservationsWithDetail) string { return value }
+}
+
+func basisOfRecord(method db.ObservationMethod) string {
+	if method == db.ObservationMethodObserved {
+		return "HumanObservation"
+	}
+	return "MachineObservation"
+}
+
+func establishmentMeans(native bool) string {
+	if native {
+		return "native"
+	}
+	return "introduced"
+}
+
+// eventDate formats a timestamp, stored as local time without a zone, in
+// ISO 8601 with the local offset.
+func eventDate(t time.Time) string {
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, config.TIMEZONE)
+	return local.Format(time.RFC3339)
+}
+
+// dynamicProperties holds the values without a Darwin Core term as JSON.
+func dynamicProperties(o db.ObservationsWithDetail) string {
+	props := map[string]any{
+		"block":  o.Block,
+		"tenure": o.Tenure,
+		"forest": o.Forest,
+	}
+	if o.Confidence != nil {
+		props["confidence"] = *o.Confidence
+	}
+	if o.Temperature != nil {
+		props["temperature"] = *o.Temperature
+	}
+	if o.AppearanceStart != nil {
+		props["appearanceStart"] = *o.AppearanceStart
+	}
+	if o.AppearanceEnd != nil {
+		props["appearanceEnd"] = *o.AppearanceEnd
+	}
+	b, err := json.Marshal(props)
+	if err != nil {
+		panic(fmt.Sprintf("marshal dynamic properties: %v", err))
+	}
+	return string(b)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// cleanField keeps a value on one line of the tab separated file.
+var cleanField = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ").Replace
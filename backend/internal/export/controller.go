@@ -0,0 +1,86 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/dwca"
+	"github.com/biomonash/nillumbik/internal/stats"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	q db.Querier
+}
+
+func NewController(queries db.Querier) *Controller {
+	return &Controller{
+		q: queries,
+	}
+}
+
+type DwCARequest struct {
+	stats.ObservationStatsInput
+}
+
+// DwCAParams selects the observations of a Darwin Core Archive export with
+// the filters of the stats endpoints. The importer's export-dwca command
+// takes the same filters.
+func DwCAParams(input stats.ObservationStatsInput) db.ListObservationDetailsParams {
+	from, to, taxa, commonName := stats.ParseObservationStatsInput(input)
+	return db.ListObservationDetailsParams{
+		From:         from,
+		To:           to,
+		Block:        input.Block,
+		Tenure:       input.NullTenure(),
+		Forest:       input.NullForest(),
+		SiteCode:     input.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: input.Independence,
+	}
+}
+
+// DwCA godoc
+//
+//	@Summary		Darwin Core Archive export
+//	@Description	Export observations as a Darwin Core Archive (occurrence.txt, meta.xml and eml.xml) for the Atlas of Living Australia and GBIF
+//	@Tags			export
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Security		APIKeyAuth
+//	@Produce		application/zip
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Only observations starting an independent event, after this many minutes without one of the species at the site by the same method"	minimum(1)	maximum(10080)
+//	@Success		200				{file}		file
+//	@Failure		400				{object}	utils.HttpError
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
+//	@Router			/export/dwca [get]
+func (u *Controller) DwCA(c *gin.Context) {
+	var req DwCARequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	// Build the archive first so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	if err := dwca.Export(c.Request.Context(), u.q, DwCAParams(req.ObservationStatsInput), &buf); err != nil {
+		c.Error(fmt.Errorf("failed to export Darwin Core Archive: %w", err))
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="nillumbik-dwca.zip"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/occupancy"
+	"github.com/biomonash/nillumbik/internal/stats"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type OccupancyRequest struct {
+	stats.ObservationStatsInput
+	// OccasionDays is the length of each survey occasion
+	OccasionDays int32                 `form:"occasionDays" binding:"required,min=1,max=366"`
+	Method       *db.ObservationMethod `form:"method"`
+	// SpeciesID picks the species, otherwise found by its common name
+	SpeciesID *int64 `form:"speciesId"`
+	// Format is csv for a zip of detections.csv and covariates.csv, or json
+	Format string `form:"format" binding:"omitempty,oneof=csv json"`
+}
+
+// Occupancy godoc
+//
+//	@Summary		Occupancy detection history export
+//	@Description	Export the site by occasion detection history of a species for occupancy models, such as those of the R package unmarked. The species is given by speciesId, or by commonName when no other species shares it. Occasions of occasionDays run from the start of from to the end of to. Each site matching the filters gets 1 when the species was detected in an occasion, 0 when the site was surveyed without detecting it and NA when it was not surveyed, that is when none of its deployments overlap the occasion and it has no observation of any species in it. The site covariates (block, tenure and forest) come as a separate table. The csv format is a zip of detections.csv and covariates.csv.
+//	@Tags			export
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Security		APIKeyAuth
+//	@Produce		application/zip
+//	@Produce		json
+//	@Param			from			query		string	True	"Start of the first occasion"	format(date)
+//	@Param			to				query		string	True	"Last day of the period"		format(date)
+//	@Param			speciesId		query		integer	False	"ID of the species"
+//	@Param			commonName		query		string	False	"Common name of the species, when speciesId is missing"
+//	@Param			occasionDays	query		integer	True	"Length of each occasion in days"							minimum(1)	maximum(366)
+//	@Param			method			query		string	False	"Only count the surveys and observations of this method"	Enums(audio, camera, observed)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Only count detections of the species if it is of this taxa"
+//	@Param			independence	query		integer	False	"Only count detections starting an independent event, after this many minutes without one of the species at the site by the same method"	minimum(1)			maximum(10080)
+//	@Param			format			query		string	False	"Format of the export"																														Enums(csv, json)	default(csv)
+//	@Success		200				{object}	occupancy.History
+//	@Failure		400				{object}	utils.HttpError
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
+//	@Router			/export/occupancy [get]
+func (u *Controller) Occupancy(c *gin.Context) {
+	var req OccupancyRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+	if req.From == "" || req.To == "" || req.SpeciesID == nil && (req.CommonName == nil || strings.TrimSpace(*req.CommonName) == "") {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", errors.New("from, to and speciesId or commonName are required")))
+		return
+	}
+	if req.Method != nil && !req.Method.Valid() {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", fmt.Errorf("unknown observation method %q", *req.Method)))
+		return
+	}
+	ctx := c.Request.Context()
+
+	from, to, taxa, commonName := stats.ParseObservationStatsInput(req.ObservationStatsInput)
+	species, err := u.occupancySpecies(ctx, req.SpeciesID, commonName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	params := db.ListOccupancyOccasionsParams{
+		StartTime:    from.Time,
+		EndTime:      endOfDay(to.Time),
+		OccasionDays: req.OccasionDays,
+		Method:       db.NullObservationMethod{Valid: req.Method != nil},
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		Independence: req.Independence,
+	}
+	if req.Method != nil {
+		params.Method.ObservationMethod = *req.Method
+	}
+
+	history, err := occupancy.Build(ctx, u.q, species, params)
+	if errors.Is(err, occupancy.ErrTooManyOccasions) || errors.Is(err, occupancy.ErrNoOccasions) {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to build detection history: %w", err))
+		return
+	}
+
+	if req.Format == "json" {
+		c.JSON(http.StatusOK, history)
+		return
+	}
+	var buf bytes.Buffer
+	if err := occupancy.Write(&buf, history); err != nil {
+		c.Error(fmt.Errorf("failed to write detection history: %w", err))
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="nillumbik-occupancy.zip"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// occupancySpecies finds the species of an occupancy history by its id or,
+// without one, by its common name, which must not be shared.
+func (u *Controller) occupancySpecies(ctx context.Context, id *int64, commonName *string) (db.Species, error) {
+	if id != nil {
+		species, err := u.q.GetSpecies(ctx, *id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Species{}, utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", fmt.Errorf("unknown species %d", *id))
+		}
+		if err != nil {
+			return db.Species{}, fmt.Errorf("failed to get species: %w", err)
+		}
+		return species, nil
+	}
+
+	matches, err := u.q.ListSpeciesByCommonName(ctx, *commonName)
+	if err != nil {
+		return db.Species{}, fmt.Errorf("failed to get species: %w", err)
+	}
+	switch len(matches) {
+	case 0:
+		return db.Species{}, utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", fmt.Errorf("unknown species %q", *commonName))
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = fmt.Sprintf("%d (%s)", m.ID, m.ScientificName)
+		}
+		return db.Species{}, utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters",
+			fmt.Errorf("%d species are called %q, pass the speciesId of one of %s", len(matches), *commonName, strings.Join(ids, ", ")))
+	}
+}
+
+// endOfDay returns the start of the day after the one t falls in, so that a
+// period includes its last day whatever the time of to.
+func endOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
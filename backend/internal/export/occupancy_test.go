@@ -0,0 +1,172 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/occupancy"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// occupancyQuerier has two species called Superb Lyrebird, a wombat and a
+// site surveyed by a camera for the first week of October 2024. It records
+// the parameters of the occasions query.
+type occupancyQuerier struct {
+	db.Querier
+	params *db.ListOccupancyOccasionsParams
+}
+
+var occupancySpecies = []db.Species{
+	{ID: 1, ScientificName: "Menura novaehollandiae", CommonName: "Superb Lyrebird", Taxa: db.TaxaBird},
+	{ID: 2, ScientificName: "Menura alberti", CommonName: "Superb Lyrebird", Taxa: db.TaxaBird},
+	{ID: 3, ScientificName: "Vombatus ursinus", CommonName: "Common Wombat", Taxa: db.TaxaMammal},
+}
+
+func (q occupancyQuerier) GetSpecies(ctx context.Context, id int64) (db.Species, error) {
+	for _, s := range occupancySpecies {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return db.Species{}, pgx.ErrNoRows
+}
+
+func (q occupancyQuerier) ListSpeciesByCommonName(ctx context.Context, commonName string) ([]db.Species, error) {
+	matches := []db.Species{}
+	for _, s := range occupancySpecies {
+		if s.CommonName == commonName {
+			matches = append(matches, s)
+		}
+	}
+	return matches, nil
+}
+
+func (q occupancyQuerier) ListOccupancySites(ctx context.Context, arg db.ListOccupancySitesParams) ([]db.Site, error) {
+	return []db.Site{{ID: 1, Code: "NIL01"}}, nil
+}
+
+func (q occupancyQuerier) ListDeployments(ctx context.Context, arg db.ListDeploymentsParams) ([]db.Deployment, error) {
+	return []db.Deployment{
+		{SiteID: 1, StartTime: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 10, 8, 0, 0, 0, 0, time.UTC)},
+	}, nil
+}
+
+func (q occupancyQuerier) ListOccupancyOccasions(ctx context.Context, arg db.ListOccupancyOccasionsParams) ([]db.ListOccupancyOccasionsRow, error) {
+	*q.params = arg
+	return []db.ListOccupancyOccasionsRow{{SiteID: 1, Occasion: 0, Detected: true}}, nil
+}
+
+func TestOccupancy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	period := "from=2024-10-01&to=2024-10-14&occasionDays=7&format=json"
+	tests := []struct {
+		name    string
+		query   string
+		want    int
+		species int64
+	}{
+		{"by id", period + "&speciesId=2", 200, 2},
+		{"by a unique common name", period + "&commonName=Common_Wombat", 200, 3},
+		{"id over common name", period + "&speciesId=1&commonName=Common+Wombat", 200, 1},
+		{"shared common name", period + "&commonName=Superb+Lyrebird", 400, 0},
+		{"unknown common name", period + "&commonName=Platypus", 400, 0},
+		{"unknown id", period + "&speciesId=9", 400, 0},
+		{"no species", period, 400, 0},
+		{"no period", "occasionDays=7&speciesId=1", 400, 0},
+		{"unknown method", period + "&speciesId=1&method=heard", 400, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params db.ListOccupancyOccasionsParams
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/export/occupancy?"+tt.query, nil)
+			NewController(occupancyQuerier{params: &params}).Occupancy(c)
+
+			code := c.Writer.Status()
+			if len(c.Errors) > 0 {
+				var httpErr utils.HttpError
+				if !errors.As(c.Errors.Last().Err, &httpErr) {
+					t.Fatal(c.Errors.Last())
+				}
+				code = httpErr.Code
+			}
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d: %v", code, tt.want, c.Errors)
+			}
+			if tt.want != 200 {
+				return
+			}
+			var history occupancy.History
+			if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+				t.Fatal(err)
+			}
+			if history.Species.ID != tt.species || params.SpeciesID != tt.species {
+				t.Errorf("species = %d and queried %d, want %d", history.Species.ID, params.SpeciesID, tt.species)
+			}
+			// The period includes the 14th, in two weekly occasions
+			if want := time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC); !params.EndTime.Equal(want) {
+				t.Errorf("end time = %s, want %s", params.EndTime, want)
+			}
+			if len(history.Occasions) != 2 {
+				t.Errorf("occasions = %+v, want two", history.Occasions)
+			}
+		})
+	}
+}
+
+func TestOccupancyFilters(t *testing.T) {
+	// The taxa and independence filters go to the query, which only applies
+	// them to the detections
+	gin.SetMode(gin.TestMode)
+	var params db.ListOccupancyOccasionsParams
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet,
+		"/export/occupancy?from=2024-10-01&to=2024-10-14&occasionDays=7&speciesId=3&taxa=mammal&independence=30&method=camera&format=json", nil)
+	NewController(occupancyQuerier{params: &params}).Occupancy(c)
+	if len(c.Errors) > 0 {
+		t.Fatal(c.Errors.Last())
+	}
+	if !params.Taxa.Valid || params.Taxa.Taxa != db.TaxaMammal {
+		t.Errorf("taxa = %+v, want mammal", params.Taxa)
+	}
+	if params.Independence == nil || *params.Independence != 30 {
+		t.Errorf("independence = %v, want 30", params.Independence)
+	}
+	if !params.Method.Valid || params.Method.ObservationMethod != db.ObservationMethodCamera {
+		t.Errorf("method = %+v, want camera", params.Method)
+	}
+}
+
+func TestEndOfDay(t *testing.T) {
+	melbourne, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{"midnight", time.Date(2024, 10, 14, 0, 0, 0, 0, time.UTC), time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"during the day", time.Date(2024, 10, 14, 17, 30, 0, 0, time.UTC), time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"last moment of the day", time.Date(2024, 10, 14, 23, 59, 59, 999, time.UTC), time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"end of the year", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Daylight saving starts: the day is 23 hours long
+		{"short day", time.Date(2024, 10, 6, 0, 0, 0, 0, melbourne), time.Date(2024, 10, 7, 0, 0, 0, 0, melbourne)},
+	}
+	for _, tt := range tests {
+		if got := endOfDay(tt.t); !got.Equal(tt.want) {
+			t.Errorf("%s: endOfDay(%s) = %s, want %s", tt.name, tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package export
+
+import "github.com/gin-gonic/gin"
+
+func Register(r gin.IRouter, ctl *Controller) {
+	g := r.Group("/export")
+	g.GET("/dwca", ctl.DwCA)
+	g.GET("/occupancy", ctl.Occupancy)
+}
@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// UndoReport counts the records removed when an import batch is undone.
+type UndoReport struct {
+	BatchID      int64 `json:"batchId"`
+	Observations int64 `json:"observations"`
+	// Restored counts the observations the batch updated that got back
+	// their previous values.
+	Restored    int64 `json:"restored"`
+	Sites       int64 `json:"sites"`
+	Species     int64 `json:"species"`
+	Deployments int64 `json:"deployments"`
+}
+
+// ErrBatchNotUndoable is returned when undoing a batch that did not
+// complete, or was already undone.
+var ErrBatchNotUndoable = errors.New("only completed import batches can be undone")
+
+// UndoImport deletes, in one transaction, every observation and deployment
+// the batch imported and the sites and species it created, and puts back the
+// values of the observations it updated. Sites and species that observations
+// or deployments from other batches still refer to are kept, as are the
+// values of observations a later batch updated again. The batch itself is
+// kept and marked as reverted.
+func UndoImport(ctx context.Context, conn Conn, batchID int64) (*UndoReport, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	q := db.New(tx)
+	batch, err := q.GetImportBatch(ctx, batchID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("import batch %d not found", batchID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import batch: %w", err)
+	}
+	if batch.Status != db.ImportStatusCompleted {
+		return nil, fmt.Errorf("import batch %d is %s: %w", batchID, batch.Status, ErrBatchNotUndoable)
+	}
+
+	report := &UndoReport{BatchID: batchID}
+	report.Restored, err = q.RestoreObservationsByImportBatch(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore observations: %w", err)
+	}
+	if err := q.PassOnObservationRevisionsByImportBatch(ctx, batchID); err != nil {
+		return nil, fmt.Errorf("failed to update later revisions: %w", err)
+	}
+	if err := q.DeleteObservationRevisionsByImportBatch(ctx, batchID); err != nil {
+		return nil, fmt.Errorf("failed to delete revisions: %w", err)
+	}
+	report.Observations, err = q.DeleteObservationsByImportBatch(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete observations: %w", err)
+	}
+	report.Deployments, err = q.DeleteDeploymentsByImportBatch(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete deployments: %w", err)
+	}
+	report.Species, err = q.DeleteSpeciesByImportBatch(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete species: %w", err)
+	}
+	report.Sites, err = q.DeleteSitesByImportBatch(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete sites: %w", err)
+	}
+	err = q.SetImportBatchStatus(ctx, db.SetImportBatchStatusParams{
+		ID:     batchID,
+		Status: db.ImportStatusReverted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update import batch: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit undo: %w", err)
+	}
+	return report, nil
+}
+
+// importBatch records the import of files, under the name of the file or
+// directory they were found in, as a new import batch and runs it inside a
+// single transaction, committed along with the batch once run returns
+// without error. When it fails the batch is marked as failed instead.
+func importBatch(ctx context.Context, conn Conn, name string, files []string, run func(ctx context.Context, q *db.Queries, report *Report) error) (*Report, error) {
+	checksum, err := fileChecksum(files...)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := db.New(conn)
+	batch, err := batches.CreateImportBatch(ctx, db.CreateImportBatchParams{
+		FileName: filepath.Base(name),
+		Checksum: checksum,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import batch: %w", err)
+	}
+
+	report := newReport(name, false)
+	report.BatchID = batch.ID
+	if err := runBatch(ctx, conn, report, run); err != nil {
+		// The import was rolled back, only record that it failed. ctx may
+		// have been cancelled already.
+		_, ferr := batches.FinishImportBatch(context.Background(), db.FinishImportBatchParams{
+			ID:       batch.ID,
+			Status:   db.ImportStatusFailed,
+			RowCount: int32(report.TotalRows),
+		})
+		return report, errors.Join(err, ferr)
+	}
+	return report, nil
+}
+
+func runBatch(ctx context.Context, conn Conn, report *Report, run func(ctx context.Context, q *db.Queries, report *Report) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// No-op once committed. Uses a fresh context so that an interrupted
+	// import is still rolled back.
+	defer tx.Rollback(context.Background())
+
+	q := db.New(tx)
+	if err := run(ctx, q, report); err != nil {
+		return err
+	}
+
+	_, err = q.FinishImportBatch(ctx, db.FinishImportBatchParams{
+		ID:       report.BatchID,
+		Status:   db.ImportStatusCompleted,
+		RowCount: int32(report.TotalRows),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to finish import batch: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+	report.Committed = true
+	return nil
+}
+
+// fileChecksum hashes the content of the files, in order.
+func fileChecksum(filenames ...string) (string, error) {
+	h := sha256.New()
+	for _, filename := range filenames {
+		if err := hashFile(h, filename); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", filename, err)
+	}
+	return nil
+}
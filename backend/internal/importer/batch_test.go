@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+func TestUndoImport(t *testing.T) {
+	ctx := context.Background()
+	conn := newMemDB()
+	first, err := ImportCSV(ctx, conn, writeTestCSV(t,
+		testCSVHeader,
+		"SG01,5-Oct-24,6:30 AM,Camera,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird",
+		"SG02,5-Oct-24,9:00 PM,Audio,Vombatus ursinus,Common Wombat,Dry,N,Native,Private,N,2,Mammal",
+	), Options{OnConflict: ConflictSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Observes at a site and of a species the first batch created
+	second, err := ImportCSV(ctx, conn, writeTestCSV(t,
+		testCSVHeader,
+		"SG01,6-Oct-24,6:30 AM,Camera,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird",
+		"SG03,6-Oct-24,7:00 AM,Camera,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird",
+	), Options{OnConflict: ConflictSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	undo, err := UndoImport(ctx, conn, first.BatchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UndoReport{BatchID: first.BatchID, Observations: 2, Sites: 1, Species: 1}
+	if *undo != want {
+		t.Errorf("undo = %+v, want %+v", *undo, want)
+	}
+
+	tables := conn.tables
+	var sites []string
+	for _, s := range tables.sites {
+		sites = append(sites, s.Code)
+	}
+	// SG01 and the lyrebird are kept for the second batch
+	if len(sites) != 2 || sites[0] != "SG01" || sites[1] != "SG03" {
+		t.Errorf("sites = %v, want [SG01 SG03]", sites)
+	}
+	if len(tables.species) != 1 || tables.species[0].ScientificName != "Menura novaehollandiae" {
+		t.Errorf("species = %+v, want the lyrebird", tables.species)
+	}
+	if len(tables.observations) != 2 {
+		t.Errorf("got %d observations, want the 2 of the second batch", len(tables.observations))
+	}
+	for _, o := range tables.observations {
+		if !fromBatch(o.ImportBatchID, second.BatchID) {
+			t.Errorf("observation %d is from batch %v, want %d", o.ID, deref(o.ImportBatchID), second.BatchID)
+		}
+	}
+	if status := tables.batches[0].Status; status != db.ImportStatusReverted {
+		t.Errorf("undone batch is %s, want %s", status, db.ImportStatusReverted)
+	}
+
+	tests := []struct {
+		name    string
+		batchID int64
+		err     error
+	}{
+		{"undone batch", first.BatchID, ErrBatchNotUndoable},
+		{"unknown batch", 1000, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UndoImport(ctx, conn, tt.batchID)
+			if err == nil || tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUndoFailedImport(t *testing.T) {
+	conn := newMemDB()
+	conn.failOn = "UpsertObservations"
+	report, err := ImportCSV(context.Background(), conn, writeTestCSV(t,
+		testCSVHeader,
+		"SG01,5-Oct-24,6:30 AM,Camera,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird",
+	), Options{OnConflict: ConflictSkip})
+	if err == nil {
+		t.Fatal("import succeeded")
+	}
+	conn.failOn = ""
+	if _, err := UndoImport(context.Background(), conn, report.BatchID); !errors.Is(err, ErrBatchNotUndoable) {
+		t.Errorf("err = %v, want %v", err, ErrBatchNotUndoable)
+	}
+}
@@ -0,0 +1,277 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// birdnetColumns match the columns of BirdNET-Analyzer's selection tables
+// (Raven format, tab separated) and CSV results.
+var birdnetColumns = ColumnMapping{
+	FieldAppearanceStart: {"Begin Time (s)", "Start (s)"},
+	FieldAppearanceEnd:   {"End Time (s)", "End (s)"},
+	FieldScientificName:  {"Scientific name"},
+	FieldCommonName:      {"Common name"},
+	FieldConfidence:      {"Confidence"},
+	FieldFile:            {"Begin Path", "File"},
+	fieldFileOffset:      {"File Offset (s)"},
+}
+
+var (
+	birdnetRequired = []Field{FieldAppearanceStart, FieldAppearanceEnd, FieldConfidence}
+	birdnetOptional = []Field{FieldScientificName, FieldCommonName, FieldFile, fieldFileOffset}
+)
+
+// birdnetSuffixes are the file name endings of BirdNET-Analyzer results,
+// per recording or combined.
+var birdnetSuffixes = []string{
+	".birdnet.selection.table.txt",
+	".birdnet.results.csv",
+	"birdnet_selectiontable.txt",
+	"birdnet_combinedtable.csv",
+}
+
+// BirdNETFiles lists the BirdNET-Analyzer result files under path, or path
+// itself when it is a file.
+func BirdNETFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := strings.ToLower(d.Name())
+		for _, suffix := range birdnetSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				files = append(files, p)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no BirdNET results found in %s", path)
+	}
+	return files, nil
+}
+
+// ImportBirdNET imports the detections of the BirdNET-Analyzer results at
+// path, a results file or a directory of them, as audio observations in one
+// import batch. The site and start time of each recording come from its path
+// through opts.FilePattern. Detections scored below opts.MinConfidence, or
+// of sites and species that are not in the database, are skipped and
+// counted in the report.
+func ImportBirdNET(ctx context.Context, conn Conn, path string, opts Options) (*Report, error) {
+	if !opts.OnConflict.Valid() {
+		return nil, fmt.Errorf("unknown conflict mode: %q", opts.OnConflict)
+	}
+	files, err := BirdNETFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return importBatch(ctx, conn, path, files, func(ctx context.Context, q *db.Queries, report *Report) error {
+		observations := newObservationWriter(q, opts.OnConflict, report)
+		if err := readBirdNET(ctx, q, files, report, opts, observations.add); err != nil {
+			return err
+		}
+		return observations.flush(ctx)
+	})
+}
+
+// ValidateBirdNET checks the detections at path the same way ImportBirdNET
+// does, without writing to the database.
+func ValidateBirdNET(ctx context.Context, q db.Querier, path string, opts Options) (*Report, error) {
+	files, err := BirdNETFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(path, true)
+	if err := readBirdNET(ctx, q, files, report, opts, nil); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func readBirdNET(ctx context.Context, q db.Querier, files []string, report *Report, opts Options, add func(context.Context, db.CreateObservationsParams) error) error {
+	rows := newRowImporter(q, columns{}, report)
+	for _, filename := range files {
+		if err := rows.readBirdNETFile(ctx, filename, opts, add); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// readBirdNETFile passes the detections of a results file to add. In dry-run
+// mode invalid rows are rejected and skipped, otherwise the first one stops
+// the import.
+func (r *rowImporter) readBirdNETFile(ctx context.Context, filename string, opts Options, add func(context.Context, db.CreateObservationsParams) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open results: %w", err)
+	}
+	defer file.Close()
+
+	reader := newCSVReader(file)
+	if strings.EqualFold(filepath.Ext(filename), ".txt") {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	cols, err := birdnetColumns.resolveFields(header, birdnetRequired, birdnetOptional)
+	if err != nil {
+		return err
+	}
+	if !cols.has(FieldScientificName) && !cols.has(FieldCommonName) {
+		return errors.New("no scientific or common name column")
+	}
+	if r.report.header == nil {
+		r.report.header = header
+	}
+
+	line := 1
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read results: %w", err)
+		}
+		line++
+
+		r.report.TotalRows++
+		params, err := r.parseDetection(ctx, filename, values, cols, opts)
+		if errors.As(err, &invalidRow{}) {
+			err = invalidRow{fmt.Errorf("%s: %w", filepath.Base(filename), err)}
+		}
+		valid, err := r.report.check(line, values, err)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if !valid || add == nil {
+			continue
+		}
+		if err := add(ctx, params); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return nil
+}
+
+// parseDetection turns a BirdNET detection into an audio observation of an
+// existing site and species. The observation starts when the detection
+// does, with its appearance in seconds from the start of the recording.
+func (r *rowImporter) parseDetection(ctx context.Context, resultsFile string, values []string, cols columns, opts Options) (db.CreateObservationsParams, error) {
+	if len(values) < cols.width {
+		return db.CreateObservationsParams{}, invalidRow{fmt.Errorf("unexpected column count %d, want %d", len(values), cols.width)}
+	}
+	row := record{values: values, columns: cols}
+
+	c, err := strconv.ParseFloat(strings.TrimSpace(row.get(FieldConfidence)), 32)
+	if err != nil {
+		return db.CreateObservationsParams{}, invalidRow{fmt.Errorf("invalid confidence %q", row.get(FieldConfidence))}
+	}
+	confidence := float32(c)
+	if confidence < opts.MinConfidence {
+		return db.CreateObservationsParams{}, skippedRow{fmt.Sprintf("confidence below %g", opts.MinConfidence)}
+	}
+
+	begin, err := parseSeconds(row.get(FieldAppearanceStart))
+	if err != nil {
+		return db.CreateObservationsParams{}, invalidRow{err}
+	}
+	end, err := parseSeconds(row.get(FieldAppearanceEnd))
+	if err != nil {
+		return db.CreateObservationsParams{}, invalidRow{err}
+	}
+	// Combined tables count time across recordings, the offset is within
+	// the recording
+	if offset := row.get(fieldFileOffset); strings.TrimSpace(offset) != "" {
+		o, err := parseSeconds(offset)
+		if err != nil {
+			return db.CreateObservationsParams{}, invalidRow{err}
+		}
+		begin, end = o, o+end-begin
+	}
+
+	recording := strings.TrimSpace(row.get(FieldFile))
+	if recording == "" {
+		recording = recordingName(resultsFile)
+	}
+	siteCode, start, err := opts.filePattern().Match(recording)
+	if err != nil {
+		return db.CreateObservationsParams{}, invalidRow{err}
+	}
+
+	site, err := r.knownSite(ctx, siteCode)
+	if err != nil {
+		return db.CreateObservationsParams{}, err
+	}
+	species, err := r.knownSpecies(ctx, strings.TrimSpace(row.get(FieldScientificName)), strings.TrimSpace(row.get(FieldCommonName)))
+	if err != nil {
+		return db.CreateObservationsParams{}, err
+	}
+
+	startSec := int32(math.Floor(begin))
+	endSec := int32(math.Ceil(end))
+	return db.CreateObservationsParams{
+		SiteID:          site.ID,
+		SpeciesID:       species.ID,
+		Timestamp:       start.Add(time.Duration(startSec) * time.Second),
+		Method:          db.ObservationMethodAudio,
+		AppearanceStart: &startSec,
+		AppearanceEnd:   &endSec,
+		Confidence:      &confidence,
+		File:            &recording,
+		ImportBatchID:   r.batchID,
+	}, nil
+}
+
+func parseSeconds(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid time in seconds %q", s)
+	}
+	return v, nil
+}
+
+// recordingName guesses the recording of a results file without a file
+// column from its name, which BirdNET-Analyzer derives from the recording's.
+func recordingName(resultsFile string) string {
+	name := filepath.Base(resultsFile)
+	lower := strings.ToLower(name)
+	for _, suffix := range birdnetSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return name[:len(name)-len(suffix)]
+		}
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/config"
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// knownQuerier has site NIL01 and the lyrebird and kookaburra.
+type knownQuerier struct {
+	db.Querier
+}
+
+var knownSpeciesList = []db.Species{
+	{ID: 10, ScientificName: "Menura novaehollandiae", CommonName: "Superb Lyrebird", Taxa: db.TaxaBird},
+	{ID: 11, ScientificName: "Dacelo novaeguineae", CommonName: "Laughing Kookaburra", Taxa: db.TaxaBird},
+}
+
+func (knownQuerier) GetSiteByCode(ctx context.Context, code string) (db.Site, error) {
+	if code == "NIL01" {
+		return db.Site{ID: 1, Code: code}, nil
+	}
+	return db.Site{}, pgx.ErrNoRows
+}
+
+func (knownQuerier) GetSpeciesByScientificName(ctx context.Context, name string) (db.Species, error) {
+	for _, s := range knownSpeciesList {
+		if strings.EqualFold(s.ScientificName, name) {
+			return s, nil
+		}
+	}
+	return db.Species{}, pgx.ErrNoRows
+}
+
+func (knownQuerier) GetSpeciesByCommonName(ctx context.Context, name string) (db.Species, error) {
+	for _, s := range knownSpeciesList {
+		if strings.EqualFold(s.CommonName, name) {
+			return s, nil
+		}
+	}
+	return db.Species{}, pgx.ErrNoRows
+}
+
+func writeTestFile(t *testing.T, dir, name string, lines ...string) string {
+	t.Helper()
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestReadBirdNET(t *testing.T) {
+	dir := t.TempDir()
+	// A selection table per recording, without a file column
+	writeTestFile(t, dir, "NIL01_20240301_063000.BirdNET.selection.table.txt",
+		"Selection\tView\tChannel\tBegin Time (s)\tEnd Time (s)\tLow Freq (Hz)\tHigh Freq (Hz)\tCommon Name\tSpecies Code\tConfidence",
+		"1\tSpectrogram 1\t1\t3.0\t6.0\t150\t12000\tSuperb Lyrebird\tsuplyr1\t0.92",
+		"2\tSpectrogram 1\t1\t9.5\t12.5\t150\t12000\tLaughing Kookaburra\tlaukoo1\t0.31",
+	)
+	// A combined table counting time across recordings
+	writeTestFile(t, dir, "BirdNET_CombinedTable.csv",
+		"Start (s),End (s),Scientific name,Common name,Confidence,File,File Offset (s)",
+		"63.0,66.0,Dacelo novaeguineae,Laughing Kookaburra,0.8,rec/NIL01_20240302_180000.wav,3.4",
+		"70.0,73.0,Pycnoptilus floccosus,Pilotbird,0.9,rec/NIL01_20240302_180000.wav,10.0",
+		"80.0,83.0,Menura novaehollandiae,Superb Lyrebird,0.7,rec/NIL09_20240302_180000.wav,20.0",
+		"90.0,93.0,Menura novaehollandiae,Superb Lyrebird,high,rec/NIL01_20240302_180000.wav,30.0",
+		"95.0,98.0,Menura novaehollandiae,Superb Lyrebird,0.9,rec/lyrebird.wav,35.0",
+	)
+	writeTestFile(t, dir, "notes.txt", "not a results file")
+
+	files, err := BirdNETFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("found %d results files, want 2: %v", len(files), files)
+	}
+
+	report := newReport(dir, true)
+	var observations []db.CreateObservationsParams
+	err = readBirdNET(context.Background(), knownQuerier{}, files, report, Options{MinConfidence: 0.5}, func(ctx context.Context, o db.CreateObservationsParams) error {
+		observations = append(observations, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	march := func(day, hour, min, sec int) time.Time {
+		return time.Date(2024, 3, day, hour, min, sec, 0, config.TIMEZONE)
+	}
+	// Files are read in name order
+	want := []struct {
+		speciesID  int64
+		timestamp  time.Time
+		start, end int32
+		file       string
+	}{
+		// From the offset in the recording, rounded outwards to whole seconds
+		{11, march(2, 18, 0, 3), 3, 7, "rec/NIL01_20240302_180000.wav"},
+		// Named after the results file, which has lost the extension
+		{10, march(1, 6, 30, 3), 3, 6, "NIL01_20240301_063000"},
+	}
+	if len(observations) != len(want) {
+		t.Fatalf("got %d observations, want %d: %+v", len(observations), len(want), observations)
+	}
+	for i, w := range want {
+		o := observations[i]
+		if o.SiteID != 1 || o.SpeciesID != w.speciesID || !o.Timestamp.Equal(w.timestamp) || o.Method != db.ObservationMethodAudio {
+			t.Errorf("observation %d = species %d at site %d at %v by %s, want species %d at site 1 at %v by audio",
+				i, o.SpeciesID, o.SiteID, o.Timestamp, o.Method, w.speciesID, w.timestamp)
+		}
+		if *o.AppearanceStart != w.start || *o.AppearanceEnd != w.end || *o.File != w.file {
+			t.Errorf("observation %d appears %d-%d s in %s, want %d-%d s in %s",
+				i, *o.AppearanceStart, *o.AppearanceEnd, *o.File, w.start, w.end, w.file)
+		}
+	}
+
+	wantSkipped := map[string]int{
+		"confidence below 0.5":                  1,
+		"unknown species Pycnoptilus floccosus": 1,
+		"unknown site NIL09":                    1,
+	}
+	if len(report.Skipped) != len(wantSkipped) {
+		t.Errorf("skipped = %v, want %v", report.Skipped, wantSkipped)
+	}
+	for reason, n := range wantSkipped {
+		if report.Skipped[reason] != n {
+			t.Errorf("skipped %d rows for %q, want %d", report.Skipped[reason], reason, n)
+		}
+	}
+	if report.TotalRows != 7 || report.ValidRows != 2 || report.RejectedRows != 2 {
+		t.Errorf("rows = %d, valid %d, rejected %d, want 7, 2 and 2", report.TotalRows, report.ValidRows, report.RejectedRows)
+	}
+	for _, e := range report.Errors {
+		if !strings.HasPrefix(e.Error, "BirdNET_CombinedTable.csv: ") {
+			t.Errorf("rejected row %d: %s, want it named after its file", e.Row, e.Error)
+		}
+	}
+}
+
+func TestReadBirdNETHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		err    string
+	}{
+		{"no confidence", "Start (s),End (s),Scientific name", "missing required column for confidence"},
+		{"no species", "Start (s),End (s),Confidence,File", "no scientific or common name column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeTestFile(t, t.TempDir(), "results.BirdNET.results.csv", tt.header)
+			_, err := ValidateBirdNET(context.Background(), knownQuerier{}, filename, Options{})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRecordingName(t *testing.T) {
+	tests := []struct {
+		results string
+		want    string
+	}{
+		{"out/NIL01_20240301_063000.BirdNET.selection.table.txt", "NIL01_20240301_063000"},
+		{"NIL01_20240301_063000.BirdNET.results.csv", "NIL01_20240301_063000"},
+		{"NIL01_20240301_063000.csv", "NIL01_20240301_063000"},
+	}
+	for _, tt := range tests {
+		if got := recordingName(tt.results); got != tt.want {
+			t.Errorf("recordingName(%q) = %q, want %q", tt.results, got, tt.want)
+		}
+	}
+}
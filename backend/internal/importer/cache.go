@@ -3,6 +3,7 @@ package importer
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/biomonash/nillumbik/internal/db"
 )
@@ -11,13 +12,16 @@ type ImporterCache struct {
 	q       db.Querier
 	sites   map[string]db.Site
 	species map[string]db.Species
+	// commonNames are the species looked up by lowercase common name
+	commonNames map[string]db.Species
 }
 
 func NewCache(q db.Querier) *ImporterCache {
 	return &ImporterCache{
-		q:       q,
-		sites:   make(map[string]db.Site),
-		species: make(map[string]db.Species),
+		q:           q,
+		sites:       make(map[string]db.Site),
+		species:     make(map[string]db.Species),
+		commonNames: make(map[string]db.Species),
 	}
 }
 
@@ -53,6 +57,24 @@ func (c *ImporterCache) GetSpecies(ctx context.Context, sciName string) (db.Spec
 	return species, nil
 }
 
+// GetSpeciesByCommonName looks species up by common name, for files that do
+// not give the scientific name.
+func (c *ImporterCache) GetSpeciesByCommonName(ctx context.Context, commonName string) (db.Species, error) {
+	key := strings.ToLower(commonName)
+	species, ok := c.commonNames[key]
+	if ok {
+		return species, nil
+	}
+
+	species, err := c.q.GetSpeciesByCommonName(ctx, commonName)
+	if err != nil {
+		return db.Species{}, fmt.Errorf("failed to get species by common name: %w", err)
+	}
+	c.commonNames[key] = species
+	c.species[species.ScientificName] = species
+	return species, nil
+}
+
 func (c *ImporterCache) AddSpecies(species db.Species) {
 	c.species[species.ScientificName] = species
 }
@@ -0,0 +1,379 @@
+package importer
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// camtrapResources are the data files of a Camtrap DP package and the
+// columns we need from each.
+var camtrapResources = map[string][]string{
+	"deployments":  {"deploymentID"},
+	"media":        {"mediaID", "deploymentID", "timestamp", "filePath"},
+	"observations": {"observationID", "deploymentID", "observationType", "scientificName"},
+}
+
+// CamtrapDPFiles finds the deployments, media and observations files of the
+// Camtrap DP package at path, its directory or its datapackage.json. Paths
+// listed in datapackage.json take precedence over the default file names.
+func CamtrapDPFiles(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := path
+	if !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+
+	files := make(map[string]string, len(camtrapResources))
+	for name := range camtrapResources {
+		files[name] = filepath.Join(dir, name+".csv")
+	}
+
+	descriptor, err := os.ReadFile(filepath.Join(dir, "datapackage.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Resources []struct {
+			Name string `json:"name"`
+			Path any    `json:"path"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(descriptor, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse datapackage.json: %w", err)
+	}
+	for _, res := range pkg.Resources {
+		if _, ok := camtrapResources[res.Name]; !ok {
+			continue
+		}
+		p, ok := res.Path.(string)
+		if !ok || strings.Contains(p, "://") {
+			return nil, fmt.Errorf("resource %s must be a single local file", res.Name)
+		}
+		files[res.Name] = filepath.Join(dir, filepath.FromSlash(p))
+	}
+	return files, nil
+}
+
+// ImportCamtrapDP imports the animal observations of a Camtrap DP package as
+// camera observations in one import batch. Deployments are matched to sites
+// by locationID, locationName or deploymentID, in that order, and new sites
+// take their block, tenure and forest from opts.Site. Event observations are
+// imported as they are; media observations are grouped into events by
+// eventID or, without one, by opts.EventGap. Observations of species that
+// are not in the database are skipped. The period of each deployment is
+// recorded as a camera deployment of its site.
+func ImportCamtrapDP(ctx context.Context, conn Conn, path string, opts Options) (*Report, error) {
+	if !opts.OnConflict.Valid() {
+		return nil, fmt.Errorf("unknown conflict mode: %q", opts.OnConflict)
+	}
+	files, err := CamtrapDPFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return importBatch(ctx, conn, path, sortedValues(files), func(ctx context.Context, q *db.Queries, report *Report) error {
+		observations := newObservationWriter(q, opts.OnConflict, report)
+		deployments := newDeploymentWriter(q, opts.OnConflict, report)
+		if err := readCamtrapDP(ctx, q, files, report, opts, observations.add, deployments); err != nil {
+			return err
+		}
+		return observations.flush(ctx)
+	})
+}
+
+// ValidateCamtrapDP checks the package at path the same way ImportCamtrapDP
+// does, without writing to the database.
+func ValidateCamtrapDP(ctx context.Context, q db.Querier, path string, opts Options) (*Report, error) {
+	files, err := CamtrapDPFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(path, true)
+	if err := readCamtrapDP(ctx, q, files, report, opts, nil, nil); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// camtrapMedia is a row of media.csv.
+type camtrapMedia struct {
+	file      string
+	timestamp time.Time
+	err       error
+}
+
+// camtrapPackage holds the deployments and media observations refer to.
+type camtrapPackage struct {
+	sites map[string]db.Site
+	media map[string]camtrapMedia
+	// deploymentMedia lists the media of each deployment in time order
+	deploymentMedia map[string][]camtrapMedia
+	// eventLevel is set when the package has event observations, which
+	// then take the place of its media observations
+	eventLevel bool
+}
+
+func readCamtrapDP(ctx context.Context, q db.Querier, files map[string]string, report *Report, opts Options, add func(context.Context, db.CreateObservationsParams) error, addDeployment func(context.Context, db.UpsertDeploymentParams) error) error {
+	tables := make(map[string]*table, len(files))
+	for name, filename := range files {
+		t, err := readTable(filename, camtrapResources[name]...)
+		if err != nil {
+			return err
+		}
+		tables[name] = t
+	}
+	deployments, media, observations := tables["deployments"], tables["media"], tables["observations"]
+	report.header = observations.header
+
+	rows := newRowImporter(q, columns{}, report)
+	pkg := camtrapPackage{
+		sites:           make(map[string]db.Site),
+		media:           make(map[string]camtrapMedia),
+		deploymentMedia: make(map[string][]camtrapMedia),
+	}
+
+	for i, row := range deployments.rows {
+		site, err := rows.deploymentSite(ctx, deployments, row, opts.Site)
+		if err == nil {
+			pkg.sites[deployments.get(row, "deploymentID")] = site
+			err = rows.camtrapDeployment(ctx, deployments, row, site, addDeployment)
+		}
+		if errors.As(err, &invalidRow{}) {
+			// Deployments are not counted as rows, only their errors are
+			report.reject(i+2, row, fmt.Errorf("%s: %w", deployments.name, err))
+			if report.DryRun {
+				continue
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", deployments.name, i+2, err)
+		}
+	}
+
+	for _, row := range media.rows {
+		m := camtrapMedia{file: media.get(row, "filePath")}
+		m.timestamp, m.err = parseEventDate(media.get(row, "timestamp"), "")
+		pkg.media[media.get(row, "mediaID")] = m
+		deployment := media.get(row, "deploymentID")
+		pkg.deploymentMedia[deployment] = append(pkg.deploymentMedia[deployment], m)
+	}
+	for _, list := range pkg.deploymentMedia {
+		slices.SortFunc(list, func(a, b camtrapMedia) int {
+			return a.timestamp.Compare(b.timestamp)
+		})
+	}
+	pkg.eventLevel = slices.ContainsFunc(observations.rows, func(row []string) bool {
+		return observations.get(row, "observationLevel") == "event"
+	})
+
+	var sightings []sighting
+	for i, row := range observations.rows {
+		report.TotalRows++
+		s, err := rows.parseCamtrapObservation(ctx, observations, row, pkg)
+		valid, err := report.check(i+2, row, err)
+		if err != nil {
+			return fmt.Errorf("%s line %d: %w", observations.name, i+2, err)
+		}
+		if valid {
+			sightings = append(sightings, s)
+		}
+	}
+
+	if add == nil {
+		return nil
+	}
+	for _, params := range groupEvents(sightings, opts.eventGap(), rows.batchID) {
+		if err := add(ctx, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deploymentSite returns the site of a deployment, creating it when it does
+// not exist yet.
+func (r *rowImporter) deploymentSite(ctx context.Context, t *table, row []string, defaults SiteDefaults) (db.Site, error) {
+	if t.get(row, "deploymentID") == "" {
+		return db.Site{}, invalidRow{errors.New("missing deploymentID")}
+	}
+	name := t.get(row, "locationName")
+	siteCode := cmp.Or(t.get(row, "locationID"), name, t.get(row, "deploymentID"))
+	return r.site(ctx, siteCode, func() (db.CreateSiteParams, error) {
+		location, err := formatLocation(t.get(row, "latitude"), t.get(row, "longitude"))
+		if err != nil {
+			return db.CreateSiteParams{}, err
+		}
+		return defaults.siteParams(siteCode, cmp.Or(name, siteCode), location)
+	})
+}
+
+// camtrapDeployment records the period of a deployment as the effort at its
+// site. Deployments without a start and end are left out.
+func (r *rowImporter) camtrapDeployment(ctx context.Context, t *table, row []string, site db.Site, add func(context.Context, db.UpsertDeploymentParams) error) error {
+	start, end := t.get(row, "deploymentStart"), t.get(row, "deploymentEnd")
+	if start == "" && end == "" {
+		return nil
+	}
+	notes := t.get(row, "deploymentComments")
+	params, err := deploymentParams(site.ID, db.ObservationMethodCamera, start, end, t.get(row, "cameraID"), notes, r.batchID)
+	if err != nil || add == nil {
+		return err
+	}
+	return add(ctx, params)
+}
+
+// parseCamtrapObservation turns an animal observation into a sighting.
+func (r *rowImporter) parseCamtrapObservation(ctx context.Context, t *table, row []string, pkg camtrapPackage) (sighting, error) {
+	if obsType := t.get(row, "observationType"); obsType != "animal" {
+		return sighting{}, skippedRow{"observation type " + cmp.Or(obsType, "unclassified")}
+	}
+	level := t.get(row, "observationLevel")
+	if pkg.eventLevel && level != "event" {
+		return sighting{}, skippedRow{"media observation in a package with event observations"}
+	}
+
+	site, ok := pkg.sites[t.get(row, "deploymentID")]
+	if !ok {
+		return sighting{}, invalidRow{fmt.Errorf("unknown deployment %q", t.get(row, "deploymentID"))}
+	}
+	scientific := t.get(row, "scientificName")
+	if scientific == "" {
+		return sighting{}, skippedRow{"unidentified animal"}
+	}
+	species, err := r.knownSpecies(ctx, scientific, "")
+	if err != nil {
+		return sighting{}, err
+	}
+
+	s := sighting{siteID: site.ID, speciesID: species.ID}
+	if p := t.get(row, "classificationProbability"); p != "" {
+		c, err := strconv.ParseFloat(p, 32)
+		if err != nil {
+			return sighting{}, invalidRow{fmt.Errorf("invalid classificationProbability %q", p)}
+		}
+		confidence := float32(c)
+		s.confidence = &confidence
+	}
+
+	if level == "event" {
+		s.start, err = parseEventDate(t.get(row, "eventStart"), "")
+		if err != nil {
+			return sighting{}, invalidRow{fmt.Errorf("invalid eventStart: %w", err)}
+		}
+		s.end = s.start
+		if end := t.get(row, "eventEnd"); end != "" {
+			s.end, err = parseEventDate(end, "")
+			if err != nil {
+				return sighting{}, invalidRow{fmt.Errorf("invalid eventEnd: %w", err)}
+			}
+		}
+		s.event = cmp.Or(t.get(row, "eventID"), t.get(row, "observationID"))
+		s.file = firstMedia(pkg.deploymentMedia[t.get(row, "deploymentID")], s.start, s.end)
+		return s, nil
+	}
+
+	m, ok := pkg.media[t.get(row, "mediaID")]
+	if !ok {
+		return sighting{}, invalidRow{fmt.Errorf("unknown media %q", t.get(row, "mediaID"))}
+	}
+	if m.err != nil {
+		return sighting{}, invalidRow{fmt.Errorf("invalid media timestamp: %w", m.err)}
+	}
+	s.start, s.end = m.timestamp, m.timestamp
+	s.file = m.file
+	s.event = t.get(row, "eventID")
+	return s, nil
+}
+
+// firstMedia returns the file of the first media taken between start and
+// end, or "" when there is none.
+func firstMedia(media []camtrapMedia, start, end time.Time) string {
+	i, _ := slices.BinarySearchFunc(media, start, func(m camtrapMedia, t time.Time) int {
+		return m.timestamp.Compare(t)
+	})
+	if i < len(media) && !media[i].timestamp.After(end) {
+		return media[i].file
+	}
+	return ""
+}
+
+// table is a CSV file read whole, its values looked up by column name.
+type table struct {
+	name   string
+	header []string
+	index  map[string]int
+	rows   [][]string
+}
+
+// readTable reads a CSV file, checking it has the required columns.
+func readTable(filename string, required ...string) (*table, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	t := &table{name: filepath.Base(filename), index: make(map[string]int)}
+	reader := newCSVReader(file)
+	t.header, err = reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s header: %w", t.name, err)
+	}
+	for i, name := range t.header {
+		t.index[name] = i
+	}
+	for _, name := range required {
+		if _, ok := t.index[name]; !ok {
+			return nil, fmt.Errorf("%s has no %s column", t.name, name)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", t.name, err)
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t, nil
+}
+
+func (t *table) get(row []string, column string) string {
+	i, ok := t.index[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func sortedValues(m map[string]string) []string {
+	keys := slices.Sorted(maps.Keys(m))
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+	}
+	return values
+}
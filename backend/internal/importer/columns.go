@@ -0,0 +1,259 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Field is a value the importer reads from a CSV column.
+type Field string
+
+const (
+	FieldSiteCode        Field = "site_code"
+	FieldDate            Field = "date"
+	FieldTime            Field = "time"
+	FieldMethod          Field = "method"
+	FieldAppearanceStart Field = "appearance_start"
+	FieldAppearanceEnd   Field = "appearance_end"
+	FieldTemperature     Field = "temperature"
+	FieldNarrative       Field = "narrative"
+	FieldConfidence      Field = "confidence"
+	FieldFile            Field = "file"
+	FieldScientificName  Field = "scientific_name"
+	FieldCommonName      Field = "common_name"
+	FieldForest          Field = "forest"
+	FieldIndicator       Field = "indicator"
+	FieldNative          Field = "native"
+	FieldTenure          Field = "tenure"
+	FieldReportable      Field = "reportable"
+	FieldBlock           Field = "block"
+	FieldTaxa            Field = "taxa"
+
+	// fieldFileOffset is where a detection starts within its recording in
+	// BirdNET tables that combine several recordings.
+	fieldFileOffset Field = "file_offset"
+)
+
+// requiredFields must all be present in the header of a CSV. The other
+// fields are optional and left empty when their column is missing.
+var requiredFields = []Field{
+	FieldSiteCode,
+	FieldDate,
+	FieldTime,
+	FieldMethod,
+	FieldScientificName,
+	FieldCommonName,
+	FieldForest,
+	FieldIndicator,
+	FieldNative,
+	FieldTenure,
+	FieldReportable,
+	FieldBlock,
+	FieldTaxa,
+}
+
+var optionalFields = []Field{
+	FieldAppearanceStart,
+	FieldAppearanceEnd,
+	FieldTemperature,
+	FieldNarrative,
+	FieldConfidence,
+	FieldFile,
+}
+
+// ColumnMapping lists, for each field, the header names its column may have.
+// Headers are matched case-insensitively, ignoring spaces and punctuation, and
+// the field name itself always matches.
+type ColumnMapping map[Field][]string
+
+// DefaultColumns matches the headers of the master survey spreadsheet. They
+// are kept to names that cannot be mistaken for another field; spreadsheets
+// with other headers can be read with a mapping file, or by position when
+// asked to.
+var DefaultColumns = ColumnMapping{
+	FieldSiteCode:        {"Site", "Site code", "Site ID"},
+	FieldDate:            {"Date", "Survey date"},
+	FieldTime:            {"Time", "Survey time"},
+	FieldMethod:          {"Method", "Survey method", "Detection method"},
+	FieldAppearanceStart: {"Appearance start", "Start (s)"},
+	FieldAppearanceEnd:   {"Appearance end", "End (s)"},
+	FieldTemperature:     {"Temperature", "Temp"},
+	FieldNarrative:       {"Narrative"},
+	FieldConfidence:      {"Confidence"},
+	FieldScientificName:  {"Scientific name"},
+	FieldCommonName:      {"Common name"},
+	FieldForest:          {"Forest", "Forest type"},
+	FieldIndicator:       {"Indicator"},
+	FieldNative:          {"Native"},
+	FieldTenure:          {"Tenure"},
+	FieldReportable:      {"Reportable"},
+	FieldBlock:           {"Block"},
+	FieldTaxa:            {"Taxa"},
+}
+
+// positionalColumns is the fixed layout of the survey spreadsheet, which the
+// importer read by position before columns were matched by header name.
+// Files are only read with it when Options.Positional asks to, never because
+// their header does not resolve.
+var positionalColumns = columns{
+	index: map[Field]int{
+		FieldSiteCode:        1,
+		FieldDate:            4,
+		FieldTime:            5,
+		FieldMethod:          6,
+		FieldAppearanceStart: 8,
+		FieldAppearanceEnd:   9,
+		FieldTemperature:     10,
+		FieldNarrative:       11,
+		FieldConfidence:      13,
+		FieldScientificName:  14,
+		FieldCommonName:      15,
+		FieldForest:          16,
+		FieldIndicator:       17,
+		FieldNative:          18,
+		FieldTenure:          19,
+		FieldReportable:      20,
+		FieldBlock:           21,
+		FieldTaxa:            22,
+	},
+	width:      23,
+	positional: true,
+}
+
+// LoadColumnMapping reads a YAML (or JSON) file mapping field names to the
+// header names used by a spreadsheet, e.g.
+//
+//	site_code: [Site, Site code]
+//	scientific_name: [Scientific name]
+//
+// Fields missing from the file keep the aliases of DefaultColumns.
+func LoadColumnMapping(filename string) (ColumnMapping, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column mapping: %w", err)
+	}
+	var custom ColumnMapping
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("failed to parse column mapping: %w", err)
+	}
+
+	mapping := make(ColumnMapping, len(DefaultColumns))
+	for field, aliases := range DefaultColumns {
+		mapping[field] = aliases
+	}
+	for field, aliases := range custom {
+		if !slices.Contains(requiredFields, field) && !slices.Contains(optionalFields, field) {
+			return nil, fmt.Errorf("unknown field %q in column mapping", field)
+		}
+		mapping[field] = aliases
+	}
+	return mapping, nil
+}
+
+// columns maps each field to its column index in a CSV.
+type columns struct {
+	index map[Field]int
+	width int
+	// positional is set when the columns come from positionalColumns
+	// rather than the header.
+	positional bool
+}
+
+// resolve finds the column of every field in the header. All missing
+// required fields are reported at once, as are headers matching more than
+// one field or fields matching more than one header.
+func (m ColumnMapping) resolve(header []string) (columns, error) {
+	return m.resolveFields(header, requiredFields, optionalFields)
+}
+
+// resolveColumns finds the columns of the CSV from its header, by name
+// unless opts.Positional asks for the survey spreadsheet's fixed order.
+func (o Options) resolveColumns(header []string) (columns, error) {
+	if !o.Positional {
+		return o.columns().resolve(header)
+	}
+	if o.Columns != nil {
+		return columns{}, fmt.Errorf("a column mapping cannot be used when reading columns by position")
+	}
+	if len(header) < positionalColumns.width {
+		return columns{}, fmt.Errorf("reading columns by position needs %d columns, the header has %d",
+			positionalColumns.width, len(header))
+	}
+	return positionalColumns, nil
+}
+
+// resolveFields is resolve for other files than the survey spreadsheet, with
+// their own required and optional fields.
+func (m ColumnMapping) resolveFields(header []string, required, optional []Field) (columns, error) {
+	cols := columns{index: make(map[Field]int), width: len(header)}
+
+	matches := make(map[string]Field)
+	for _, field := range append(slices.Clone(required), optional...) {
+		for _, alias := range append([]string{string(field)}, m[field]...) {
+			key := normaliseHeader(alias)
+			if other, ok := matches[key]; ok && other != field {
+				return cols, fmt.Errorf("header %q is mapped to both %s and %s", alias, other, field)
+			}
+			matches[key] = field
+		}
+	}
+
+	var problems []string
+	for i, name := range header {
+		field, ok := matches[normaliseHeader(name)]
+		if !ok {
+			continue
+		}
+		if j, dup := cols.index[field]; dup {
+			problems = append(problems, fmt.Sprintf("%s matches both columns %q and %q", field, header[j], name))
+			continue
+		}
+		cols.index[field] = i
+	}
+	for _, field := range required {
+		if _, ok := cols.index[field]; !ok {
+			problems = append(problems, fmt.Sprintf("missing required column for %s (accepted headers: %s)",
+				field, strings.Join(append([]string{string(field)}, m[field]...), ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return cols, fmt.Errorf("invalid CSV header:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return cols, nil
+}
+
+func normaliseHeader(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// record is a CSV row whose values are looked up by field.
+type record struct {
+	values  []string
+	columns columns
+}
+
+// has reports whether the field has a column.
+func (c columns) has(field Field) bool {
+	_, ok := c.index[field]
+	return ok
+}
+
+// get returns the value of the field, or "" when its column is missing.
+func (r record) get(field Field) string {
+	i, ok := r.columns.index[field]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	return r.values[i]
+}
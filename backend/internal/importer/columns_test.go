@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolveColumns(t *testing.T) {
+	named := []string{
+		"Site code", "Survey date", "Time", "Method", "Scientific name", "Common name",
+		"Forest type", "Indicator", "Native", "Tenure", "Reportable", "Block", "Taxa", "Temp",
+	}
+	// The survey spreadsheet's layout, under headers no alias knows
+	unnamed := make([]string, positionalColumns.width)
+	for i := range unnamed {
+		unnamed[i] = "Column " + string(rune('A'+i))
+	}
+
+	tests := []struct {
+		name    string
+		mapping ColumnMapping
+		header  []string
+		want    map[Field]int
+		err     string
+	}{
+		{
+			name:    "by name in any order",
+			mapping: DefaultColumns,
+			header:  named,
+			want:    map[Field]int{FieldSiteCode: 0, FieldDate: 1, FieldTaxa: 12, FieldTemperature: 13},
+		},
+		{
+			name:    "field names, ignoring case and punctuation",
+			mapping: DefaultColumns,
+			header: []string{
+				"SITE_CODE", "date", "time", "method", "scientific-name", "Common Name",
+				"forest", "indicator", "native", "tenure", "reportable", "block", "taxa",
+			},
+			want: map[Field]int{FieldSiteCode: 0, FieldScientificName: 4, FieldCommonName: 5},
+		},
+		{
+			name:    "custom aliases",
+			mapping: ColumnMapping{FieldSiteCode: {"Location"}},
+			header: []string{
+				"Location", "date", "time", "method", "scientific name", "common name",
+				"forest", "indicator", "native", "tenure", "reportable", "block", "taxa",
+			},
+			want: map[Field]int{FieldSiteCode: 0},
+		},
+		{
+			// Never read by position unless asked to
+			name:    "unknown headers of the survey spreadsheet's width",
+			mapping: DefaultColumns,
+			header:  unnamed,
+			err:     "missing required column for site_code",
+		},
+		{
+			name:    "wide header missing a column",
+			mapping: DefaultColumns,
+			header:  append(slices.Clone(named[1:]), unnamed...),
+			err:     "missing required column for site_code",
+		},
+		{
+			name:    "missing columns",
+			mapping: DefaultColumns,
+			header:  named[1:],
+			err:     "missing required column for site_code",
+		},
+		{
+			name:    "field matching two columns",
+			mapping: DefaultColumns,
+			header:  append(named, "Site"),
+			err:     `site_code matches both columns "Site code" and "Site"`,
+		},
+		{
+			name:    "alias of two fields",
+			mapping: ColumnMapping{FieldDate: {"When"}, FieldTime: {"When"}},
+			header:  named,
+			err:     "is mapped to both",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, err := tt.mapping.resolve(tt.header)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cols.positional {
+				t.Error("columns read by position")
+			}
+			for field, i := range tt.want {
+				if got, ok := cols.index[field]; !ok || got != i {
+					t.Errorf("%s is column %d (found: %v), want %d", field, got, ok, i)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveColumnsPositional(t *testing.T) {
+	named := strings.Split(testCSVHeader, ",")
+	wide := make([]string, positionalColumns.width)
+	tests := []struct {
+		name       string
+		opts       Options
+		header     []string
+		positional bool
+		err        string
+	}{
+		{"by name", Options{}, named, false, ""},
+		{"by position", Options{Positional: true}, wide, true, ""},
+		// The header names are ignored
+		{"named header by position", Options{Positional: true}, append(slices.Clone(named), wide[len(named):]...), true, ""},
+		{"narrow header by position", Options{Positional: true}, named, false, "needs 23 columns, the header has 13"},
+		{"mapping by position", Options{Positional: true, Columns: DefaultColumns}, wide, false, "cannot be used"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, err := tt.opts.resolveColumns(tt.header)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cols.positional != tt.positional {
+				t.Errorf("positional = %v, want %v", cols.positional, tt.positional)
+			}
+			if tt.positional && (cols.index[FieldSiteCode] != 1 || cols.index[FieldTaxa] != 22) {
+				t.Errorf("site code and taxa are columns %d and %d, want 1 and 22", cols.index[FieldSiteCode], cols.index[FieldTaxa])
+			}
+		})
+	}
+}
+
+func TestRecordGet(t *testing.T) {
+	r := record{
+		values:  []string{"SG01", "2024-10-05"},
+		columns: columns{index: map[Field]int{FieldSiteCode: 0, FieldDate: 1, FieldTime: 5}},
+	}
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{FieldSiteCode, "SG01"},
+		{FieldDate, "2024-10-05"},
+		// Short rows leave the last columns empty
+		{FieldTime, ""},
+		{FieldNarrative, ""},
+	}
+	for _, tt := range tests {
+		if got := r.get(tt.field); got != tt.want {
+			t.Errorf("get(%s) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
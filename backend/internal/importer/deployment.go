@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// deploymentColumns are the required columns of a deployments CSV. It may
+// also have device_id and notes columns.
+var deploymentColumns = []string{"site_code", "method", "start", "end"}
+
+// ImportDeployments imports the deployments of a CSV file with site_code,
+// method, start and end columns, and optionally device_id and notes, in one
+// import batch. Start and end are ISO 8601 dates and times. Deployments are
+// identified by site, method, device and start; opts.OnConflict decides what
+// happens to those already imported.
+func ImportDeployments(ctx context.Context, conn Conn, filename string, opts Options) (*Report, error) {
+	if !opts.OnConflict.Valid() {
+		return nil, fmt.Errorf("unknown conflict mode: %q", opts.OnConflict)
+	}
+	t, err := readTable(filename, deploymentColumns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return importBatch(ctx, conn, filename, []string{filename}, func(ctx context.Context, q *db.Queries, report *Report) error {
+		return readDeployments(ctx, q, t, report, newDeploymentWriter(q, opts.OnConflict, report))
+	})
+}
+
+// ValidateDeployments checks the deployments of a CSV file the same way
+// ImportDeployments does, without writing to the database.
+func ValidateDeployments(ctx context.Context, q db.Querier, filename string, opts Options) (*Report, error) {
+	t, err := readTable(filename, deploymentColumns...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(filename, true)
+	if err := readDeployments(ctx, q, t, report, nil); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func readDeployments(ctx context.Context, q db.Querier, t *table, report *Report, add func(context.Context, db.UpsertDeploymentParams) error) error {
+	report.header = t.header
+	rows := newRowImporter(q, columns{}, report)
+	for i, row := range t.rows {
+		report.TotalRows++
+		params, err := rows.parseDeployment(ctx, t, row)
+		valid, err := report.check(i+2, row, err)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+2, err)
+		}
+		if !valid || add == nil {
+			continue
+		}
+		if err := add(ctx, params); err != nil {
+			return fmt.Errorf("line %d: %w", i+2, err)
+		}
+	}
+	return nil
+}
+
+// parseDeployment turns a row of a deployments CSV into a deployment of an
+// existing site.
+func (r *rowImporter) parseDeployment(ctx context.Context, t *table, row []string) (db.UpsertDeploymentParams, error) {
+	siteCode := t.get(row, "site_code")
+	site, err := r.cache.GetSite(ctx, siteCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.UpsertDeploymentParams{}, invalidRow{fmt.Errorf("unknown site %q", siteCode)}
+	}
+	if err != nil {
+		return db.UpsertDeploymentParams{}, err
+	}
+
+	method := db.ObservationMethod(strings.ToLower(t.get(row, "method")))
+	if !method.Valid() {
+		return db.UpsertDeploymentParams{}, invalidRow{fmt.Errorf("unknown observation method %q", t.get(row, "method"))}
+	}
+	return deploymentParams(site.ID, method, t.get(row, "start"), t.get(row, "end"), t.get(row, "device_id"), t.get(row, "notes"), r.batchID)
+}
+
+// deploymentParams parses the period of a deployment, leaving empty device
+// IDs and notes out.
+func deploymentParams(siteID int64, method db.ObservationMethod, start, end, deviceID, notes string, batchID *int64) (db.UpsertDeploymentParams, error) {
+	params := db.UpsertDeploymentParams{
+		SiteID:        siteID,
+		Method:        method,
+		ImportBatchID: batchID,
+	}
+	var err error
+	params.StartTime, err = parseEventDate(start, "")
+	if err != nil {
+		return db.UpsertDeploymentParams{}, invalidRow{fmt.Errorf("invalid start: %w", err)}
+	}
+	params.EndTime, err = parseEventDate(end, "")
+	if err != nil {
+		return db.UpsertDeploymentParams{}, invalidRow{fmt.Errorf("invalid end: %w", err)}
+	}
+	if !params.EndTime.After(params.StartTime) {
+		return db.UpsertDeploymentParams{}, invalidRow{errors.New("end is not after start")}
+	}
+	if deviceID != "" {
+		params.DeviceID = &deviceID
+	}
+	if notes != "" {
+		params.Notes = &notes
+	}
+	return params, nil
+}
+
+// newDeploymentWriter returns a function upserting deployments and counting
+// those written in the report.
+func newDeploymentWriter(q db.Querier, mode ConflictMode, report *Report) func(context.Context, db.UpsertDeploymentParams) error {
+	return func(ctx context.Context, params db.UpsertDeploymentParams) error {
+		params.UpdateExisting = mode == ConflictUpdate
+		n, err := q.UpsertDeployment(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to write deployment: %w", err)
+		}
+		report.Deployments += n
+		return nil
+	}
+}
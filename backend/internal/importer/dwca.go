@@ -0,0 +1,334 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/config"
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/dwca"
+)
+
+// dwcaTerms are the Darwin Core terms occurrences are imported from. Any
+// other term of an archive is reported as unmapped.
+var dwcaTerms = []string{
+	"basisOfRecord",
+	"occurrenceStatus",
+	"eventDate",
+	"eventTime",
+	"samplingProtocol",
+	"scientificName",
+	"vernacularName",
+	"class",
+	"establishmentMeans",
+	"locationID",
+	"locality",
+	"decimalLatitude",
+	"decimalLongitude",
+	"occurrenceRemarks",
+	"associatedMedia",
+	"dynamicProperties",
+}
+
+// occurrenceProperties are the values without a Darwin Core term that
+// dwca.Write stores as JSON in dynamicProperties.
+type occurrenceProperties struct {
+	Block           *int32        `json:"block"`
+	Tenure          db.TenureType `json:"tenure"`
+	Forest          db.ForestType `json:"forest"`
+	Confidence      *float32      `json:"confidence"`
+	Temperature     *int32        `json:"temperature"`
+	AppearanceStart *int32        `json:"appearanceStart"`
+	AppearanceEnd   *int32        `json:"appearanceEnd"`
+}
+
+// ImportDwCA imports the occurrences of a Darwin Core Archive as a new
+// import batch, in a single transaction like ImportCSV. Sites are matched by
+// locationID, or locality when there is none, and species by scientificName.
+// New sites take their block, tenure and forest from dynamicProperties or
+// else opts.Site. The report lists the terms that were not imported.
+func ImportDwCA(ctx context.Context, conn Conn, filename string, opts Options) (*Report, error) {
+	if !opts.OnConflict.Valid() {
+		return nil, fmt.Errorf("unknown conflict mode: %q", opts.OnConflict)
+	}
+	archive, err := openDwCA(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	return importBatch(ctx, conn, filename, []string{filename}, func(ctx context.Context, q *db.Queries, report *Report) error {
+		observations := newObservationWriter(q, opts.OnConflict, report)
+		if err := readDwCA(ctx, q, archive, report, opts, observations.add); err != nil {
+			return err
+		}
+		return observations.flush(ctx)
+	})
+}
+
+// ValidateDwCA checks every occurrence of a Darwin Core Archive the same way
+// ImportDwCA does, without writing to the database, and collects the
+// rejected rows in the report as ValidateCSV does.
+func ValidateDwCA(ctx context.Context, q db.Querier, filename string, opts Options) (*Report, error) {
+	archive, err := openDwCA(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	report := newReport(filename, true)
+	if err := readDwCA(ctx, q, archive, report, opts, nil); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func openDwCA(filename string) (*dwca.Archive, error) {
+	archive, err := dwca.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if archive.Meta.Core.RowType != dwca.OccurrenceRowType {
+		archive.Close()
+		return nil, fmt.Errorf("unsupported core row type %s, only occurrence archives can be imported", archive.Meta.Core.RowType)
+	}
+	return archive, nil
+}
+
+// readDwCA parses every occurrence of the archive and passes it to add. In
+// dry-run mode invalid rows are rejected and skipped, otherwise the first
+// one stops the import.
+func readDwCA(ctx context.Context, q db.Querier, archive *dwca.Archive, report *Report, opts Options, add func(context.Context, db.CreateObservationsParams) error) error {
+	core, err := archive.Core()
+	if err != nil {
+		return err
+	}
+	defer core.Close()
+
+	report.header = core.Header()
+	for _, term := range core.Terms() {
+		if !slices.Contains(dwcaTerms, term) {
+			report.UnmappedTerms = append(report.UnmappedTerms, term)
+		}
+	}
+	for _, ext := range archive.Meta.Extensions {
+		report.UnmappedTerms = append(report.UnmappedTerms, dwca.Term(ext.RowType)+" extension")
+	}
+
+	rows := newRowImporter(q, columns{}, report)
+	for {
+		row, err := core.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", core.File.Location, err)
+		}
+
+		report.TotalRows++
+		params, err := rows.parseOccurrence(ctx, row, opts.Site)
+		valid, err := report.check(row.Line, row.Values, err)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", row.Line, err)
+		}
+		if !valid || add == nil {
+			continue
+		}
+		if err := add(ctx, params); err != nil {
+			return fmt.Errorf("line %d: %w", row.Line, err)
+		}
+	}
+	return nil
+}
+
+// parseOccurrence resolves the site and species of an occurrence row,
+// creating them unless in dry-run mode, and parses its observation.
+func (r *rowImporter) parseOccurrence(ctx context.Context, row dwca.Row, defaults SiteDefaults) (db.CreateObservationsParams, error) {
+	if strings.EqualFold(row.Get("occurrenceStatus"), "absent") {
+		return db.CreateObservationsParams{}, skippedRow{"absent occurrence"}
+	}
+
+	var props occurrenceProperties
+	// Only our own JSON is understood, other publishers use free text
+	if p := row.Get("dynamicProperties"); strings.HasPrefix(p, "{") {
+		if err := json.Unmarshal([]byte(p), &props); err != nil {
+			return db.CreateObservationsParams{}, invalidRow{fmt.Errorf("invalid dynamicProperties: %w", err)}
+		}
+	}
+
+	siteCode := row.Get("locationID")
+	if siteCode == "" {
+		siteCode = row.Get("locality")
+	}
+	if siteCode == "" {
+		return db.CreateObservationsParams{}, invalidRow{errors.New("missing locationID and locality")}
+	}
+	site, err := r.site(ctx, siteCode, func() (db.CreateSiteParams, error) {
+		return parseOccurrenceSite(row, siteCode, props, defaults)
+	})
+	if err != nil {
+		return db.CreateObservationsParams{}, err
+	}
+
+	scientific := row.Get("scientificName")
+	if scientific == "" {
+		return db.CreateObservationsParams{}, invalidRow{errors.New("missing scientificName")}
+	}
+	species, err := r.species(ctx, scientific, func() (db.CreateSpeciesParams, error) {
+		params, err := parseOccurrenceSpecies(row)
+		if errors.As(err, &skippedRow{}) {
+			r.report.unsupportedClass(row.Get("class"))
+		}
+		return params, err
+	})
+	if err != nil {
+		return db.CreateObservationsParams{}, err
+	}
+
+	params, err := parseOccurrenceObservation(row, props, site.ID, species.ID)
+	if err != nil {
+		return db.CreateObservationsParams{}, invalidRow{fmt.Errorf("failed to parse observation: %w", err)}
+	}
+	params.ImportBatchID = r.batchID
+	return params, nil
+}
+
+func parseOccurrenceSite(row dwca.Row, siteCode string, props occurrenceProperties, defaults SiteDefaults) (db.CreateSiteParams, error) {
+	if props.Block != nil {
+		defaults.Block = props.Block
+	}
+	if props.Tenure != "" {
+		defaults.Tenure = props.Tenure
+	}
+	if props.Forest != "" {
+		defaults.Forest = props.Forest
+	}
+
+	name := siteCode
+	if locality := row.Get("locality"); locality != "" {
+		name = locality
+	}
+	location, err := formatLocation(row.Get("decimalLatitude"), row.Get("decimalLongitude"))
+	if err != nil {
+		return db.CreateSiteParams{}, err
+	}
+	return defaults.siteParams(siteCode, name, location)
+}
+
+func parseOccurrenceSpecies(row dwca.Row) (species db.CreateSpeciesParams, err error) {
+	scientific := row.Get("scientificName")
+	common := row.Get("vernacularName")
+	if common == "" {
+		common = scientific
+	}
+
+	class := row.Get("class")
+	if class == "" {
+		err = errors.New("missing class")
+		return
+	}
+	taxa, ok := dwca.ClassTaxa(class)
+	if !ok {
+		// Other taxa, e.g. the invertebrates of a partner's survey, are out
+		// of scope rather than wrong
+		err = skippedRow{"class other than birds, mammals and reptiles"}
+		return
+	}
+
+	// Wild occurrences are native unless the archive says otherwise, e.g.
+	// "introduced" or "invasive"
+	means := strings.ToLower(row.Get("establishmentMeans"))
+	native := means == "" || strings.HasPrefix(means, "native")
+
+	return db.CreateSpeciesParams{
+		ScientificName: scientific,
+		CommonName:     common,
+		Native:         native,
+		Taxa:           taxa,
+	}, nil
+}
+
+func parseOccurrenceObservation(row dwca.Row, props occurrenceProperties, siteID, speciesID int64) (param db.CreateObservationsParams, err error) {
+	timestamp, err := parseEventDate(row.Get("eventDate"), row.Get("eventTime"))
+	if err != nil {
+		return
+	}
+
+	method, err := occurrenceMethod(row.Get("samplingProtocol"), row.Get("basisOfRecord"))
+	if err != nil {
+		return
+	}
+
+	var narrativePtr *string
+	if narrative := row.Get("occurrenceRemarks"); narrative != "" {
+		narrativePtr = &narrative
+	}
+
+	// associatedMedia is a "|" separated list, keep the first
+	var filePtr *string
+	if media, _, _ := strings.Cut(row.Get("associatedMedia"), "|"); strings.TrimSpace(media) != "" {
+		file := strings.TrimSpace(media)
+		filePtr = &file
+	}
+
+	return db.CreateObservationsParams{
+		SiteID:          siteID,
+		SpeciesID:       speciesID,
+		Timestamp:       timestamp,
+		Method:          method,
+		AppearanceStart: props.AppearanceStart,
+		AppearanceEnd:   props.AppearanceEnd,
+		Temperature:     props.Temperature,
+		Narrative:       narrativePtr,
+		Confidence:      props.Confidence,
+		File:            filePtr,
+	}, nil
+}
+
+// parseEventDate parses an ISO 8601 eventDate, or the start of a date range,
+// into local time. A date without a time takes eventTime, or else midnight.
+func parseEventDate(date, timeOfDay string) (time.Time, error) {
+	date, _, _ = strings.Cut(date, "/")
+	if date == "" {
+		return time.Time{}, errors.New("missing eventDate")
+	}
+	if !strings.Contains(date, "T") && timeOfDay != "" {
+		date += "T" + timeOfDay
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.In(config.TIMEZONE), nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, date, config.TIMEZONE); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid eventDate %q", date)
+}
+
+// occurrenceMethod works out the observation method from the
+// samplingProtocol, falling back to basisOfRecord for human observations.
+func occurrenceMethod(protocol, basisOfRecord string) (db.ObservationMethod, error) {
+	p := strings.ToLower(protocol)
+	switch {
+	case db.ObservationMethod(p).Valid():
+		return db.ObservationMethod(p), nil
+	case strings.Contains(p, "camera"):
+		return db.ObservationMethodCamera, nil
+	case strings.Contains(p, "audio"), strings.Contains(p, "acoustic"), strings.Contains(p, "sound"):
+		return db.ObservationMethodAudio, nil
+	}
+	if strings.EqualFold(basisOfRecord, "HumanObservation") {
+		return db.ObservationMethodObserved, nil
+	}
+	return "", fmt.Errorf("unknown observation method for samplingProtocol %q and basisOfRecord %q", protocol, basisOfRecord)
+}
@@ -0,0 +1,223 @@
+package importer
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/dwca"
+	"github.com/jackc/pgx/v5"
+)
+
+// emptyQuerier has no sites or species yet.
+type emptyQuerier struct {
+	db.Querier
+}
+
+func (emptyQuerier) GetSiteByCode(ctx context.Context, code string) (db.Site, error) {
+	return db.Site{}, pgx.ErrNoRows
+}
+
+func (emptyQuerier) GetSpeciesByScientificName(ctx context.Context, name string) (db.Species, error) {
+	return db.Species{}, pgx.ErrNoRows
+}
+
+var testSiteDefaults = func() SiteDefaults {
+	block := int32(1)
+	return SiteDefaults{Block: &block, Tenure: db.TenureTypePublic, Forest: db.ForestTypeDry}
+}()
+
+// readTestDwCA validates the archive and returns the report with the
+// observations it would import.
+func readTestDwCA(t *testing.T, filename string) (*Report, []db.CreateObservationsParams) {
+	t.Helper()
+	archive, err := openDwCA(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	report := newReport(filename, true)
+	var observations []db.CreateObservationsParams
+	err = readDwCA(context.Background(), emptyQuerier{}, archive, report, Options{Site: testSiteDefaults}, func(ctx context.Context, o db.CreateObservationsParams) error {
+		observations = append(observations, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report, observations
+}
+
+func TestDwCARoundTrip(t *testing.T) {
+	narrative := "Two calling\tnear the creek"
+	file := "REC_0001.wav"
+	confidence := float32(0.75)
+	start, end := int32(3), int32(12)
+	exported := []db.ObservationsWithDetail{
+		{
+			ID:              1,
+			Timestamp:       time.Date(2024, 10, 5, 6, 30, 0, 0, time.UTC),
+			Method:          db.ObservationMethodAudio,
+			AppearanceStart: &start,
+			AppearanceEnd:   &end,
+			Narrative:       &narrative,
+			Confidence:      &confidence,
+			File:            &file,
+			Native:          true,
+			Taxa:            db.TaxaBird,
+			ScientificName:  "Menura novaehollandiae",
+			CommonName:      "Superb Lyrebird",
+			Block:           3,
+			SiteCode:        "SG01",
+			Tenure:          db.TenureTypePrivate,
+			Forest:          db.ForestTypeWet,
+		},
+		{
+			ID:             2,
+			Timestamp:      time.Date(2024, 4, 7, 2, 30, 0, 0, time.UTC),
+			Method:         db.ObservationMethodObserved,
+			Taxa:           db.TaxaMammal,
+			ScientificName: "Vulpes vulpes",
+			CommonName:     "Red Fox",
+			Block:          1,
+			SiteCode:       "KW02",
+			Tenure:         db.TenureTypePublic,
+			Forest:         db.ForestTypeDry,
+		},
+	}
+	filename := filepath.Join(t.TempDir(), "dwca.zip")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dwca.Write(f, exported, dwca.DefaultMetadata()); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, imported := readTestDwCA(t, filename)
+	if report.ValidRows != 2 || report.RejectedRows != 0 || len(report.Skipped) != 0 {
+		t.Fatalf("report = %d valid, %d rejected, skipped %v, errors %v", report.ValidRows, report.RejectedRows, report.Skipped, report.Errors)
+	}
+	if !slices.Equal(report.NewSites, []string{"SG01", "KW02"}) {
+		t.Errorf("new sites = %v", report.NewSites)
+	}
+	if !slices.Equal(report.NewSpecies, []string{"Menura novaehollandiae", "Vulpes vulpes"}) {
+		t.Errorf("new species = %v", report.NewSpecies)
+	}
+	if !slices.Contains(report.UnmappedTerms, "occurrenceID") {
+		t.Errorf("unmapped terms %v miss occurrenceID", report.UnmappedTerms)
+	}
+
+	for i, o := range imported {
+		want := exported[i]
+		got := o.Timestamp
+		if got.Format(time.DateTime) != want.Timestamp.Format(time.DateTime) {
+			t.Errorf("observation %d timestamp = %v, want %v local time", i, got, want.Timestamp)
+		}
+		if o.Method != want.Method {
+			t.Errorf("observation %d method = %s, want %s", i, o.Method, want.Method)
+		}
+		checkEqual(t, "confidence", o.Confidence, want.Confidence)
+		checkEqual(t, "appearance start", o.AppearanceStart, want.AppearanceStart)
+		checkEqual(t, "appearance end", o.AppearanceEnd, want.AppearanceEnd)
+		checkEqual(t, "file", o.File, want.File)
+	}
+	if n := imported[0].Narrative; n == nil || *n != "Two calling near the creek" {
+		t.Errorf("narrative = %v, want the tab cleaned out", n)
+	}
+	if imported[1].Narrative != nil {
+		t.Errorf("narrative = %q, want none", *imported[1].Narrative)
+	}
+}
+
+func TestDwCASkippedAndRejected(t *testing.T) {
+	const meta = `<?xml version="1.0" encoding="UTF-8"?>
+<archive xmlns="http://rs.tdwg.org/dwc/text/">
+  <core encoding="UTF-8" fieldsTerminatedBy="\t" fieldsEnclosedBy="" ignoreHeaderLines="1" rowType="http://rs.tdwg.org/dwc/terms/Occurrence">
+    <files><location>occurrence.txt</location></files>
+    <field index="0" term="http://rs.tdwg.org/dwc/terms/locationID"/>
+    <field index="1" term="http://rs.tdwg.org/dwc/terms/eventDate"/>
+    <field index="2" term="http://rs.tdwg.org/dwc/terms/scientificName"/>
+    <field index="3" term="http://rs.tdwg.org/dwc/terms/class"/>
+    <field index="4" term="http://rs.tdwg.org/dwc/terms/occurrenceStatus"/>
+    <field term="http://rs.tdwg.org/dwc/terms/basisOfRecord" default="HumanObservation"/>
+  </core>
+</archive>`
+	const occurrences = "locationID\teventDate\tscientificName\tclass\toccurrenceStatus\n" +
+		"SG01\t2024-10-05T06:30\tMenura novaehollandiae\tAves\tpresent\n" +
+		"SG01\t2024-10-05T07:00\tVulpes vulpes\tMammalia\tABSENT\n" +
+		"SG01\t2024-10-05T07:30\tApis mellifera\tInsecta\tpresent\n" +
+		"SG01\t2024-10-06T07:30\tApis mellifera\tInsecta\t\n" +
+		"SG01\t2024-10-06T08:00\tLitoria ewingii\tAmphibia\tpresent\n" +
+		"SG01\t2024-10-06T08:30\tUnknown thing\t\tpresent\n" +
+		"\t2024-10-06T09:00\tMenura novaehollandiae\tAves\tpresent\n"
+
+	filename := filepath.Join(t.TempDir(), "dwca.zip")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(f)
+	for name, content := range map[string]string{"meta.xml": meta, "occurrence.txt": occurrences} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, imported := readTestDwCA(t, filename)
+	if report.TotalRows != 7 || report.ValidRows != 1 || len(imported) != 1 {
+		t.Errorf("%d rows, %d valid, %d imported, want 7, 1 and 1", report.TotalRows, report.ValidRows, len(imported))
+	}
+	wantSkipped := map[string]int{
+		"absent occurrence":                            1,
+		"class other than birds, mammals and reptiles": 3,
+	}
+	if !maps.Equal(report.Skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", report.Skipped, wantSkipped)
+	}
+	if !slices.Equal(report.UnsupportedClasses, []string{"Insecta", "Amphibia"}) {
+		t.Errorf("unsupported classes = %v, want Insecta and Amphibia", report.UnsupportedClasses)
+	}
+	var rejected []int
+	for _, e := range report.Errors {
+		rejected = append(rejected, e.Row)
+	}
+	if !slices.Equal(rejected, []int{7, 8}) {
+		t.Errorf("rejected lines %v, want the missing class and site on 7 and 8", rejected)
+	}
+}
+
+func checkEqual[T comparable](t *testing.T, name string, got, want *T) {
+	t.Helper()
+	if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+		t.Errorf("%s = %v, want %v", name, deref(got), deref(want))
+	}
+}
+
+func deref[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
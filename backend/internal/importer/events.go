@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// DefaultEventGap groups the images a camera takes while an animal stays in
+// front of it into one observation.
+const DefaultEventGap = 2 * time.Minute
+
+// sighting is a species seen on camera, in one image or over an event.
+type sighting struct {
+	siteID     int64
+	speciesID  int64
+	start      time.Time
+	end        time.Time
+	confidence *float32
+	file       string
+	// event groups sightings already known to be one event. Sightings
+	// without one are grouped by time.
+	event string
+}
+
+// groupEvents turns the sightings into camera observations. Sightings of the
+// same species at the same site are one observation when they belong to the
+// same event, or, without an event, when less than gap apart. An
+// observation starts with its first sighting, its appearance runs from 0 to
+// the seconds until the end of the last one, and it keeps the file of the
+// first sighting and the highest confidence.
+func groupEvents(sightings []sighting, gap time.Duration, batchID *int64) []db.CreateObservationsParams {
+	slices.SortStableFunc(sightings, func(a, b sighting) int {
+		return cmp.Or(
+			cmp.Compare(a.siteID, b.siteID),
+			cmp.Compare(a.speciesID, b.speciesID),
+			cmp.Compare(a.event, b.event),
+			a.start.Compare(b.start),
+		)
+	})
+
+	var observations []db.CreateObservationsParams
+	for i := 0; i < len(sightings); {
+		first := sightings[i]
+		end := first.end
+		confidence := first.confidence
+		j := i + 1
+		for ; j < len(sightings); j++ {
+			s := sightings[j]
+			if s.siteID != first.siteID || s.speciesID != first.speciesID || s.event != first.event {
+				break
+			}
+			if first.event == "" && s.start.Sub(end) > gap {
+				break
+			}
+			if s.end.After(end) {
+				end = s.end
+			}
+			if s.confidence != nil && (confidence == nil || *s.confidence > *confidence) {
+				confidence = s.confidence
+			}
+		}
+
+		appearanceStart := int32(0)
+		appearanceEnd := int32(math.Ceil(end.Sub(first.start).Seconds()))
+		var file *string
+		if first.file != "" {
+			file = &first.file
+		}
+		observations = append(observations, db.CreateObservationsParams{
+			SiteID:          first.siteID,
+			SpeciesID:       first.speciesID,
+			Timestamp:       first.start,
+			Method:          db.ObservationMethodCamera,
+			AppearanceStart: &appearanceStart,
+			AppearanceEnd:   &appearanceEnd,
+			Confidence:      confidence,
+			File:            file,
+			ImportBatchID:   batchID,
+		})
+		i = j
+	}
+	return observations
+}
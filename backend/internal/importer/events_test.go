@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupEvents(t *testing.T) {
+	base := time.Date(2024, 10, 5, 6, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return base.Add(time.Duration(seconds) * time.Second)
+	}
+	score := func(v float32) *float32 { return &v }
+	// image is a sighting in a single image, event a sighting over an event.
+	image := func(site, species int64, second int, confidence *float32, file string) sighting {
+		return sighting{siteID: site, speciesID: species, start: at(second), end: at(second), confidence: confidence, file: file}
+	}
+	event := func(site, species int64, from, to int, id string) sighting {
+		return sighting{siteID: site, speciesID: species, start: at(from), end: at(to), event: id}
+	}
+
+	type observation struct {
+		site, species int64
+		start         int
+		appearanceEnd int32
+		confidence    *float32
+		file          string
+	}
+	tests := []struct {
+		name      string
+		sightings []sighting
+		want      []observation
+	}{
+		{"none", nil, nil},
+		{
+			"images less than the gap apart",
+			[]sighting{
+				image(1, 10, 0, score(0.6), "a.jpg"),
+				image(1, 10, 90, score(0.9), "b.jpg"),
+				image(1, 10, 200, score(0.7), "c.jpg"),
+			},
+			[]observation{{1, 10, 0, 200, score(0.9), "a.jpg"}},
+		},
+		{
+			"gap measured from the last image",
+			[]sighting{
+				image(1, 10, 0, nil, "a.jpg"),
+				image(1, 10, 121, nil, "b.jpg"),
+			},
+			[]observation{{1, 10, 0, 0, nil, "a.jpg"}, {1, 10, 121, 0, nil, "b.jpg"}},
+		},
+		{
+			"exactly the gap apart",
+			[]sighting{
+				image(1, 10, 0, nil, "a.jpg"),
+				image(1, 10, 120, nil, "b.jpg"),
+			},
+			[]observation{{1, 10, 0, 120, nil, "a.jpg"}},
+		},
+		{
+			"sorted by site, species and time",
+			[]sighting{
+				image(2, 10, 30, nil, "d.jpg"),
+				image(1, 20, 10, nil, "c.jpg"),
+				image(1, 10, 60, nil, "b.jpg"),
+				image(1, 10, 0, nil, "a.jpg"),
+			},
+			[]observation{
+				{1, 10, 0, 60, nil, "a.jpg"},
+				{1, 20, 10, 0, nil, "c.jpg"},
+				{2, 10, 30, 0, nil, "d.jpg"},
+			},
+		},
+		{
+			"confidence kept when later images have none",
+			[]sighting{
+				image(1, 10, 0, nil, ""),
+				image(1, 10, 5, score(0.4), ""),
+				image(1, 10, 10, nil, ""),
+			},
+			[]observation{{1, 10, 0, 10, score(0.4), ""}},
+		},
+		{
+			"known events regardless of the gap",
+			[]sighting{
+				event(1, 10, 0, 30, "e1"),
+				event(1, 10, 600, 660, "e1"),
+				event(1, 10, 700, 701, "e2"),
+			},
+			[]observation{{1, 10, 0, 660, nil, ""}, {1, 10, 700, 1, nil, ""}},
+		},
+		{
+			"rounds the appearance up to the second",
+			[]sighting{{siteID: 1, speciesID: 10, start: at(0), end: at(2).Add(100 * time.Millisecond)}},
+			[]observation{{1, 10, 0, 3, nil, ""}},
+		},
+	}
+
+	batchID := int64(7)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupEvents(tt.sightings, DefaultEventGap, &batchID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d observations, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, o := range got {
+				want := tt.want[i]
+				if o.SiteID != want.site || o.SpeciesID != want.species || !o.Timestamp.Equal(at(want.start)) {
+					t.Errorf("observation %d of species %d at site %d at %v, want species %d at site %d at %v",
+						i, o.SpeciesID, o.SiteID, o.Timestamp, want.species, want.site, at(want.start))
+				}
+				if *o.AppearanceStart != 0 || *o.AppearanceEnd != want.appearanceEnd {
+					t.Errorf("observation %d appears from %d to %d, want 0 to %d", i, *o.AppearanceStart, *o.AppearanceEnd, want.appearanceEnd)
+				}
+				checkEqual(t, "confidence", o.Confidence, want.confidence)
+				var file *string
+				if want.file != "" {
+					file = &want.file
+				}
+				checkEqual(t, "file", o.File, file)
+				if o.ImportBatchID != &batchID {
+					t.Errorf("observation %d is not of the batch", i)
+				}
+			}
+		})
+	}
+}
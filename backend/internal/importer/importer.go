@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"cmp"
 	"context"
 	"encoding/csv"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/biomonash/nillumbik/internal/db"
 	"github.com/jackc/pgx/v5"
@@ -15,22 +17,280 @@ import (
 
 const BATCH_SIZE = 1000
 
-func ImportCSV(ctx context.Context, q *db.Queries, filename string) error {
+// invalidRow marks an error caused by the content of a row rather than by
+// the database, so validation can carry on with the next row.
+type invalidRow struct {
+	err error
+}
+
+func (e invalidRow) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidRow) Unwrap() error {
+	return e.err
+}
+
+// skippedRow marks a row that is left out of an import on purpose, such as
+// a detection below the minimum confidence. Skipped rows are counted in the
+// report by reason and do not stop an import.
+type skippedRow struct {
+	reason string
+}
+
+func (e skippedRow) Error() string {
+	return e.reason
+}
+
+// rowImporter resolves the site, species and observation of each CSV row.
+// In dry-run mode nothing is written: new sites and species are only cached,
+// so later rows resolve against them, and recorded in the report.
+type rowImporter struct {
+	q       db.Querier
+	cache   *ImporterCache
+	columns columns
+	dryRun  bool
+	report  *Report
+	batchID *int64
+	// seen holds the row number of each observation read so far.
+	seen map[observationKey]int
+}
+
+// observationKey identifies an observation the way the natural key of the
+// observations table does: by site, species, timestamp, method and file.
+type observationKey struct {
+	site      string
+	species   string
+	timestamp time.Time
+	method    db.ObservationMethod
+	file      string
+}
+
+func newRowImporter(q db.Querier, cols columns, report *Report) *rowImporter {
+	r := &rowImporter{
+		q:       q,
+		cache:   NewCache(q),
+		columns: cols,
+		dryRun:  report.DryRun,
+		report:  report,
+		seen:    make(map[observationKey]int),
+	}
+	if report.BatchID != 0 {
+		r.batchID = &report.BatchID
+	}
+	return r
+}
+
+// site returns the site with the code, creating it from the params parse
+// returns when it does not exist yet.
+func (r *rowImporter) site(ctx context.Context, siteCode string, parse func() (db.CreateSiteParams, error)) (db.Site, error) {
+	// Check if site exists
+	site, err := r.cache.GetSite(ctx, siteCode)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return site, err
+	}
+
+	// Site does not exist, insert and get full site
+	siteParam, err := parse()
+	if err != nil {
+		return db.Site{}, invalidRow{fmt.Errorf("parse site failed: %w", err)}
+	}
+	siteParam.ImportBatchID = r.batchID
+	if r.dryRun {
+		site = db.Site{
+			Code:     siteParam.Code,
+			Block:    siteParam.Block,
+			Name:     siteParam.Name,
+			Location: siteParam.Location,
+			Tenure:   siteParam.Tenure,
+			Forest:   siteParam.Forest,
+		}
+	} else {
+		site, err = r.q.CreateSite(ctx, siteParam)
+		if err != nil {
+			return db.Site{}, fmt.Errorf("insert site failed: %w", err)
+		}
+	}
+	r.cache.AddSite(site)
+	r.report.NewSites = append(r.report.NewSites, site.Code)
+	return site, nil
+}
+
+// species returns the species with the scientific name, creating it from the
+// params parse returns when it does not exist yet.
+func (r *rowImporter) species(ctx context.Context, scientific string, parse func() (db.CreateSpeciesParams, error)) (db.Species, error) {
+	species, err := r.cache.GetSpecies(ctx, scientific)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return species, err
+	}
+
+	speciesParam, err := parse()
+	if errors.As(err, &skippedRow{}) {
+		return db.Species{}, err
+	}
+	if err != nil {
+		return db.Species{}, invalidRow{fmt.Errorf("failed to parse species: %w", err)}
+	}
+	speciesParam.ImportBatchID = r.batchID
+	if r.dryRun {
+		species = db.Species{
+			ScientificName: speciesParam.ScientificName,
+			CommonName:     speciesParam.CommonName,
+			Native:         speciesParam.Native,
+			Taxa:           speciesParam.Taxa,
+			Indicator:      speciesParam.Indicator,
+			Reportable:     speciesParam.Reportable,
+		}
+	} else {
+		species, err = r.q.CreateSpecies(ctx, speciesParam)
+		if err != nil {
+			return db.Species{}, fmt.Errorf("insert species failed: %w\n%v", err, speciesParam)
+		}
+	}
+	r.cache.AddSpecies(species)
+	r.report.NewSpecies = append(r.report.NewSpecies, species.ScientificName)
+	return species, nil
+}
+
+// knownSite returns the site with the code. Rows of sites that are not in the
+// database are skipped.
+func (r *rowImporter) knownSite(ctx context.Context, siteCode string) (db.Site, error) {
+	site, err := r.cache.GetSite(ctx, siteCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.Site{}, skippedRow{"unknown site " + siteCode}
+	}
+	return site, err
+}
+
+// knownSpecies returns the species with the scientific name or, failing
+// that, the common name. Rows of species that are not in the database are
+// skipped.
+func (r *rowImporter) knownSpecies(ctx context.Context, scientific, common string) (db.Species, error) {
+	if scientific != "" {
+		species, err := r.cache.GetSpecies(ctx, scientific)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return species, err
+		}
+	}
+	if common != "" {
+		species, err := r.cache.GetSpeciesByCommonName(ctx, common)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return species, err
+		}
+	}
+	return db.Species{}, skippedRow{"unknown species " + cmp.Or(scientific, common)}
+}
+
+// parseRow resolves the site and species of a row, creating them unless in
+// dry-run mode, and parses the observation it describes. A row of the same
+// observation as an earlier row is reported as a duplicate.
+func (r *rowImporter) parseRow(ctx context.Context, i int, values []string) (db.CreateObservationsParams, error) {
+	if len(values) < r.columns.width {
+		return db.CreateObservationsParams{}, invalidRow{fmt.Errorf("unexpected column count %d, want %d", len(values), r.columns.width)}
+	}
+	row := record{values: values, columns: r.columns}
+
+	// --- Parse site ---
+	site, err := r.site(ctx, strings.TrimSpace(row.get(FieldSiteCode)), func() (db.CreateSiteParams, error) {
+		return parseSite(i, row)
+	})
+	if err != nil {
+		return db.CreateObservationsParams{}, err
+	}
+
+	// --- Parse species ---
+	species, err := r.species(ctx, row.get(FieldScientificName), func() (db.CreateSpeciesParams, error) {
+		return parseSpecies(i, row)
+	})
+	if err != nil {
+		return db.CreateObservationsParams{}, err
+	}
+
+	// --- Parse observation ---
+	params, err := parseObservation(i, row, site.ID, species.ID)
+	if err != nil {
+		return db.CreateObservationsParams{}, invalidRow{fmt.Errorf("failed to parse observation: %w", err)}
+	}
+	params.ImportBatchID = r.batchID
+
+	key := observationKey{site: site.Code, species: species.ScientificName, timestamp: params.Timestamp.UTC(), method: params.Method}
+	if params.File != nil {
+		key.file = *params.File
+	}
+	if first, ok := r.seen[key]; ok {
+		r.report.duplicate(i+1, first)
+	} else {
+		r.seen[key] = i + 1
+	}
+	return params, nil
+}
+
+func newCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	// Column count is checked per row against the header
+	reader.FieldsPerRecord = -1
+	return reader
+}
+
+// readHeader resolves the columns of the CSV file from its header, so that
+// a file missing required columns is rejected before anything is imported.
+func readHeader(filename string, opts Options) ([]string, columns, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("failed to open CSV: %w", err)
+		return nil, columns{}, fmt.Errorf("failed to open CSV: %w", err)
 	}
 	defer file.Close()
 
-	cache := NewCache(q)
+	header, err := newCSVReader(file).Read()
+	if err != nil {
+		return nil, columns{}, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	cols, err := opts.resolveColumns(header)
+	return header, cols, err
+}
 
-	reader := csv.NewReader(file)
-	reader.TrimLeadingSpace = true
-	// records, err := reader.ReadAll()
+// Conn is a database connection that can also start transactions, e.g.
+// *pgxpool.Pool.
+type Conn interface {
+	db.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// ImportCSV imports the CSV file as a new import batch, inside a single
+// transaction. Either every row is committed or, when any row fails or ctx
+// is cancelled, nothing is and the batch is marked as failed. Rows whose
+// observation already exists are skipped or updated according to
+// opts.OnConflict, so the same file can safely be imported again. The
+// returned report describes what was committed, or how far the import got
+// before it was rolled back.
+func ImportCSV(ctx context.Context, conn Conn, filename string, opts Options) (*Report, error) {
+	if !opts.OnConflict.Valid() {
+		return nil, fmt.Errorf("unknown conflict mode: %q", opts.OnConflict)
+	}
+	_, cols, err := readHeader(filename, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return importBatch(ctx, conn, filename, []string{filename}, func(ctx context.Context, q *db.Queries, report *Report) error {
+		return importCSV(ctx, q, report, cols, opts)
+	})
+}
+
+func importCSV(ctx context.Context, q *db.Queries, report *Report, cols columns, opts Options) error {
+	file, err := os.Open(report.File)
+	if err != nil {
+		return fmt.Errorf("failed to open CSV: %w", err)
+	}
+	defer file.Close()
 
-	const minCols = 23 // adjust if your CSV has more/less columns
+	report.ColumnsByPosition = cols.positional
+	rows := newRowImporter(q, cols, report)
+	observations := newObservationWriter(q, opts.OnConflict, report)
+
+	reader := newCSVReader(file)
 
-	batch := make([]db.CreateObservationsParams, 0, BATCH_SIZE)
 	i := 0
 	for {
 		row, err := reader.Read()
@@ -45,72 +305,18 @@ func ImportCSV(ctx context.Context, q *db.Queries, filename string) error {
 			continue // skip header
 		}
 
-		if len(row) < minCols {
-			return fmt.Errorf("row %d: unexpected column count %d, want >= %d", i+1, len(row), minCols)
-		}
-
-		// --- Parse site ---
-		siteCode := strings.TrimSpace(row[1])
-		// Check if site exists
-		site, err := cache.GetSite(ctx, siteCode)
-		if errors.Is(err, pgx.ErrNoRows) {
-			// Site does not exist, insert and get full site
-			siteParam, err := parseSite(i, row)
-			if err != nil {
-				return fmt.Errorf("parse site failed: %w", err)
-			}
-			site, err = q.CreateSite(ctx, siteParam)
-			if err != nil {
-				return fmt.Errorf("insert site failed: %w", err)
-			}
-			cache.AddSite(site)
-		} else if err != nil {
-			return fmt.Errorf("failed to get site id by code: %w", err)
-		}
-
-		scientific := row[14]
-		// --- Parse species ---
-		species, err := cache.GetSpecies(ctx, scientific)
-
-		if errors.Is(err, pgx.ErrNoRows) {
-			speciesParam, err := parseSpecies(i, row)
-			if err != nil {
-				return fmt.Errorf("Failed to parse species: %w", err)
-			}
-			species, err = q.CreateSpecies(ctx, speciesParam)
-			if err != nil {
-				return fmt.Errorf("Row: %d insert species failed: %w\n%v", i, err, speciesParam)
-			}
-			cache.AddSpecies(species)
-		} else if err != nil {
-			panic(err)
-		}
-
-		// --- Parse observation ---
-		params, err := parseObservation(i, row, site.ID, species.ID)
+		report.TotalRows++
+		params, err := rows.parseRow(ctx, i, row)
 		if err != nil {
-			return fmt.Errorf("Row %d: failed to parse observation: %w", i, err)
+			report.reject(i+1, row, err)
+			return fmt.Errorf("row %d: %w", i+1, err)
 		}
-		batch = append(batch, params)
-
-		if len(batch) == BATCH_SIZE {
-			count, err := q.CreateObservations(ctx, batch)
-			if err != nil {
-				return fmt.Errorf("Failed to insert observations: %w", err)
-			}
-			fmt.Printf("Successfully inserted %d observations to row %d\n", count, i)
-			batch = make([]db.CreateObservationsParams, 0, BATCH_SIZE)
+		report.ValidRows++
+		if err := observations.add(ctx, params); err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
 		}
 		i++
 	}
 
-	if len(batch) != 0 {
-		count, err := q.CreateObservations(ctx, batch)
-		if err != nil {
-			return fmt.Errorf("Failed to insert observations: %w", err)
-		}
-		fmt.Printf("Successfully inserted %d observations to row %d\n", count, i)
-	}
-
-	return nil
+	return observations.flush(ctx)
 }
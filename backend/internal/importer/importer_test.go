@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+func TestImportCSV(t *testing.T) {
+	valid := []string{
+		testCSVHeader,
+		"SG01,5-Oct-24,6:30 AM,Camera,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird",
+		"SG02,5-Oct-24,9:00 PM,Audio,Vombatus ursinus,Common Wombat,Dry,N,Native,Private,N,2,Mammal",
+	}
+	tests := []struct {
+		name    string
+		rows    []string
+		failOn  string
+		wantErr bool
+	}{
+		{"committed", valid, "", false},
+		{"invalid row", append(valid,
+			"SG03,6-Oct-24,8:15 PM,Audio,Vombatus ursinus,Common Wombat,Dry,N,Native,Leased,N,2,Mammal",
+		), "", true},
+		{"failed insert", valid, "UpsertObservations", true},
+		{"failed finish", valid, "FinishImportBatch", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newMemDB()
+			conn.failOn = tt.failOn
+			report, err := ImportCSV(context.Background(), conn, writeTestCSV(t, tt.rows...), Options{OnConflict: ConflictUpdate})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want one: %v", err, tt.wantErr)
+			}
+			if tt.failOn != "" && !errors.Is(err, errMemFailure) {
+				t.Errorf("err = %v, want the failure of %s", err, tt.failOn)
+			}
+
+			tables := conn.tables
+			if len(tables.batches) != 1 {
+				t.Fatalf("got %d import batches, want 1", len(tables.batches))
+			}
+			batch := tables.batches[0]
+			if report.BatchID != batch.ID || report.Committed == tt.wantErr {
+				t.Errorf("report of batch %d committed: %v, want batch %d committed: %v",
+					report.BatchID, report.Committed, batch.ID, !tt.wantErr)
+			}
+
+			if tt.wantErr {
+				// Nothing but the failed batch is left
+				if batch.Status != db.ImportStatusFailed && tt.failOn != "FinishImportBatch" {
+					t.Errorf("batch status = %s, want %s", batch.Status, db.ImportStatusFailed)
+				}
+				if len(tables.sites) != 0 || len(tables.species) != 0 || len(tables.observations) != 0 {
+					t.Errorf("rolled back import left %d sites, %d species and %d observations",
+						len(tables.sites), len(tables.species), len(tables.observations))
+				}
+				return
+			}
+			if batch.Status != db.ImportStatusCompleted || batch.RowCount != 2 {
+				t.Errorf("batch is %s with %d rows, want %s with 2", batch.Status, batch.RowCount, db.ImportStatusCompleted)
+			}
+			if len(tables.sites) != 2 || len(tables.species) != 2 || len(tables.observations) != 2 {
+				t.Errorf("import left %d sites, %d species and %d observations, want 2 of each",
+					len(tables.sites), len(tables.species), len(tables.observations))
+			}
+			for _, o := range tables.observations {
+				if o.ImportBatchID == nil || *o.ImportBatchID != batch.ID {
+					t.Errorf("observation %d is from batch %v, want %d", o.ID, deref(o.ImportBatchID), batch.ID)
+				}
+			}
+		})
+	}
+}
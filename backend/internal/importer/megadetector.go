@@ -0,0 +1,210 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// megadetectorOutput is a MegaDetector batch output file, with the species
+// classifications of a classifier run on its detections.
+type megadetectorOutput struct {
+	Images                   []megadetectorImage `json:"images"`
+	DetectionCategories      map[string]string   `json:"detection_categories"`
+	ClassificationCategories map[string]string   `json:"classification_categories"`
+}
+
+type megadetectorImage struct {
+	File       string                  `json:"file"`
+	Failure    string                  `json:"failure"`
+	Detections []megadetectorDetection `json:"detections"`
+}
+
+type megadetectorDetection struct {
+	Category        string                       `json:"category"`
+	Conf            float32                      `json:"conf"`
+	Classifications []megadetectorClassification `json:"classifications"`
+}
+
+// megadetectorClassification is a [category, confidence] pair.
+type megadetectorClassification struct {
+	Category string
+	Conf     float32
+}
+
+func (c *megadetectorClassification) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("classification is not a [category, confidence] pair: %s", data)
+	}
+	if err := json.Unmarshal(pair[0], &c.Category); err != nil {
+		return err
+	}
+	return json.Unmarshal(pair[1], &c.Conf)
+}
+
+// ImportMegaDetector imports the classified animal detections of a
+// MegaDetector batch output file as camera observations in one import batch.
+// The site and time of each image come from its path through
+// opts.FilePattern, and images of the same species at a site less than
+// opts.EventGap apart are grouped into one observation. Detections or
+// classifications scored below opts.MinConfidence, and images of sites and
+// species that are not in the database, are skipped.
+func ImportMegaDetector(ctx context.Context, conn Conn, filename string, opts Options) (*Report, error) {
+	if !opts.OnConflict.Valid() {
+		return nil, fmt.Errorf("unknown conflict mode: %q", opts.OnConflict)
+	}
+	output, err := readMegaDetector(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return importBatch(ctx, conn, filename, []string{filename}, func(ctx context.Context, q *db.Queries, report *Report) error {
+		observations := newObservationWriter(q, opts.OnConflict, report)
+		if err := importImages(ctx, q, output, report, opts, observations.add); err != nil {
+			return err
+		}
+		return observations.flush(ctx)
+	})
+}
+
+// ValidateMegaDetector checks the images of a MegaDetector output file the
+// same way ImportMegaDetector does, without writing to the database.
+func ValidateMegaDetector(ctx context.Context, q db.Querier, filename string, opts Options) (*Report, error) {
+	output, err := readMegaDetector(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(filename, true)
+	if err := importImages(ctx, q, output, report, opts, nil); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func readMegaDetector(filename string) (*megadetectorOutput, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MegaDetector output: %w", err)
+	}
+	var output megadetectorOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse MegaDetector output: %w", err)
+	}
+	if len(output.ClassificationCategories) == 0 {
+		return nil, fmt.Errorf("%s has no species classifications, run a classifier on the detections first", filename)
+	}
+	return &output, nil
+}
+
+func importImages(ctx context.Context, q db.Querier, output *megadetectorOutput, report *Report, opts Options, add func(context.Context, db.CreateObservationsParams) error) error {
+	report.header = []string{"file"}
+	rows := newRowImporter(q, columns{}, report)
+
+	var sightings []sighting
+	for i, image := range output.Images {
+		report.TotalRows++
+		found, err := rows.parseImage(ctx, output, image, opts)
+		valid, err := report.check(i+1, []string{image.File}, err)
+		if err != nil {
+			return fmt.Errorf("image %s: %w", image.File, err)
+		}
+		if valid {
+			sightings = append(sightings, found...)
+		}
+	}
+
+	if add == nil {
+		return nil
+	}
+	for _, params := range groupEvents(sightings, opts.eventGap(), rows.batchID) {
+		if err := add(ctx, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseImage returns a sighting for each species classified in the image.
+func (r *rowImporter) parseImage(ctx context.Context, output *megadetectorOutput, image megadetectorImage, opts Options) ([]sighting, error) {
+	if image.Failure != "" {
+		return nil, skippedRow{"image failed: " + image.Failure}
+	}
+
+	// Best classification of each animal detection, by species name
+	best := make(map[string]float32)
+	for _, d := range image.Detections {
+		if output.DetectionCategories[d.Category] != "animal" || d.Conf < opts.MinConfidence {
+			continue
+		}
+		var top *megadetectorClassification
+		for _, c := range d.Classifications {
+			if top == nil || c.Conf > top.Conf {
+				top = &c
+			}
+		}
+		if top == nil || top.Conf < opts.MinConfidence {
+			continue
+		}
+		name := output.ClassificationCategories[top.Category]
+		if top.Conf > best[name] {
+			best[name] = top.Conf
+		}
+	}
+	if len(best) == 0 {
+		return nil, skippedRow{"no classified animal"}
+	}
+
+	siteCode, timestamp, err := opts.filePattern().Match(image.File)
+	if err != nil {
+		return nil, invalidRow{err}
+	}
+	site, err := r.knownSite(ctx, siteCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var sightings []sighting
+	for name, confidence := range best {
+		scientific, common := classificationName(name)
+		species, err := r.knownSpecies(ctx, scientific, common)
+		if errors.As(err, &skippedRow{}) && len(best) > 1 {
+			// Keep the other species of the image
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sightings = append(sightings, sighting{
+			siteID:     site.ID,
+			speciesID:  species.ID,
+			start:      timestamp,
+			end:        timestamp,
+			confidence: &confidence,
+			file:       image.File,
+		})
+	}
+	if len(sightings) == 0 {
+		return nil, skippedRow{"no known species"}
+	}
+	return sightings, nil
+}
+
+// classificationName splits a classifier label into the names to look the
+// species up by. SpeciesNet labels are "id;class;order;family;genus;species;
+// common name", other classifiers use a single name.
+func classificationName(label string) (scientific, common string) {
+	if parts := strings.Split(label, ";"); len(parts) == 7 {
+		return strings.TrimSpace(parts[4] + " " + parts[5]), strings.TrimSpace(parts[6])
+	}
+	return label, label
+}
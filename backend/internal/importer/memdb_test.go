@@ -0,0 +1,402 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// memTables are the tables an import writes to.
+type memTables struct {
+	batches      []db.ImportBatch
+	sites        []db.Site
+	species      []db.Species
+	observations []db.Observation
+	revisions    []db.ObservationRevision
+	nextID       int64
+}
+
+func (t *memTables) clone() *memTables {
+	c := *t
+	c.batches = slices.Clone(t.batches)
+	c.sites = slices.Clone(t.sites)
+	c.species = slices.Clone(t.species)
+	c.observations = slices.Clone(t.observations)
+	c.revisions = slices.Clone(t.revisions)
+	return &c
+}
+
+func (t *memTables) id() int64 {
+	t.nextID++
+	return t.nextID
+}
+
+// memDB is an in-memory stand-in for PostgreSQL that answers the queries of
+// an import by their sqlc name. Transactions work on a copy of the tables,
+// which replaces them on commit.
+type memDB struct {
+	tables *memTables
+	// failOn makes the query with this name fail.
+	failOn string
+}
+
+func newMemDB() *memDB {
+	return &memDB{tables: &memTables{}}
+}
+
+var errMemFailure = errors.New("query failed on purpose")
+
+func (m *memDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &memTx{memDB: &memDB{tables: m.tables.clone(), failOn: m.failOn}, parent: m}, nil
+}
+
+func (m *memDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	n, err := m.exec(queryName(sql), args)
+	return pgconn.NewCommandTag(fmt.Sprintf("DELETE %d", n)), err
+}
+
+func (m *memDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, fmt.Errorf("memDB: unexpected query %s", queryName(sql))
+}
+
+func (m *memDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return m.queryRow(queryName(sql), args)
+}
+
+func (m *memDB) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, fmt.Errorf("memDB: unexpected copy to %v", tableName)
+}
+
+func (m *memDB) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	results := &memBatchResults{}
+	for _, q := range b.QueuedQueries {
+		results.rows = append(results.rows, m.queryRow(queryName(q.SQL), q.Arguments))
+	}
+	return results
+}
+
+// laterRevision returns the next revision of the same observation, if any.
+func (t *memTables) laterRevision(r db.ObservationRevision) *db.ObservationRevision {
+	var later *db.ObservationRevision
+	for i, n := range t.revisions {
+		if n.ObservationID == r.ObservationID && n.ID > r.ID && (later == nil || n.ID < later.ID) {
+			later = &t.revisions[i]
+		}
+	}
+	return later
+}
+
+func fromBatch(batchID *int64, id any) bool {
+	return batchID != nil && *batchID == id.(int64)
+}
+
+// deleteRows deletes the rows matching del and returns how many it deleted.
+func deleteRows[T any](rows *[]T, del func(T) bool) int64 {
+	n := len(*rows)
+	*rows = slices.DeleteFunc(*rows, del)
+	return int64(n - len(*rows))
+}
+
+// queryName returns the name sqlc gives the query in its first line.
+func queryName(sql string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(sql, "-- name: "), " ")
+	return name
+}
+
+func (m *memDB) exec(name string, args []any) (int64, error) {
+	if name == m.failOn {
+		return 0, errMemFailure
+	}
+	t := m.tables
+	switch name {
+	case "SetImportBatchStatus":
+		for i, b := range t.batches {
+			if b.ID == args[0].(int64) {
+				t.batches[i].Status = args[1].(db.ImportStatus)
+				return 1, nil
+			}
+		}
+		return 0, nil
+	case "DeleteObservationsByImportBatch":
+		n := deleteRows(&t.observations, func(o db.Observation) bool {
+			return fromBatch(o.ImportBatchID, args[0])
+		})
+		deleteRows(&t.revisions, func(r db.ObservationRevision) bool {
+			return !slices.ContainsFunc(t.observations, func(o db.Observation) bool { return o.ID == r.ObservationID })
+		})
+		return n, nil
+	case "RestoreObservationsByImportBatch":
+		var n int64
+		for _, r := range t.revisions {
+			if r.ImportBatchID != args[0].(int64) || t.laterRevision(r) != nil {
+				continue
+			}
+			for i, o := range t.observations {
+				if o.ID == r.ObservationID {
+					t.observations[i].AppearanceStart, t.observations[i].AppearanceEnd = r.AppearanceStart, r.AppearanceEnd
+					t.observations[i].Temperature, t.observations[i].Narrative, t.observations[i].Confidence = r.Temperature, r.Narrative, r.Confidence
+					n++
+				}
+			}
+		}
+		return n, nil
+	case "PassOnObservationRevisionsByImportBatch":
+		for _, r := range t.revisions {
+			if later := t.laterRevision(r); r.ImportBatchID == args[0].(int64) && later != nil {
+				later.AppearanceStart, later.AppearanceEnd = r.AppearanceStart, r.AppearanceEnd
+				later.Temperature, later.Narrative, later.Confidence = r.Temperature, r.Narrative, r.Confidence
+			}
+		}
+		return 0, nil
+	case "DeleteObservationRevisionsByImportBatch":
+		return deleteRows(&t.revisions, func(r db.ObservationRevision) bool {
+			return r.ImportBatchID == args[0].(int64)
+		}), nil
+	case "DeleteSpeciesByImportBatch":
+		return deleteRows(&t.species, func(s db.Species) bool {
+			return fromBatch(s.ImportBatchID, args[0]) && !slices.ContainsFunc(t.observations, func(o db.Observation) bool {
+				return o.SpeciesID == s.ID
+			})
+		}), nil
+	case "DeleteSitesByImportBatch":
+		return deleteRows(&t.sites, func(s db.Site) bool {
+			return fromBatch(s.ImportBatchID, args[0]) && !slices.ContainsFunc(t.observations, func(o db.Observation) bool {
+				return o.SiteID == s.ID
+			})
+		}), nil
+	case "DeleteDeploymentsByImportBatch":
+		return 0, nil
+	}
+	return 0, fmt.Errorf("memDB: unexpected exec %s", name)
+}
+
+func (m *memDB) queryRow(name string, args []any) memRow {
+	if name == m.failOn {
+		return memRow{err: errMemFailure}
+	}
+	t := m.tables
+	switch name {
+	case "CreateImportBatch":
+		b := db.ImportBatch{
+			ID:        t.id(),
+			FileName:  args[0].(string),
+			Checksum:  args[1].(string),
+			StartedAt: time.Now(),
+			Status:    db.ImportStatusRunning,
+		}
+		t.batches = append(t.batches, b)
+		return rowOf(b)
+	case "FinishImportBatch", "GetImportBatch":
+		for i, b := range t.batches {
+			if b.ID != args[0].(int64) {
+				continue
+			}
+			if name == "FinishImportBatch" {
+				b.Status = args[1].(db.ImportStatus)
+				b.RowCount = args[2].(int32)
+				b.FinishedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+				t.batches[i] = b
+			}
+			return rowOf(b)
+		}
+	case "GetSiteByCode":
+		for _, s := range t.sites {
+			if s.Code == args[0].(string) {
+				return rowOf(s)
+			}
+		}
+	case "CreateSite":
+		s := db.Site{
+			ID:            t.id(),
+			Code:          args[0].(string),
+			Block:         args[1].(int32),
+			Name:          args[2].(*string),
+			Location:      args[3].(*string),
+			Tenure:        args[4].(db.TenureType),
+			Forest:        args[5].(db.ForestType),
+			ImportBatchID: args[6].(*int64),
+		}
+		t.sites = append(t.sites, s)
+		return rowOf(s)
+	case "GetSpeciesByScientificName":
+		for _, s := range t.species {
+			if strings.EqualFold(s.ScientificName, args[0].(string)) {
+				return rowOf(s)
+			}
+		}
+	case "CreateSpecies":
+		s := db.Species{
+			ID:             t.id(),
+			ScientificName: args[0].(string),
+			CommonName:     args[1].(string),
+			Native:         args[2].(bool),
+			Taxa:           args[3].(db.Taxa),
+			Indicator:      args[4].(bool),
+			Reportable:     args[5].(bool),
+			ImportBatchID:  args[6].(*int64),
+		}
+		t.species = append(t.species, s)
+		return rowOf(s)
+	case "UpsertObservations":
+		return m.upsertObservation(args)
+	default:
+		return memRow{err: fmt.Errorf("memDB: unexpected query %s", name)}
+	}
+	return memRow{err: pgx.ErrNoRows}
+}
+
+// upsertObservation inserts the observation unless one with the same natural
+// key exists, which is then updated when asked to and its values differ. The
+// first update of an import batch keeps the previous values as a revision.
+func (m *memDB) upsertObservation(args []any) memRow {
+	t := m.tables
+	o := db.Observation{
+		SiteID:          args[0].(int64),
+		SpeciesID:       args[1].(int64),
+		Timestamp:       args[2].(time.Time),
+		Method:          args[3].(db.ObservationMethod),
+		File:            args[4].(*string),
+		AppearanceStart: args[5].(*int32),
+		AppearanceEnd:   args[6].(*int32),
+		Temperature:     args[7].(*int32),
+		Narrative:       args[8].(*string),
+		Confidence:      args[9].(*float32),
+		ImportBatchID:   args[10].(*int64),
+	}
+	updateExisting := args[11].(bool)
+
+	for i, e := range t.observations {
+		if e.SiteID != o.SiteID || e.SpeciesID != o.SpeciesID || !e.Timestamp.Equal(o.Timestamp) ||
+			e.Method != o.Method || deref(e.File) != deref(o.File) {
+			continue
+		}
+		changed := o
+		changed.ID, changed.ImportBatchID = e.ID, e.ImportBatchID
+		if !updateExisting || reflect.DeepEqual(changed, e) {
+			return memRow{err: pgx.ErrNoRows}
+		}
+		t.observations[i] = changed
+		batchID := o.ImportBatchID
+		if batchID != nil && !fromBatch(e.ImportBatchID, *batchID) && !slices.ContainsFunc(t.revisions, func(r db.ObservationRevision) bool {
+			return r.ImportBatchID == *batchID && r.ObservationID == e.ID
+		}) {
+			t.revisions = append(t.revisions, db.ObservationRevision{
+				ID:              t.id(),
+				ObservationID:   e.ID,
+				ImportBatchID:   *batchID,
+				AppearanceStart: e.AppearanceStart,
+				AppearanceEnd:   e.AppearanceEnd,
+				Temperature:     e.Temperature,
+				Narrative:       e.Narrative,
+				Confidence:      e.Confidence,
+			})
+		}
+		return memRow{values: []any{e.ID, false}}
+	}
+	o.ID = t.id()
+	t.observations = append(t.observations, o)
+	return memRow{values: []any{o.ID, true}}
+}
+
+// memTx is a transaction of a memDB.
+type memTx struct {
+	pgx.Tx
+	*memDB
+	parent *memDB
+	done   bool
+}
+
+func (tx *memTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, errors.New("memDB: unexpected nested transaction")
+}
+
+func (tx *memTx) Commit(ctx context.Context) error {
+	if tx.done {
+		return pgx.ErrTxClosed
+	}
+	tx.done = true
+	tx.parent.tables = tx.tables
+	return nil
+}
+
+func (tx *memTx) Rollback(ctx context.Context) error {
+	tx.done = true
+	return nil
+}
+
+func (tx *memTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return tx.memDB.Exec(ctx, sql, args...)
+}
+
+func (tx *memTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return tx.memDB.Query(ctx, sql, args...)
+}
+
+func (tx *memTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.memDB.QueryRow(ctx, sql, args...)
+}
+
+func (tx *memTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return tx.memDB.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+func (tx *memTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return tx.memDB.SendBatch(ctx, b)
+}
+
+// memRow scans its values in order, or returns its error.
+type memRow struct {
+	values []any
+	err    error
+}
+
+// rowOf returns the fields of a table row in the order sqlc scans them.
+func rowOf(v any) memRow {
+	rv := reflect.ValueOf(v)
+	values := make([]any, rv.NumField())
+	for i := range values {
+		values[i] = rv.Field(i).Interface()
+	}
+	return memRow{values: values}
+}
+
+func (r memRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+	return nil
+}
+
+type memBatchResults struct {
+	rows []memRow
+}
+
+func (r *memBatchResults) Exec() (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("memDB: unexpected batch exec")
+}
+
+func (r *memBatchResults) Query() (pgx.Rows, error) {
+	return nil, errors.New("memDB: unexpected batch query")
+}
+
+func (r *memBatchResults) QueryRow() pgx.Row {
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row
+}
+
+func (r *memBatchResults) Close() error {
+	return nil
+}
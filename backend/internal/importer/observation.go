@@ -8,37 +8,42 @@ import (
 	"github.com/biomonash/nillumbik/internal/db"
 )
 
-func parseObservation(i int, row []string, siteID, speciesID int64) (param db.CreateObservationsParams, err error) {
-	timestamp, err := parseTimestamp(row[4], row[5])
+func parseObservation(i int, row record, siteID, speciesID int64) (param db.CreateObservationsParams, err error) {
+	timestamp, err := parseTimestamp(row.get(FieldDate), row.get(FieldTime))
 	if err != nil {
 		// leave timestamp NULL if you prefer; or set fallback if schema requires NOT NULL
 		err = fmt.Errorf("failed to parse timestamp: %w", err)
 		return
 	}
 
-	method := db.ObservationMethod(strings.ToLower(row[6]))
+	method := db.ObservationMethod(strings.ToLower(row.get(FieldMethod)))
 	if !method.Valid() {
-		err = fmt.Errorf("unknown observation method: %s", row[6])
+		err = fmt.Errorf("unknown observation method: %s", row.get(FieldMethod))
 		return
 	}
 
-	start := parseOptionalInt(row[8])
-	end := parseOptionalInt(row[9])
+	start := parseOptionalInt(row.get(FieldAppearanceStart))
+	end := parseOptionalInt(row.get(FieldAppearanceEnd))
 
-	temp := parseOptionalInt(row[10])
+	temp := parseOptionalInt(row.get(FieldTemperature))
 
 	var narrativePtr *string
-	if row[11] != "" {
-		narrativePtr = &row[11]
+	if narrative := row.get(FieldNarrative); narrative != "" {
+		narrativePtr = &narrative
 	}
 
 	var confidencePtr *float32
-	if row[13] != "" {
-		c, _ := strconv.ParseFloat(row[13], 32)
+	if confidence := row.get(FieldConfidence); confidence != "" {
+		c, _ := strconv.ParseFloat(confidence, 32)
 		conf := float32(c)
 		confidencePtr = &conf
 	}
 
+	var filePtr *string
+	if file := strings.TrimSpace(row.get(FieldFile)); file != "" {
+		filePtr = &file
+	}
+
 	return db.CreateObservationsParams{
 		SiteID:          siteID,
 		SpeciesID:       speciesID,
@@ -49,5 +54,6 @@ func parseObservation(i int, row []string, siteID, speciesID int64) (param db.Cr
 		Temperature:     temp,
 		Narrative:       narrativePtr,
 		Confidence:      confidencePtr,
+		File:            filePtr,
 	}, nil
 }
@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/biomonash/nillumbik/internal/config"
+)
+
+// DefaultFilePattern matches the file names recorders and camera renaming
+// tools give by default, e.g. NIL01_20240301_063000.wav.
+const DefaultFilePattern = `(?P<site>[A-Za-z0-9-]+)_(?P<date>\d{8})_(?P<time>\d{6})`
+
+// FilePattern finds the site a recording or image was taken at, and when it
+// started, in its path. It is a regular expression with the named groups
+// site, date and time, e.g. `(?P<site>[^/]+)/(?P<date>\d{8})_(?P<time>\d{6})`
+// for files kept in one directory per site.
+type FilePattern struct {
+	re *regexp.Regexp
+}
+
+// NewFilePattern compiles a file pattern, checking it has the site, date and
+// time groups.
+func NewFilePattern(expr string) (*FilePattern, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern: %w", err)
+	}
+	for _, group := range []string{"site", "date", "time"} {
+		if re.SubexpIndex(group) < 0 {
+			return nil, fmt.Errorf("file pattern has no (?P<%s>...) group", group)
+		}
+	}
+	return &FilePattern{re: re}, nil
+}
+
+var defaultFilePattern, _ = NewFilePattern(DefaultFilePattern)
+
+func (p *FilePattern) String() string {
+	return p.re.String()
+}
+
+// Match returns the site code and start time in the path. When the pattern
+// matches several parts of the path the last one, nearest the file name,
+// wins. Dates are read as YYYYMMDD and times as HHMMSS or HHMM in local
+// time, ignoring any separators.
+func (p *FilePattern) Match(path string) (siteCode string, start time.Time, err error) {
+	matches := p.re.FindAllStringSubmatch(filepath.ToSlash(path), -1)
+	if len(matches) == 0 {
+		err = fmt.Errorf("%s does not match the file pattern %s", path, p.re)
+		return
+	}
+	m := matches[len(matches)-1]
+	siteCode = m[p.re.SubexpIndex("site")]
+	date := digits(m[p.re.SubexpIndex("date")])
+	clock := digits(m[p.re.SubexpIndex("time")])
+
+	layout := "20060102150405"
+	if len(clock) == 4 {
+		layout = "200601021504"
+	}
+	start, err = time.ParseInLocation(layout, date+clock, config.TIMEZONE)
+	if err != nil {
+		err = fmt.Errorf("invalid date or time in %s: %w", path, err)
+	}
+	return
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/config"
+)
+
+func TestFilePatternMatch(t *testing.T) {
+	perSite, err := NewFilePattern(`(?P<site>[^/]+)/(?P<date>\d{4}-\d{2}-\d{2})_(?P<time>\d{2}-\d{2})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(hour, min, sec int) time.Time {
+		return time.Date(2024, 3, 1, hour, min, sec, 0, config.TIMEZONE)
+	}
+
+	tests := []struct {
+		name    string
+		pattern *FilePattern
+		path    string
+		site    string
+		start   time.Time
+		err     string
+	}{
+		{"default", defaultFilePattern, "NIL01_20240301_063000.wav", "NIL01", at(6, 30, 0), ""},
+		{"in a directory", defaultFilePattern, "recordings/2024/NIL-02_20240301_183015.WAV", "NIL-02", at(18, 30, 15), ""},
+		// The file name wins over a directory that also matches
+		{"nearest the file name", defaultFilePattern, "OLD01_20230101_000000/NIL03_20240301_063000.wav", "NIL03", at(6, 30, 0), ""},
+		{"windows path", defaultFilePattern, `D:\SD\NIL04_20240301_063000.wav`, "NIL04", at(6, 30, 0), ""},
+		{"site directory", perSite, "audio/NIL05/2024-03-01_06-30.flac", "NIL05", at(6, 30, 0), ""},
+		{"no match", defaultFilePattern, "recording.wav", "", time.Time{}, "does not match"},
+		{"invalid date", defaultFilePattern, "NIL01_20241301_063000.wav", "", time.Time{}, "invalid date or time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site, start, err := tt.pattern.Match(tt.path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if site != tt.site || !start.Equal(tt.start) {
+				t.Errorf("Match(%q) = %s at %v, want %s at %v", tt.path, site, start, tt.site, tt.start)
+			}
+		})
+	}
+}
+
+func TestNewFilePattern(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{DefaultFilePattern, ""},
+		{`(?P<site>\w+)_(?P<date>\d{8})`, "no (?P<time>...) group"},
+		{`(?P<site>\w+`, "invalid file pattern"},
+	}
+	for _, tt := range tests {
+		_, err := NewFilePattern(tt.expr)
+		if (err != nil) != (tt.err != "") || err != nil && !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("NewFilePattern(%q) = %v, want %q", tt.expr, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// RowError records why a row of the CSV was rejected.
+type RowError struct {
+	Row    int      `json:"row"`
+	Error  string   `json:"error"`
+	Values []string `json:"-"`
+}
+
+// DuplicateRow is a row of the same observation, by site, species,
+// timestamp, method and file, as an earlier row of the file. An import
+// writes the observation once and updates or skips it for the duplicate.
+type DuplicateRow struct {
+	Row      int `json:"row"`
+	FirstRow int `json:"firstRow"`
+}
+
+// Report summarises an import or validation run.
+type Report struct {
+	File         string   `json:"file"`
+	BatchID      int64    `json:"batchId,omitempty"`
+	DryRun       bool     `json:"dryRun"`
+	TotalRows    int      `json:"totalRows"`
+	ValidRows    int      `json:"validRows"`
+	RejectedRows int      `json:"rejectedRows"`
+	NewSites     []string `json:"newSites"`
+	NewSpecies   []string `json:"newSpecies"`
+	Observations int64    `json:"observations"`
+	Updated      int64    `json:"updated"`
+	Unchanged    int64    `json:"unchanged"`
+	// Deployments counts the deployments written, new or updated.
+	Deployments int64      `json:"deployments"`
+	Committed   bool       `json:"committed"`
+	Errors      []RowError `json:"errors"`
+	// Skipped counts the rows left out on purpose, by reason.
+	Skipped map[string]int `json:"skipped,omitempty"`
+	// UnmappedTerms lists the Darwin Core terms of an archive that have no
+	// place in the database and were ignored.
+	UnmappedTerms []string `json:"unmappedTerms,omitempty"`
+	// UnsupportedClasses lists the classes of the occurrences skipped for
+	// being neither birds, mammals nor reptiles.
+	UnsupportedClasses []string `json:"unsupportedClasses,omitempty"`
+	// Duplicates lists the rows repeating an earlier row's observation.
+	Duplicates []DuplicateRow `json:"duplicates,omitempty"`
+	// ColumnsByPosition is set when the CSV columns were read in the survey
+	// spreadsheet's fixed order rather than by header name.
+	ColumnsByPosition bool `json:"columnsByPosition,omitempty"`
+
+	header []string
+}
+
+func newReport(filename string, dryRun bool) *Report {
+	return &Report{
+		File:       filename,
+		DryRun:     dryRun,
+		NewSites:   []string{},
+		NewSpecies: []string{},
+		Errors:     []RowError{},
+	}
+}
+
+func (r *Report) reject(row int, values []string, err error) {
+	r.RejectedRows++
+	r.Errors = append(r.Errors, RowError{
+		Row:    row,
+		Error:  err.Error(),
+		Values: values,
+	})
+}
+
+// check records the outcome of parsing a row: valid rows are counted,
+// skipped rows counted by reason and invalid rows rejected. It returns
+// whether the row is valid, and the error that stops the import if any.
+// Invalid rows only stop an import, a dry run carries on.
+func (r *Report) check(row int, values []string, err error) (bool, error) {
+	switch {
+	case err == nil:
+		r.ValidRows++
+		return true, nil
+	case errors.As(err, &skippedRow{}):
+		r.skip(err.Error())
+		return false, nil
+	case errors.As(err, &invalidRow{}):
+		r.reject(row, values, err)
+		if r.DryRun {
+			return false, nil
+		}
+	}
+	return false, err
+}
+
+func (r *Report) duplicate(row, first int) {
+	r.Duplicates = append(r.Duplicates, DuplicateRow{Row: row, FirstRow: first})
+}
+
+func (r *Report) unsupportedClass(class string) {
+	if !slices.Contains(r.UnsupportedClasses, class) {
+		r.UnsupportedClasses = append(r.UnsupportedClasses, class)
+	}
+}
+
+func (r *Report) skip(reason string) {
+	if r.Skipped == nil {
+		r.Skipped = make(map[string]int)
+	}
+	r.Skipped[reason]++
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteRejectedCSV writes every rejected row with its original values,
+// prefixed by the row number and the reason it was rejected.
+func (r *Report) WriteRejectedCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"row", "error"}, r.header...)); err != nil {
+		return err
+	}
+	for _, e := range r.Errors {
+		record := append([]string{strconv.Itoa(e.Row), e.Error}, e.Values...)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -8,18 +8,18 @@ import (
 	"github.com/biomonash/nillumbik/internal/db"
 )
 
-func parseSite(i int, row []string) (site db.CreateSiteParams, err error) {
-	siteCode := strings.TrimSpace(row[1])
+func parseSite(i int, row record) (site db.CreateSiteParams, err error) {
+	siteCode := strings.TrimSpace(row.get(FieldSiteCode))
 
-	blockInt, err := strconv.Atoi(strings.TrimSpace(row[21]))
+	blockInt, err := strconv.Atoi(strings.TrimSpace(row.get(FieldBlock)))
 	if err != nil {
-		err = fmt.Errorf("row %d: invalid block value %q: %w", i+1, row[21], err)
+		err = fmt.Errorf("invalid block value %q: %w", row.get(FieldBlock), err)
 		return
 	}
 	block := int32(blockInt)
 
-	forest := strings.ToLower(strings.TrimSpace(row[16]))
-	tenure := strings.ToLower(strings.TrimSpace(row[19]))
+	forest := strings.ToLower(strings.TrimSpace(row.get(FieldForest)))
+	tenure := strings.ToLower(strings.TrimSpace(row.get(FieldTenure)))
 
 	tenureEnum := db.TenureType(tenure)
 	if !tenureEnum.Valid() {
@@ -48,3 +48,43 @@ func parseSite(i int, row []string) (site db.CreateSiteParams, err error) {
 		Location: nil, // We won't have the location data
 	}, nil
 }
+
+// siteParams describes a new site, found in a file that does not record its
+// block, tenure or forest, with the defaults.
+func (d SiteDefaults) siteParams(siteCode, name string, location *string) (site db.CreateSiteParams, err error) {
+	if d.Block == nil {
+		err = fmt.Errorf("no block for new site %s", siteCode)
+		return
+	}
+	if !d.Tenure.Valid() {
+		err = fmt.Errorf("unknown tenure type for new site %s: %q", siteCode, d.Tenure)
+		return
+	}
+	if !d.Forest.Valid() {
+		err = fmt.Errorf("unknown forest type for new site %s: %q", siteCode, d.Forest)
+		return
+	}
+
+	return db.CreateSiteParams{
+		Code:     siteCode,
+		Block:    *d.Block,
+		Name:     &name,
+		Location: location,
+		Tenure:   d.Tenure,
+		Forest:   d.Forest,
+	}, nil
+}
+
+// formatLocation stores coordinates as "latitude,longitude", or nil when
+// either is missing.
+func formatLocation(lat, lon string) (*string, error) {
+	if lat == "" || lon == "" {
+		return nil, nil
+	}
+	latF, lonF, err := parseCoords(lat, lon)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coordinates %s, %s: %w", lat, lon, err)
+	}
+	location := strconv.FormatFloat(latF, 'f', -1, 64) + "," + strconv.FormatFloat(lonF, 'f', -1, 64)
+	return &location, nil
+}
@@ -7,11 +7,11 @@ import (
 	"github.com/biomonash/nillumbik/internal/db"
 )
 
-func parseSpecies(i int, row []string) (species db.CreateSpeciesParams, err error) {
-	scientific := row[14]
-	common := row[15]
-	native := strings.ToLower(row[18]) == "native"
-	taxa := strings.ToLower(row[22])
+func parseSpecies(i int, row record) (species db.CreateSpeciesParams, err error) {
+	scientific := row.get(FieldScientificName)
+	common := row.get(FieldCommonName)
+	native := strings.ToLower(row.get(FieldNative)) == "native"
+	taxa := strings.ToLower(row.get(FieldTaxa))
 
 	taxaEnum := db.Taxa(taxa)
 	if !taxaEnum.Valid() {
@@ -20,8 +20,8 @@ func parseSpecies(i int, row []string) (species db.CreateSpeciesParams, err erro
 	}
 
 	// parse indicator/reportable from CSV
-	indicator := strings.ToLower(strings.TrimSpace(row[17])) == "y"
-	reportable := strings.ToLower(strings.TrimSpace(row[20])) == "y"
+	indicator := strings.ToLower(strings.TrimSpace(row.get(FieldIndicator))) == "y"
+	reportable := strings.ToLower(strings.TrimSpace(row.get(FieldReportable))) == "y"
 
 	// Species does not exist, insert (include indicator/reportable)
 	species = db.CreateSpeciesParams{
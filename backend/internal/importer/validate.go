@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// ValidateCSV parses every row of the CSV file the same way ImportCSV does
+// but writes nothing to the database. Instead of stopping at the first bad
+// row it collects every row error, along with the sites and species an
+// import would create, into the returned Report. A header that cannot be
+// read or misses required columns is returned as an error.
+func ValidateCSV(ctx context.Context, q db.Querier, filename string, opts Options) (*Report, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV: %w", err)
+	}
+	defer file.Close()
+
+	reader := newCSVReader(file)
+	// Without a header no row can be read, fail rather than reject it
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	cols, err := opts.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(filename, true)
+	report.header = header
+	report.ColumnsByPosition = cols.positional
+	rows := newRowImporter(q, cols, report)
+
+	i := 1
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// Malformed quoting only spoils this record, keep reading
+			report.TotalRows++
+			report.reject(i+1, row, err)
+			i++
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		report.TotalRows++
+		_, err = rows.parseRow(ctx, i, row)
+		if errors.As(err, &invalidRow{}) {
+			report.reject(i+1, row, err)
+		} else if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		} else {
+			report.ValidRows++
+		}
+		i++
+	}
+
+	return report, nil
+}
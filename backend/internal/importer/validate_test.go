@@ -0,0 +1,85 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testCSVHeader = "Site code,Survey date,Time,Method,Scientific name,Common name,Forest type,Indicator,Native,Tenure,Reportable,Block,Taxa"
+
+// writeTestCSV writes the lines to a CSV file in a temporary directory.
+func writeTestCSV(t *testing.T, lines ...string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "observations.csv")
+	if err := os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestValidateCSV(t *testing.T) {
+	filename := writeTestCSV(t,
+		testCSVHeader,
+		"SG01,5-Oct-24,6:30 AM,Camera,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird",
+		"SG01,5-Oct-24,7:00 AM,Sighting,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird",
+		`SG02,5-Oct-24,6:30 AM,cam"era,Vombatus ursinus,Common Wombat,Dry,N,Native,Private,N,2,Mammal`,
+		"SG03,6-Oct-24,8:15 PM,Audio,Vombatus ursinus,Common Wombat,Dry,N,Native,Leased,N,2,Mammal",
+		"SG02,5-Oct-24,9:00 PM,Audio,Vombatus ursinus,Common Wombat,Dry,N,Native,Private,N,2,Mammal",
+	)
+	report, err := ValidateCSV(context.Background(), emptyQuerier{}, filename, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.TotalRows != 5 || report.ValidRows != 2 || report.RejectedRows != 3 {
+		t.Errorf("rows = %d, valid %d, rejected %d, want 5, 2 and 3",
+			report.TotalRows, report.ValidRows, report.RejectedRows)
+	}
+	var rejected []int
+	for _, e := range report.Errors {
+		rejected = append(rejected, e.Row)
+	}
+	// Row 4 is malformed, rows 3 and 5 have an unknown method and tenure
+	if want := []int{3, 4, 5}; !slices.Equal(rejected, want) {
+		t.Errorf("rejected rows = %v, want %v: %+v", rejected, want, report.Errors)
+	}
+	if want := []string{"SG01", "SG02"}; !slices.Equal(report.NewSites, want) {
+		t.Errorf("new sites = %v, want %v", report.NewSites, want)
+	}
+	if want := []string{"Menura novaehollandiae", "Vombatus ursinus"}; !slices.Equal(report.NewSpecies, want) {
+		t.Errorf("new species = %v, want %v", report.NewSpecies, want)
+	}
+	if !report.DryRun || report.Committed {
+		t.Errorf("dry run = %v and committed = %v, want a dry run", report.DryRun, report.Committed)
+	}
+}
+
+func TestValidateCSVHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		err  string
+	}{
+		{"malformed header", []string{`Site "code",Survey date`, "SG01,5-Oct-24"}, "failed to read CSV header"},
+		{"empty file", nil, "failed to read CSV header"},
+		{"missing columns", []string{"Site code,Survey date", "SG01,5-Oct-24"}, "missing required column for time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeTestCSV(t, tt.rows...)
+			if tt.rows == nil {
+				if err := os.WriteFile(filename, nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			report, err := ValidateCSV(context.Background(), emptyQuerier{}, filename, Options{})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q (report %+v)", err, tt.err, report)
+			}
+		})
+	}
+}
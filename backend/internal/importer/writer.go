@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/jackc/pgx/v5"
+)
+
+// ConflictMode decides what happens to a row whose observation, identified
+// by site, species, timestamp, method and file, already exists.
+type ConflictMode string
+
+const (
+	// ConflictSkip leaves existing observations untouched.
+	ConflictSkip ConflictMode = "skip"
+	// ConflictUpdate overwrites the other values of existing observations.
+	ConflictUpdate ConflictMode = "update"
+)
+
+func (m ConflictMode) Valid() bool {
+	switch m {
+	case ConflictSkip, ConflictUpdate:
+		return true
+	}
+	return false
+}
+
+// Options configures an import.
+type Options struct {
+	OnConflict ConflictMode
+	// Columns maps the CSV headers to fields, DefaultColumns when nil.
+	Columns ColumnMapping
+	// Positional reads the CSV columns in the survey spreadsheet's fixed
+	// order, whatever the header says, instead of by header name.
+	Positional bool
+	// Site fills in new sites from formats that do not record their block,
+	// tenure or forest, such as Darwin Core Archives.
+	Site SiteDefaults
+	// FilePattern finds the site and start time of recordings and images
+	// in their path, DefaultFilePattern when nil.
+	FilePattern *FilePattern
+	// MinConfidence drops detections scored below it.
+	MinConfidence float32
+	// EventGap is the longest gap between images of the same species at a
+	// site for them to be one observation, DefaultEventGap when zero.
+	EventGap time.Duration
+}
+
+func (o Options) eventGap() time.Duration {
+	if o.EventGap == 0 {
+		return DefaultEventGap
+	}
+	return o.EventGap
+}
+
+func (o Options) filePattern() *FilePattern {
+	if o.FilePattern == nil {
+		return defaultFilePattern
+	}
+	return o.FilePattern
+}
+
+// SiteDefaults are the block, tenure and forest given to new sites when the
+// imported file does not say. Sites missing a value are rejected.
+type SiteDefaults struct {
+	Block  *int32
+	Tenure db.TenureType
+	Forest db.ForestType
+}
+
+func (o Options) columns() ColumnMapping {
+	if o.Columns == nil {
+		return DefaultColumns
+	}
+	return o.Columns
+}
+
+// observationWriter upserts observations in batches of BATCH_SIZE and counts
+// in the report how many were new, updated or unchanged.
+type observationWriter struct {
+	q      *db.Queries
+	mode   ConflictMode
+	report *Report
+	batch  []db.UpsertObservationsParams
+}
+
+func newObservationWriter(q *db.Queries, mode ConflictMode, report *Report) *observationWriter {
+	return &observationWriter{
+		q:      q,
+		mode:   mode,
+		report: report,
+		batch:  make([]db.UpsertObservationsParams, 0, BATCH_SIZE),
+	}
+}
+
+func (w *observationWriter) add(ctx context.Context, params db.CreateObservationsParams) error {
+	w.batch = append(w.batch, db.UpsertObservationsParams{
+		SiteID:          params.SiteID,
+		SpeciesID:       params.SpeciesID,
+		Timestamp:       params.Timestamp,
+		Method:          params.Method,
+		AppearanceStart: params.AppearanceStart,
+		AppearanceEnd:   params.AppearanceEnd,
+		Temperature:     params.Temperature,
+		Narrative:       params.Narrative,
+		Confidence:      params.Confidence,
+		File:            params.File,
+		ImportBatchID:   params.ImportBatchID,
+		UpdateExisting:  w.mode == ConflictUpdate,
+	})
+	if len(w.batch) < BATCH_SIZE {
+		return nil
+	}
+	return w.flush(ctx)
+}
+
+func (w *observationWriter) flush(ctx context.Context) error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+
+	var batchErr error
+	w.q.UpsertObservations(ctx, w.batch).QueryRow(func(_ int, row db.UpsertObservationsRow, err error) {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			w.report.Unchanged++
+		case err != nil:
+			if batchErr == nil {
+				batchErr = err
+			}
+		case row.Inserted:
+			w.report.Observations++
+		default:
+			w.report.Updated++
+		}
+	})
+	if batchErr != nil {
+		return fmt.Errorf("Failed to insert observations: %w", batchErr)
+	}
+
+	fmt.Printf("Processed %d observations: %d new, %d updated, %d unchanged\n",
+		w.report.ValidRows, w.report.Observations, w.report.Updated, w.report.Unchanged)
+	w.batch = w.batch[:0]
+	return nil
+}
@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// lyrebirdAt is a row observing a lyrebird at SG01 at 6:30 on the day, at
+// the temperature.
+func lyrebirdAt(day, temperature string) string {
+	return "SG01," + day + ",6:30 AM,Camera,Menura novaehollandiae,Superb Lyrebird,Wet,Y,Native,Public,N,1,Bird," + temperature
+}
+
+func TestReimportCounts(t *testing.T) {
+	header := testCSVHeader + ",Temp"
+	original := []string{header, lyrebirdAt("5-Oct-24", "12"), lyrebirdAt("6-Oct-24", "14")}
+	tests := []struct {
+		name       string
+		rows       []string
+		mode       ConflictMode
+		new        int64
+		updated    int64
+		unchanged  int64
+		duplicates []DuplicateRow
+	}{
+		{"same file", original, ConflictUpdate, 0, 0, 2, nil},
+		{"same file skipping", original, ConflictSkip, 0, 0, 2, nil},
+		{"changed and extended", append(slices.Clone(original[:2]), lyrebirdAt("6-Oct-24", "15"), lyrebirdAt("7-Oct-24", "9")),
+			ConflictUpdate, 1, 1, 1, nil},
+		{"changed, skipping", append(slices.Clone(original[:2]), lyrebirdAt("6-Oct-24", "15")),
+			ConflictSkip, 0, 0, 2, nil},
+		// The second row updates the observation the first one inserted
+		{"duplicate rows", []string{header, lyrebirdAt("7-Oct-24", "9"), lyrebirdAt("7-Oct-24", "10"), lyrebirdAt("7-Oct-24", "10")},
+			ConflictUpdate, 1, 1, 1, []DuplicateRow{{Row: 3, FirstRow: 2}, {Row: 4, FirstRow: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			conn := newMemDB()
+			if _, err := ImportCSV(ctx, conn, writeTestCSV(t, original...), Options{OnConflict: ConflictSkip}); err != nil {
+				t.Fatal(err)
+			}
+
+			filename := writeTestCSV(t, tt.rows...)
+			dryRun, err := ValidateCSV(ctx, db.New(conn), filename, Options{OnConflict: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := ImportCSV(ctx, conn, filename, Options{OnConflict: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Observations != tt.new || report.Updated != tt.updated || report.Unchanged != tt.unchanged {
+				t.Errorf("new, updated, unchanged = %d, %d, %d, want %d, %d, %d",
+					report.Observations, report.Updated, report.Unchanged, tt.new, tt.updated, tt.unchanged)
+			}
+			if !slices.Equal(report.Duplicates, tt.duplicates) || !slices.Equal(dryRun.Duplicates, tt.duplicates) {
+				t.Errorf("duplicates = %v and %v in the dry run, want %v", report.Duplicates, dryRun.Duplicates, tt.duplicates)
+			}
+			if dryRun.ValidRows != report.ValidRows {
+				t.Errorf("dry run has %d valid rows, the import %d", dryRun.ValidRows, report.ValidRows)
+			}
+		})
+	}
+}
+
+func TestUndoReimport(t *testing.T) {
+	ctx := context.Background()
+	conn := newMemDB()
+	header := testCSVHeader + ",Temp"
+	importRows := func(rows ...string) *Report {
+		t.Helper()
+		report, err := ImportCSV(ctx, conn, writeTestCSV(t, append([]string{header}, rows...)...), Options{OnConflict: ConflictUpdate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	undo := func(batch *Report) *UndoReport {
+		t.Helper()
+		report, err := UndoImport(ctx, conn, batch.BatchID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	temperatures := func() []int32 {
+		var temps []int32
+		for _, o := range conn.tables.observations {
+			temps = append(temps, *o.Temperature)
+		}
+		return temps
+	}
+
+	importRows(lyrebirdAt("5-Oct-24", "12"), lyrebirdAt("6-Oct-24", "14"))
+	second := importRows(lyrebirdAt("5-Oct-24", "13"), lyrebirdAt("6-Oct-24", "15"), lyrebirdAt("7-Oct-24", "9"))
+	third := importRows(lyrebirdAt("6-Oct-24", "16"))
+	if got, want := temperatures(), []int32{13, 16, 9}; !slices.Equal(got, want) {
+		t.Fatalf("temperatures = %v, want %v", got, want)
+	}
+
+	// The 6 October observation keeps the third batch's value
+	report := undo(second)
+	if report.Observations != 1 || report.Restored != 1 {
+		t.Errorf("undoing the second batch deleted %d and restored %d observations, want 1 and 1", report.Observations, report.Restored)
+	}
+	if got, want := temperatures(), []int32{12, 16}; !slices.Equal(got, want) {
+		t.Errorf("temperatures = %v, want %v", got, want)
+	}
+
+	// Back to the first batch's value, not the second's
+	report = undo(third)
+	if report.Observations != 0 || report.Restored != 1 {
+		t.Errorf("undoing the third batch deleted %d and restored %d observations, want 0 and 1", report.Observations, report.Restored)
+	}
+	if got, want := temperatures(), []int32{12, 14}; !slices.Equal(got, want) {
+		t.Errorf("temperatures = %v, want %v", got, want)
+	}
+	if len(conn.tables.revisions) != 0 {
+		t.Errorf("%d revisions left after undoing every update", len(conn.tables.revisions))
+	}
+}
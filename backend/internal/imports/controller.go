@@ -0,0 +1,150 @@
+package imports
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/importer"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	conn importer.Conn
+	q    db.Querier
+}
+
+func NewController(conn importer.Conn) *Controller {
+	return &Controller{
+		conn: conn,
+		q:    db.New(conn),
+	}
+}
+
+// format validates and imports one kind of uploaded file.
+type format struct {
+	validate func(ctx context.Context, q db.Querier, filename string, opts importer.Options) (*importer.Report, error)
+	run      func(ctx context.Context, conn importer.Conn, filename string, opts importer.Options) (*importer.Report, error)
+}
+
+// formats are the single-file formats that can be uploaded. Camtrap DP
+// packages are directories and can only be imported from the command line.
+var formats = map[string]format{
+	"csv":          {importer.ValidateCSV, importer.ImportCSV},
+	"dwca":         {importer.ValidateDwCA, importer.ImportDwCA},
+	"birdnet":      {importer.ValidateBirdNET, importer.ImportBirdNET},
+	"megadetector": {importer.ValidateMegaDetector, importer.ImportMegaDetector},
+	"deployments":  {importer.ValidateDeployments, importer.ImportDeployments},
+}
+
+type ImportRequest struct {
+	Format     string                `form:"format" binding:"required,oneof=csv dwca birdnet megadetector deployments"`
+	DryRun     bool                  `form:"dryRun"`
+	OnConflict importer.ConflictMode `form:"onConflict"`
+}
+
+// ListImports godoc
+//
+//	@Summary		List imports
+//	@Description	List past import batches, newest first
+//	@Tags			import
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Security		APIKeyAuth
+//	@Produce		json
+//	@Success		200	{object}	[]Batch
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Router			/imports [get]
+func (u *Controller) ListImports(c *gin.Context) {
+	batches, err := u.q.ListImportBatches(c.Request.Context())
+	if err != nil {
+		c.Error(fmt.Errorf("failed to list import batches: %w", err))
+		return
+	}
+	c.JSON(200, utils.MapSlice(newBatch, batches))
+}
+
+// CreateImport godoc
+//
+//	@Summary		Import a file
+//	@Description	Import an uploaded CSV, Darwin Core Archive, BirdNET results, MegaDetector output or deployments CSV file as one import batch, like the importer command. Every row is validated first: when any is rejected nothing is imported and the report lists the errors. With dryRun the file is only validated.
+//	@Tags			import
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Security		APIKeyAuth
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file		formData	file	true	"The file to import"
+//	@Param			format		query		string	true	"Format of the file"	Enums(csv, dwca, birdnet, megadetector, deployments)
+//	@Param			dryRun		query		bool	false	"Only validate the file"
+//	@Param			onConflict	query		string	false	"What to do with rows already imported"	Enums(update, skip)
+//	@Success		200			{object}	importer.Report
+//	@Success		201			{object}	importer.Report
+//	@Failure		400			{object}	utils.HttpError
+//	@Failure		401			{object}	utils.HttpError
+//	@Failure		403			{object}	utils.HttpError
+//	@Failure		422			{object}	importer.Report
+//	@Router			/imports [post]
+func (u *Controller) CreateImport(c *gin.Context) {
+	var req ImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	opts := importer.Options{OnConflict: req.OnConflict}
+	if opts.OnConflict == "" {
+		opts.OnConflict = importer.ConflictUpdate
+	}
+	if !opts.OnConflict.Valid() {
+		c.Error(utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown conflict mode %q", opts.OnConflict)))
+		return
+	}
+
+	upload, err := c.FormFile("file")
+	if err != nil {
+		c.Error(utils.NewHttpError(400, "missing file", err))
+		return
+	}
+	dir, err := os.MkdirTemp("", "nillumbik-import-")
+	if err != nil {
+		c.Error(fmt.Errorf("failed to create upload directory: %w", err))
+		return
+	}
+	defer os.RemoveAll(dir)
+	// Keep the name: BirdNET results are read by their extension, and name
+	// their recording when they have no file column
+	filename := filepath.Join(dir, filepath.Base(upload.Filename))
+	if err := c.SaveUploadedFile(upload, filename); err != nil {
+		c.Error(fmt.Errorf("failed to save upload: %w", err))
+		return
+	}
+
+	f := formats[req.Format]
+	report, err := f.validate(c.Request.Context(), u.q, filename, opts)
+	if err != nil {
+		c.Error(utils.NewHttpError(400, "invalid file", err))
+		return
+	}
+	report.File = upload.Filename
+	if req.DryRun {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	if report.RejectedRows > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	report, err = f.run(c.Request.Context(), u.conn, filename, opts)
+	if err != nil {
+		c.Error(fmt.Errorf("import failed, nothing was imported: %w", err))
+		return
+	}
+	report.File = upload.Filename
+	c.JSON(http.StatusCreated, report)
+}
@@ -0,0 +1,33 @@
+package imports
+
+import (
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+)
+
+// Batch is a past import with the number of observations it still has.
+type Batch struct {
+	ID               int64           `json:"id"`
+	FileName         string          `json:"fileName"`
+	Checksum         string          `json:"checksum"`
+	RowCount         int32           `json:"rowCount"`
+	StartedAt        time.Time       `json:"startedAt"`
+	FinishedAt       *time.Time      `json:"finishedAt"`
+	Status           db.ImportStatus `json:"status"`
+	ObservationCount int64           `json:"observationCount"`
+}
+
+func newBatch(b db.ListImportBatchesRow) Batch {
+	return Batch{
+		ID:               b.ID,
+		FileName:         b.FileName,
+		Checksum:         b.Checksum,
+		RowCount:         b.RowCount,
+		StartedAt:        b.StartedAt,
+		FinishedAt:       utils.FromPgTimestamp(b.FinishedAt),
+		Status:           b.Status,
+		ObservationCount: b.ObservationCount,
+	}
+}
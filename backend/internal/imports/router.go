@@ -0,0 +1,9 @@
+package imports
+
+import "github.com/gin-gonic/gin"
+
+func Register(r gin.IRouter, ctl *Controller) {
+	g := r.Group("/imports")
+	g.GET("", ctl.ListImports)
+	g.POST("", ctl.CreateImport)
+}
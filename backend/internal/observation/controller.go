@@ -1,14 +1,17 @@
 package observation
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/stats"
 	"github.com/biomonash/nillumbik/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Controller struct {
@@ -21,49 +24,134 @@ func NewController(queries db.Querier) *Controller {
 	}
 }
 
-type ListObservationsRequest struct {
-	Limit  int32 `form:"limit" binding:"required,max=1000,min=1"`
-	Offset int32 `form:"offset" binding:"min=0"`
-}
-
-type ListObservationsResponse struct {
-	Count        int              `json:"count"`
-	Observations []db.Observation `json:"observations"`
-}
-
 // ListObservations godoc
 //
 //	@Summary		List observations
-//	@Description	List observations
+//	@Description	List the observations matching the filters, a page at a time. Pass the nextCursor of a page as the cursor of the next request; it is null on the last page. The offset parameter and the count field are deprecated, in favour of the cursor and total, and will be removed in the next release.
 //	@Tags			observation
 //	@Accept			json
 //	@Produce		json
-//	@Param			limit	query		int	True	"Result limit"	default(100)
-//	@Param			offset	query		int	True	"Result offset"	default(0)
-//	@Success		200		{object}	ListObservationsResponse
-//	@Error			400 	{object}	gin.H
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Only observations starting an independent event, after this many minutes without one of the species at the site by the same method"	minimum(1)	maximum(10080)
+//	@Param			method			query		string	False	"Filter by observation method"																											Enums(audio, camera, observed)
+//	@Param			minConfidence	query		number	False	"Only observations with at least this confidence"
+//	@Param			native			query		bool	False	"Filter by native species"
+//	@Param			indicator		query		bool	False	"Filter by indicator species"
+//	@Param			sort			query		string	False	"Order by timestamp, oldest or newest first"	Enums(timestamp, -timestamp)	default(timestamp)
+//	@Param			cursor			query		string	False	"nextCursor of the previous page"
+//	@Param			offset			query		int		False	"Deprecated: number of observations to skip, not allowed with cursor"	minimum(0)
+//	@Param			limit			query		int		False	"Page size"																default(100)
+//	@Param			expand			query		string	False	"Nest the site and/or species in each observation, e.g. site,species"
+//	@Success		200				{object}	ListObservationsResponse
+//	@Failure		400				{object}	utils.HttpError
 //	@Router			/observations [get]
 func (u *Controller) ListObservations(c *gin.Context) {
-	var params ListObservationsRequest
-	if err := c.ShouldBindQuery(&params); err != nil {
-		// c.Error(err)
+	var req ListObservationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
 		c.Error(utils.NewHttpError(400, "validation failed", err))
 		return
 	}
+	expand, err := parseExpand(req.Expand)
+	if err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if req.Method != nil && !req.Method.Valid() {
+		c.Error(utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown observation method %q", *req.Method)))
+		return
+	}
+	if req.Cursor != "" && req.Offset > 0 {
+		c.Error(utils.NewHttpError(400, "validation failed", errors.New("offset cannot be combined with cursor")))
+		return
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
 
-	obs, err := u.q.ListObservations(c.Request.Context(), db.ListObservationsParams{
-		Limit:  params.Limit,
-		Offset: params.Offset,
-	})
+	from, to, taxa, commonName := stats.ParseObservationStatsInput(req.ObservationStatsInput)
+	filter := db.CountObservationsParams{
+		From:          from,
+		To:            to,
+		Block:         req.Block,
+		Tenure:        req.NullTenure(),
+		Forest:        req.NullForest(),
+		SiteCode:      req.SiteCode,
+		Taxa:          taxa,
+		CommonName:    commonName,
+		Independence:  req.Independence,
+		Method:        db.NullObservationMethod{Valid: req.Method != nil},
+		MinConfidence: req.MinConfidence,
+		Native:        req.Native,
+		Indicator:     req.Indicator,
+	}
+	if req.Method != nil {
+		filter.Method.ObservationMethod = *req.Method
+	}
+
+	params := db.ListObservationsParams{
+		From:          filter.From,
+		To:            filter.To,
+		Block:         filter.Block,
+		Tenure:        filter.Tenure,
+		Forest:        filter.Forest,
+		SiteCode:      filter.SiteCode,
+		Taxa:          filter.Taxa,
+		CommonName:    filter.CommonName,
+		Independence:  filter.Independence,
+		Method:        filter.Method,
+		MinConfidence: filter.MinConfidence,
+		Native:        filter.Native,
+		Indicator:     filter.Indicator,
+		// One more than the page to tell whether there is a next one
+		Limit:  limit + 1,
+		Offset: req.Offset,
+	}
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor)
+		if err != nil {
+			c.Error(utils.NewHttpError(400, "invalid cursor", err))
+			return
+		}
+		params.AfterTimestamp = pgtype.Timestamp{Time: after.Timestamp, Valid: true}
+		params.AfterID = after.ID
+	}
+
+	var obs []db.Observation
+	if req.Sort == "-timestamp" {
+		obs, err = u.q.ListObservationsDesc(c.Request.Context(), db.ListObservationsDescParams(params))
+	} else {
+		obs, err = u.q.ListObservations(c.Request.Context(), params)
+	}
 	if err != nil {
 		c.Error(fmt.Errorf("failed to list observations: %w", err))
 		return
 	}
+	total, err := u.q.CountObservations(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to count observations: %w", err))
+		return
+	}
 
-	c.JSON(200, ListObservationsResponse{
-		Count:        len(obs),
-		Observations: obs,
-	})
+	res := ListObservationsResponse{Total: total, Count: total}
+	if len(obs) > int(limit) {
+		obs = obs[:limit]
+		next := newCursor(obs[limit-1]).encode()
+		res.NextCursor = &next
+	}
+	res.Observations, err = u.expand(c.Request.Context(), obs, expand)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(200, res)
 }
 
 // GetObservationDetail godoc
@@ -71,10 +159,13 @@ func (u *Controller) ListObservations(c *gin.Context) {
 //	@Summary		Get Observation Detail
 //	@Description	Get the detail of an observation by ID
 //	@Tags			observation
-//	@Param			id	path	integer	True	"ID of the observation"
+//	@Param			id		path	integer	True	"ID of the observation"
+//	@Param			expand	query	string	False	"Nest the site and/or species in the observation, e.g. site,species"
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	Observation
+//	@Success		200	{object}	ObservationDetail
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
 //	@Router			/observations/{id} [get]
 func (u *Controller) GetObservationByID(c *gin.Context) {
 	idStr := c.Param("id")
@@ -83,6 +174,16 @@ func (u *Controller) GetObservationByID(c *gin.Context) {
 		c.Error(utils.NewHttpError(400, "Invalid id", err))
 		return
 	}
+	var req ExpandRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	expand, err := parseExpand(req.Expand)
+	if err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
 	ob, err := u.q.GetObservation(c.Request.Context(), int64(id))
 	if errors.Is(pgx.ErrNoRows, err) {
 		c.Error(utils.NewHttpError(404, "observation not found", err))
@@ -93,5 +194,238 @@ func (u *Controller) GetObservationByID(c *gin.Context) {
 		return
 	}
 
+	details, err := u.expand(c.Request.Context(), []db.Observation{ob}, expand)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(200, details[0])
+}
+
+// CreateObservation godoc
+//
+//	@Summary		Create observation
+//	@Description	Record a new observation of a known site and species
+//	@Tags			observation
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			observation	body		ObservationInput	true	"The observation"
+//	@Success		201			{object}	db.Observation
+//	@Failure		400			{object}	utils.HttpError
+//	@Failure		401			{object}	utils.HttpError
+//	@Failure		403			{object}	utils.HttpError
+//	@Failure		409			{object}	utils.HttpError
+//	@Router			/observations [post]
+func (u *Controller) CreateObservation(c *gin.Context) {
+	var input ObservationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if err := u.validate(c.Request.Context(), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	ob, err := u.q.CreateObservation(c.Request.Context(), db.CreateObservationParams{
+		SiteID:          input.SiteID,
+		SpeciesID:       input.SpeciesID,
+		Timestamp:       localTime(input.Timestamp),
+		Method:          input.Method,
+		AppearanceStart: input.AppearanceStart,
+		AppearanceEnd:   input.AppearanceEnd,
+		Temperature:     input.Temperature,
+		Narrative:       input.Narrative,
+		Confidence:      input.Confidence,
+		File:            input.File,
+	})
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "observation already exists", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to create observation: %w", err))
+		return
+	}
+
+	c.JSON(201, ob)
+}
+
+// UpdateObservation godoc
+//
+//	@Summary		Replace observation
+//	@Description	Replace every value of an observation
+//	@Tags			observation
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		integer				True	"ID of the observation"
+//	@Param			observation	body		ObservationInput	true	"The observation"
+//	@Success		200			{object}	db.Observation
+//	@Failure		400			{object}	utils.HttpError
+//	@Failure		401			{object}	utils.HttpError
+//	@Failure		403			{object}	utils.HttpError
+//	@Failure		404			{object}	utils.HttpError
+//	@Failure		409			{object}	utils.HttpError
+//	@Router			/observations/{id} [put]
+func (u *Controller) UpdateObservation(c *gin.Context) {
+	id, err := observationID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var input ObservationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	u.save(c, id, input)
+}
+
+// PatchObservation godoc
+//
+//	@Summary		Edit observation
+//	@Description	Change some values of an observation. Fields missing from the body are kept, fields set to null are cleared.
+//	@Tags			observation
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		integer				True	"ID of the observation"
+//	@Param			observation	body		ObservationInput	true	"The values to change"
+//	@Success		200			{object}	db.Observation
+//	@Failure		400			{object}	utils.HttpError
+//	@Failure		401			{object}	utils.HttpError
+//	@Failure		403			{object}	utils.HttpError
+//	@Failure		404			{object}	utils.HttpError
+//	@Failure		409			{object}	utils.HttpError
+//	@Router			/observations/{id} [patch]
+func (u *Controller) PatchObservation(c *gin.Context) {
+	id, err := observationID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	ob, err := u.q.GetObservation(c.Request.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "observation not found", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to get observation by id: %w", err))
+		return
+	}
+
+	// Decoding over the current values only changes those in the body
+	input := inputFromObservation(ob)
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	u.save(c, id, input)
+}
+
+// DeleteObservation godoc
+//
+//	@Summary		Delete observation
+//	@Description	Delete an observation by ID
+//	@Tags			observation
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			id	path	integer	True	"ID of the observation"
+//	@Success		204
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Router			/observations/{id} [delete]
+func (u *Controller) DeleteObservation(c *gin.Context) {
+	id, err := observationID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	deleted, err := u.q.DeleteObservation(c.Request.Context(), id)
+	if err != nil {
+		c.Error(fmt.Errorf("failed to delete observation: %w", err))
+		return
+	}
+	if deleted == 0 {
+		c.Error(utils.NewHttpError(404, "observation not found", pgx.ErrNoRows))
+		return
+	}
+
+	c.Status(204)
+}
+
+func (u *Controller) save(c *gin.Context, id int64, input ObservationInput) {
+	if err := u.validate(c.Request.Context(), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	ob, err := u.q.UpdateObservation(c.Request.Context(), db.UpdateObservationParams{
+		ID:              id,
+		SiteID:          input.SiteID,
+		SpeciesID:       input.SpeciesID,
+		Timestamp:       localTime(input.Timestamp),
+		Method:          input.Method,
+		AppearanceStart: input.AppearanceStart,
+		AppearanceEnd:   input.AppearanceEnd,
+		Temperature:     input.Temperature,
+		Narrative:       input.Narrative,
+		Confidence:      input.Confidence,
+		File:            input.File,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "observation not found", err))
+		return
+	}
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "another observation has the same site, species, time, method and file", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to update observation: %w", err))
+		return
+	}
+
 	c.JSON(200, ob)
 }
+
+// validate checks what binding cannot: the method, the order of the
+// appearance times and that the site and species exist.
+func (u *Controller) validate(ctx context.Context, input ObservationInput) error {
+	if !input.Method.Valid() {
+		return utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown observation method %q", input.Method))
+	}
+	if input.AppearanceStart != nil && input.AppearanceEnd != nil && *input.AppearanceStart > *input.AppearanceEnd {
+		return utils.NewHttpError(400, "validation failed", errors.New("appearanceStart is after appearanceEnd"))
+	}
+
+	_, err := u.q.GetSite(ctx, input.SiteID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewHttpError(400, "validation failed", fmt.Errorf("site %d not found", input.SiteID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get site: %w", err)
+	}
+	_, err = u.q.GetSpecies(ctx, input.SpeciesID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewHttpError(400, "validation failed", fmt.Errorf("species %d not found", input.SpeciesID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get species: %w", err)
+	}
+	return nil
+}
+
+func observationID(c *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, utils.NewHttpError(400, "Invalid id", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,292 @@
+package observation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// editQuerier keeps observations in a map, with site 1 and species 10 the
+// only ones that exist. Like the unique index, it refuses two observations
+// with the same site, species, time, method and file.
+type editQuerier struct {
+	db.Querier
+	obs map[int64]db.Observation
+}
+
+func newEditQuerier() *editQuerier {
+	return &editQuerier{obs: map[int64]db.Observation{
+		1: {ID: 1, SiteID: 1, SpeciesID: 10, Timestamp: localTime(time.Date(2024, 10, 5, 6, 30, 0, 0, time.UTC)), Method: db.ObservationMethodAudio,
+			Temperature: ptr(int32(12)), Narrative: ptr("Calling from the gully")},
+		2: {ID: 2, SiteID: 1, SpeciesID: 10, Timestamp: localTime(time.Date(2024, 10, 6, 6, 30, 0, 0, time.UTC)), Method: db.ObservationMethodAudio},
+	}}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func (q *editQuerier) GetSite(ctx context.Context, id int64) (db.Site, error) {
+	if id != 1 {
+		return db.Site{}, pgx.ErrNoRows
+	}
+	return db.Site{ID: id}, nil
+}
+
+func (q *editQuerier) GetSpecies(ctx context.Context, id int64) (db.Species, error) {
+	if id != 10 {
+		return db.Species{}, pgx.ErrNoRows
+	}
+	return db.Species{ID: id}, nil
+}
+
+func (q *editQuerier) GetObservation(ctx context.Context, id int64) (db.Observation, error) {
+	o, ok := q.obs[id]
+	if !ok {
+		return db.Observation{}, pgx.ErrNoRows
+	}
+	return o, nil
+}
+
+func (q *editQuerier) CreateObservation(ctx context.Context, arg db.CreateObservationParams) (db.Observation, error) {
+	o := db.Observation{ID: int64(len(q.obs) + 1), SiteID: arg.SiteID, SpeciesID: arg.SpeciesID, Timestamp: arg.Timestamp, Method: arg.Method,
+		AppearanceStart: arg.AppearanceStart, AppearanceEnd: arg.AppearanceEnd, Temperature: arg.Temperature,
+		Narrative: arg.Narrative, Confidence: arg.Confidence, File: arg.File}
+	return q.put(o)
+}
+
+func (q *editQuerier) UpdateObservation(ctx context.Context, arg db.UpdateObservationParams) (db.Observation, error) {
+	if _, ok := q.obs[arg.ID]; !ok {
+		return db.Observation{}, pgx.ErrNoRows
+	}
+	o := db.Observation{ID: arg.ID, SiteID: arg.SiteID, SpeciesID: arg.SpeciesID, Timestamp: arg.Timestamp, Method: arg.Method,
+		AppearanceStart: arg.AppearanceStart, AppearanceEnd: arg.AppearanceEnd, Temperature: arg.Temperature,
+		Narrative: arg.Narrative, Confidence: arg.Confidence, File: arg.File}
+	return q.put(o)
+}
+
+func (q *editQuerier) DeleteObservation(ctx context.Context, id int64) (int64, error) {
+	if _, ok := q.obs[id]; !ok {
+		return 0, nil
+	}
+	delete(q.obs, id)
+	return 1, nil
+}
+
+func (q *editQuerier) put(o db.Observation) (db.Observation, error) {
+	for _, other := range q.obs {
+		if other.ID != o.ID && other.SiteID == o.SiteID && other.SpeciesID == o.SpeciesID && other.Timestamp.Equal(o.Timestamp) &&
+			other.Method == o.Method && deref(other.File) == deref(o.File) {
+			return db.Observation{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	q.obs[o.ID] = o
+	return o, nil
+}
+
+func deref[T any](p *T) T {
+	var v T
+	if p != nil {
+		v = *p
+	}
+	return v
+}
+
+// serve runs handler on a request to path, returning the status the error
+// handler would answer with.
+func serve(handler gin.HandlerFunc, method, path, id, body string) (int, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if id != "" {
+		c.Params = gin.Params{{Key: "id", Value: id}}
+	}
+	handler(c)
+	if len(c.Errors) == 0 {
+		return c.Writer.Status(), w
+	}
+	var httpErr utils.HttpError
+	if errors.As(c.Errors.Last().Err, &httpErr) {
+		return httpErr.Code, w
+	}
+	return http.StatusInternalServerError, w
+}
+
+func TestCreateObservation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"valid", `{"siteId":1,"speciesId":10,"timestamp":"2024-10-07T06:30:00Z","method":"camera","appearanceStart":3,"appearanceEnd":8}`, 201},
+		{"same key as another", `{"siteId":1,"speciesId":10,"timestamp":"2024-10-05T06:30:00Z","method":"audio"}`, 409},
+		{"missing site", `{"speciesId":10,"timestamp":"2024-10-07T06:30:00Z","method":"audio"}`, 400},
+		{"unknown method", `{"siteId":1,"speciesId":10,"timestamp":"2024-10-07T06:30:00Z","method":"heard"}`, 400},
+		{"appearance ends before it starts", `{"siteId":1,"speciesId":10,"timestamp":"2024-10-07T06:30:00Z","method":"camera","appearanceStart":8,"appearanceEnd":3}`, 400},
+		{"confidence above one", `{"siteId":1,"speciesId":10,"timestamp":"2024-10-07T06:30:00Z","method":"audio","confidence":1.5}`, 400},
+		{"unknown site", `{"siteId":2,"speciesId":10,"timestamp":"2024-10-07T06:30:00Z","method":"audio"}`, 400},
+		{"unknown species", `{"siteId":1,"speciesId":11,"timestamp":"2024-10-07T06:30:00Z","method":"audio"}`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newEditQuerier()
+			code, _ := serve(NewController(q).CreateObservation, http.MethodPost, "/observations", "", tt.body)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if created := len(q.obs) == 3; created != (tt.want == 201) {
+				t.Errorf("created = %v, want %v", created, tt.want == 201)
+			}
+		})
+	}
+}
+
+func TestEditObservation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		body   string
+		want   int
+		check  func(t *testing.T, o db.Observation)
+	}{
+		{
+			name:   "replace",
+			method: http.MethodPut,
+			id:     "1",
+			body:   `{"siteId":1,"speciesId":10,"timestamp":"2024-10-05T06:30:00Z","method":"audio","temperature":15}`,
+			want:   200,
+			check: func(t *testing.T, o db.Observation) {
+				if deref(o.Temperature) != 15 || o.Narrative != nil {
+					t.Errorf("temperature = %v and narrative = %v, want 15 and none", deref(o.Temperature), o.Narrative)
+				}
+			},
+		},
+		{
+			name:   "replace without a required value",
+			method: http.MethodPut,
+			id:     "1",
+			body:   `{"siteId":1,"speciesId":10,"method":"audio"}`,
+			want:   400,
+		},
+		{
+			name:   "replace an unknown observation",
+			method: http.MethodPut,
+			id:     "9",
+			body:   `{"siteId":1,"speciesId":10,"timestamp":"2024-10-05T06:30:00Z","method":"audio"}`,
+			want:   404,
+		},
+		{
+			name:   "replace with the key of another",
+			method: http.MethodPut,
+			id:     "1",
+			body:   `{"siteId":1,"speciesId":10,"timestamp":"2024-10-06T06:30:00Z","method":"audio"}`,
+			want:   409,
+		},
+		{
+			name:   "patch keeps the values missing from the body",
+			method: http.MethodPatch,
+			id:     "1",
+			body:   `{"temperature":15}`,
+			want:   200,
+			check: func(t *testing.T, o db.Observation) {
+				if deref(o.Temperature) != 15 || deref(o.Narrative) != "Calling from the gully" || o.Method != db.ObservationMethodAudio {
+					t.Errorf("observation = %+v, want temperature 15 and the rest kept", o)
+				}
+			},
+		},
+		{
+			name:   "patch clears the values set to null",
+			method: http.MethodPatch,
+			id:     "1",
+			body:   `{"narrative":null}`,
+			want:   200,
+			check: func(t *testing.T, o db.Observation) {
+				if o.Narrative != nil || deref(o.Temperature) != 12 {
+					t.Errorf("observation = %+v, want no narrative and temperature 12", o)
+				}
+			},
+		},
+		{
+			name:   "patch validates the result",
+			method: http.MethodPatch,
+			id:     "1",
+			body:   `{"speciesId":11}`,
+			want:   400,
+		},
+		{
+			name:   "patch an unknown observation",
+			method: http.MethodPatch,
+			id:     "9",
+			body:   `{"temperature":15}`,
+			want:   404,
+		},
+		{
+			name:   "invalid id",
+			method: http.MethodPatch,
+			id:     "one",
+			body:   `{"temperature":15}`,
+			want:   400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newEditQuerier()
+			ctl := NewController(q)
+			handler := ctl.UpdateObservation
+			if tt.method == http.MethodPatch {
+				handler = ctl.PatchObservation
+			}
+			code, w := serve(handler, tt.method, "/observations/"+tt.id, tt.id, tt.body)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if tt.check == nil {
+				return
+			}
+			var o db.Observation
+			if err := json.Unmarshal(w.Body.Bytes(), &o); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, o)
+			tt.check(t, q.obs[1])
+		})
+	}
+}
+
+func TestDeleteObservation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		id   string
+		want int
+	}{
+		{"existing", "1", 204},
+		{"unknown", "9", 404},
+		{"invalid id", "one", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newEditQuerier()
+			code, _ := serve(NewController(q).DeleteObservation, http.MethodDelete, "/observations/"+tt.id, tt.id, "")
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if _, kept := q.obs[1]; kept == (tt.id == "1") {
+				t.Errorf("observation 1 kept = %v", kept)
+			}
+		})
+	}
+}
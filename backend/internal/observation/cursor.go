@@ -0,0 +1,38 @@
+package observation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// cursor marks the last observation of a page, the next page starting
+// after it in timestamp then id order.
+type cursor struct {
+	Timestamp time.Time `json:"t"`
+	ID        int64     `json:"id"`
+}
+
+func newCursor(o db.Observation) cursor {
+	return cursor{Timestamp: o.Timestamp, ID: o.ID}
+}
+
+// encode makes the cursor an opaque URL-safe string.
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (c cursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,133 @@
+package observation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCursor(t *testing.T) {
+	tests := []struct {
+		name string
+		c    cursor
+	}{
+		{"zero", cursor{}},
+		{"observation", cursor{Timestamp: time.Date(2024, 10, 5, 6, 30, 15, 0, time.UTC), ID: 42}},
+		{"sub-second", cursor{Timestamp: time.Date(2024, 10, 5, 6, 30, 15, 123456000, time.UTC), ID: 1 << 40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.c.encode()
+			got, err := decodeCursor(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Timestamp.Equal(tt.c.Timestamp) || got.ID != tt.c.ID {
+				t.Errorf("decodeCursor(%q) = %+v, want %+v", s, got, tt.c)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, s := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"t":"yesterday"}`)),
+	} {
+		if _, err := decodeCursor(s); err == nil {
+			t.Errorf("decodeCursor(%q) succeeded", s)
+		}
+	}
+}
+
+// pageQuerier serves observations 1 to total a minute apart, paging them
+// as the queries do.
+type pageQuerier struct {
+	db.Querier
+	total int
+}
+
+func (q pageQuerier) ListObservations(ctx context.Context, arg db.ListObservationsParams) ([]db.Observation, error) {
+	start := time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC)
+	var obs []db.Observation
+	for id := 1; id <= q.total; id++ {
+		o := db.Observation{ID: int64(id), Timestamp: start.Add(time.Duration(id) * time.Minute)}
+		if arg.AfterTimestamp.Valid && (o.Timestamp.Before(arg.AfterTimestamp.Time) || o.Timestamp.Equal(arg.AfterTimestamp.Time) && o.ID <= arg.AfterID) {
+			continue
+		}
+		obs = append(obs, o)
+	}
+	obs = obs[min(int(arg.Offset), len(obs)):]
+	return obs[:min(int(arg.Limit), len(obs))], nil
+}
+
+func (q pageQuerier) CountObservations(ctx context.Context, arg db.CountObservationsParams) (int64, error) {
+	return int64(q.total), nil
+}
+
+func TestListObservationsPaging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	q := pageQuerier{total: 5}
+	list := func(query string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/observations?"+query, nil)
+		NewController(q).ListObservations(c)
+		return w, c
+	}
+	afterSecond := newCursor(db.Observation{ID: 2, Timestamp: time.Date(2024, 10, 5, 0, 2, 0, 0, time.UTC)}).encode()
+
+	tests := []struct {
+		name    string
+		query   string
+		ids     []int64
+		next    bool
+		wantErr bool
+	}{
+		{"first page", "limit=2", []int64{1, 2}, true, false},
+		{"after a cursor", "limit=2&cursor=" + afterSecond, []int64{3, 4}, true, false},
+		{"last page", "limit=3&cursor=" + afterSecond, []int64{3, 4, 5}, false, false},
+		{"offset", "limit=2&offset=3", []int64{4, 5}, false, false},
+		{"offset past the end", "limit=2&offset=10", []int64{}, false, false},
+		{"offset with a cursor", "limit=2&offset=2&cursor=" + afterSecond, nil, false, true},
+		{"invalid cursor", "cursor=nope", nil, false, true},
+		{"negative offset", "offset=-1", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, c := list(tt.query)
+			if (len(c.Errors) > 0) != tt.wantErr {
+				t.Fatalf("errors = %v, want one: %v", c.Errors, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var res ListObservationsResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]int64, len(res.Observations))
+			for i, o := range res.Observations {
+				ids[i] = o.ID
+			}
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("ids = %v, want %v", ids, tt.ids)
+			}
+			if (res.NextCursor != nil) != tt.next {
+				t.Errorf("next cursor = %v, want one: %v", res.NextCursor, tt.next)
+			}
+			if res.Total != 5 || res.Count != res.Total {
+				t.Errorf("total = %d and count = %d, want both 5", res.Total, res.Count)
+			}
+		})
+	}
+}
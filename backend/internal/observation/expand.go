@@ -0,0 +1,97 @@
+package observation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// expansion is what to nest in observations.
+type expansion struct {
+	site    bool
+	species bool
+}
+
+func parseExpand(expand string) (e expansion, err error) {
+	if expand == "" {
+		return e, nil
+	}
+	for _, field := range strings.Split(expand, ",") {
+		switch strings.TrimSpace(field) {
+		case "site":
+			e.site = true
+		case "species":
+			e.species = true
+		default:
+			return e, fmt.Errorf("cannot expand %q, only site and species", field)
+		}
+	}
+	return e, nil
+}
+
+// expand loads the sites and species of the observations as e asks, with
+// one query each.
+func (u *Controller) expand(ctx context.Context, obs []db.Observation, e expansion) ([]ObservationDetail, error) {
+	details := make([]ObservationDetail, len(obs))
+	for i, o := range obs {
+		details[i].Observation = o
+	}
+
+	if e.site {
+		ids := uniqueIDs(obs, func(o db.Observation) int64 { return o.SiteID })
+		sites, err := u.q.ListSitesByID(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sites: %w", err)
+		}
+		byID := make(map[int64]*ObservationSite, len(sites))
+		for _, s := range sites {
+			byID[s.ID] = &ObservationSite{
+				ID:     s.ID,
+				Code:   s.Code,
+				Name:   s.Name,
+				Block:  s.Block,
+				Tenure: s.Tenure,
+				Forest: s.Forest,
+			}
+		}
+		for i := range details {
+			details[i].Site = byID[details[i].SiteID]
+		}
+	}
+
+	if e.species {
+		ids := uniqueIDs(obs, func(o db.Observation) int64 { return o.SpeciesID })
+		species, err := u.q.ListSpeciesByID(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get species: %w", err)
+		}
+		byID := make(map[int64]*ObservationSpecies, len(species))
+		for _, s := range species {
+			byID[s.ID] = &ObservationSpecies{
+				ID:             s.ID,
+				ScientificName: s.ScientificName,
+				CommonName:     s.CommonName,
+				Taxa:           s.Taxa,
+				Native:         s.Native,
+				Indicator:      s.Indicator,
+				Reportable:     s.Reportable,
+			}
+		}
+		for i := range details {
+			details[i].Species = byID[details[i].SpeciesID]
+		}
+	}
+	return details, nil
+}
+
+func uniqueIDs(obs []db.Observation, id func(db.Observation) int64) []int64 {
+	ids := make([]int64, 0, len(obs))
+	for _, o := range obs {
+		ids = append(ids, id(o))
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -0,0 +1,165 @@
+package observation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseExpand(t *testing.T) {
+	tests := []struct {
+		expand  string
+		want    expansion
+		wantErr bool
+	}{
+		{"", expansion{}, false},
+		{"site", expansion{site: true}, false},
+		{"species", expansion{species: true}, false},
+		{"site,species", expansion{site: true, species: true}, false},
+		{"species, site", expansion{site: true, species: true}, false},
+		{"site,site", expansion{site: true}, false},
+		{"observer", expansion{}, true},
+		{"site,", expansion{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseExpand(tt.expand)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseExpand(%q) error = %v, want one: %v", tt.expand, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseExpand(%q) = %+v, want %+v", tt.expand, got, tt.want)
+		}
+	}
+}
+
+// expandQuerier serves sites 1 and 2 and species 10 and 11, recording the
+// ids each lookup asks for.
+type expandQuerier struct {
+	*editQuerier
+	siteIDs    [][]int64
+	speciesIDs [][]int64
+}
+
+func (q *expandQuerier) ListSitesByID(ctx context.Context, ids []int64) ([]db.Site, error) {
+	q.siteIDs = append(q.siteIDs, ids)
+	var sites []db.Site
+	for _, id := range ids {
+		if id == 1 || id == 2 {
+			sites = append(sites, db.Site{ID: id, Code: []string{"", "NIL01", "NIL02"}[id], Block: 1, Tenure: db.TenureTypePublic, Forest: db.ForestTypeDry})
+		}
+	}
+	return sites, nil
+}
+
+func (q *expandQuerier) ListSpeciesByID(ctx context.Context, ids []int64) ([]db.Species, error) {
+	q.speciesIDs = append(q.speciesIDs, ids)
+	var species []db.Species
+	for _, id := range ids {
+		if id == 10 || id == 11 {
+			species = append(species, db.Species{ID: id, ScientificName: map[int64]string{10: "Menura novaehollandiae", 11: "Vombatus ursinus"}[id]})
+		}
+	}
+	return species, nil
+}
+
+func TestExpand(t *testing.T) {
+	obs := []db.Observation{
+		{ID: 1, SiteID: 2, SpeciesID: 10},
+		{ID: 2, SiteID: 1, SpeciesID: 11},
+		{ID: 3, SiteID: 2, SpeciesID: 10},
+	}
+	tests := []struct {
+		name       string
+		e          expansion
+		siteIDs    [][]int64
+		speciesIDs [][]int64
+	}{
+		{"nothing", expansion{}, nil, nil},
+		{"site", expansion{site: true}, [][]int64{{1, 2}}, nil},
+		{"species", expansion{species: true}, nil, [][]int64{{10, 11}}},
+		{"both", expansion{site: true, species: true}, [][]int64{{1, 2}}, [][]int64{{10, 11}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &expandQuerier{editQuerier: newEditQuerier()}
+			details, err := NewController(q).expand(context.Background(), obs, tt.e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// One lookup for all the observations, without repeated ids
+			if !slices.EqualFunc(q.siteIDs, tt.siteIDs, slices.Equal) {
+				t.Errorf("site lookups = %v, want %v", q.siteIDs, tt.siteIDs)
+			}
+			if !slices.EqualFunc(q.speciesIDs, tt.speciesIDs, slices.Equal) {
+				t.Errorf("species lookups = %v, want %v", q.speciesIDs, tt.speciesIDs)
+			}
+			for i, d := range details {
+				if d.Observation != obs[i] {
+					t.Errorf("observation %d = %+v, want %+v", i, d.Observation, obs[i])
+				}
+				if (d.Site != nil) != tt.e.site || d.Site != nil && d.Site.ID != d.SiteID {
+					t.Errorf("observation %d site = %+v, want site %d nested: %v", d.ID, d.Site, d.SiteID, tt.e.site)
+				}
+				if (d.Species != nil) != tt.e.species || d.Species != nil && d.Species.ID != d.SpeciesID {
+					t.Errorf("observation %d species = %+v, want species %d nested: %v", d.ID, d.Species, d.SpeciesID, tt.e.species)
+				}
+			}
+		})
+	}
+}
+
+func TestGetObservationByIDExpand(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		query  string
+		want   int
+		fields []string
+	}{
+		{"not expanded", "", 200, nil},
+		{"site", "?expand=site", 200, []string{"site"}},
+		{"site and species", "?expand=site,species", 200, []string{"site", "species"}},
+		{"unknown field", "?expand=observer", 400, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &expandQuerier{editQuerier: newEditQuerier()}
+			code, w := serve(NewController(q).GetObservationByID, http.MethodGet, "/observations/1"+tt.query, "1", "")
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if tt.want != 200 {
+				return
+			}
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			// The observation keeps its flat fields, with the nested ones added
+			if _, ok := body["siteId"]; !ok {
+				t.Errorf("body %s has no siteId", w.Body)
+			}
+			for _, field := range []string{"site", "species"} {
+				_, ok := body[field]
+				if want := slices.Contains(tt.fields, field); ok != want {
+					t.Errorf("body %s has %s: %v, want %v", w.Body, field, ok, want)
+				}
+			}
+			if _, ok := body["site"]; ok {
+				var site ObservationSite
+				if err := json.Unmarshal(body["site"], &site); err != nil {
+					t.Fatal(err)
+				}
+				if site.ID != 1 || site.Code != "NIL01" {
+					t.Errorf("site = %+v, want NIL01", site)
+				}
+			}
+		})
+	}
+}
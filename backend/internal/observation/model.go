@@ -3,22 +3,101 @@ package observation
 import (
 	"time"
 
+	"github.com/biomonash/nillumbik/internal/config"
 	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/stats"
 )
 
-type Observation struct {
-	ID             int64                `json:"id"`
-	SiteID         int64                `json:"siteId"`
-	SpeciesID      int64                `json:"speciesId"`
-	Timestamp      time.Time            `json:"timestamp"`
-	Method         db.ObservationMethod `json:"method"`
-	AppearanceTime struct {
-		Start int `json:"start"`
-		End   int `json:"end"`
-	} `json:"appearanceIime"`
-	Temperature *int32   `json:"temperature"`
-	Narrative   *string  `json:"narrative"`
-	Confidence  *float32 `json:"confidence"`
-	Indicator   bool     `json:"indicator"`
-	Reportable  bool     `json:"reportable"`
+const defaultPageSize = 100
+
+type ListObservationsRequest struct {
+	stats.ObservationStatsInput
+	ExpandRequest
+	Method        *db.ObservationMethod `form:"method"`
+	MinConfidence *float32              `form:"minConfidence" binding:"omitempty,min=0,max=1"`
+	Native        *bool                 `form:"native"`
+	Indicator     *bool                 `form:"indicator"`
+	Sort          string                `form:"sort" binding:"omitempty,oneof=timestamp -timestamp"`
+	Cursor        string                `form:"cursor"`
+	// Offset pages by position, as before cursors. Deprecated: use Cursor.
+	Offset int32 `form:"offset" binding:"omitempty,min=0"`
+	Limit  int32 `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+type ListObservationsResponse struct {
+	// Total counts every matching observation, not only this page
+	Total int64 `json:"total"`
+	// Count is Total under its former name. Deprecated: use Total, Count
+	// goes in the next release.
+	Count        int64               `json:"count"`
+	NextCursor   *string             `json:"nextCursor"`
+	Observations []ObservationDetail `json:"observations"`
+}
+
+// ObservationDetail is an observation with its site and species nested in
+// it when they are expanded.
+type ObservationDetail struct {
+	db.Observation
+	Site    *ObservationSite    `json:"site,omitempty"`
+	Species *ObservationSpecies `json:"species,omitempty"`
+}
+
+type ObservationSite struct {
+	ID     int64         `json:"id"`
+	Code   string        `json:"code"`
+	Name   *string       `json:"name"`
+	Block  int32         `json:"block"`
+	Tenure db.TenureType `json:"tenure"`
+	Forest db.ForestType `json:"forest"`
+}
+
+type ObservationSpecies struct {
+	ID             int64   `json:"id"`
+	ScientificName string  `json:"scientificName"`
+	CommonName     string  `json:"commonName"`
+	Taxa           db.Taxa `json:"taxa"`
+	Native         bool    `json:"native"`
+	Indicator      bool    `json:"indicator"`
+	Reportable     bool    `json:"reportable"`
+}
+
+type ExpandRequest struct {
+	// Expand lists what to nest in each observation: site, species or both
+	Expand string `form:"expand"`
+}
+
+// ObservationInput is the body of requests creating or editing an
+// observation. The timestamp is local time: its offset is ignored, as the
+// timestamps the API returns carry none.
+type ObservationInput struct {
+	SiteID          int64                `json:"siteId" binding:"required"`
+	SpeciesID       int64                `json:"speciesId" binding:"required"`
+	Timestamp       time.Time            `json:"timestamp" binding:"required"`
+	Method          db.ObservationMethod `json:"method" binding:"required"`
+	AppearanceStart *int32               `json:"appearanceStart" binding:"omitempty,min=0"`
+	AppearanceEnd   *int32               `json:"appearanceEnd" binding:"omitempty,min=0"`
+	Temperature     *int32               `json:"temperature"`
+	Narrative       *string              `json:"narrative"`
+	Confidence      *float32             `json:"confidence" binding:"omitempty,min=0,max=1"`
+	File            *string              `json:"file"`
+}
+
+func inputFromObservation(o db.Observation) ObservationInput {
+	return ObservationInput{
+		SiteID:          o.SiteID,
+		SpeciesID:       o.SpeciesID,
+		Timestamp:       o.Timestamp,
+		Method:          o.Method,
+		AppearanceStart: o.AppearanceStart,
+		AppearanceEnd:   o.AppearanceEnd,
+		Temperature:     o.Temperature,
+		Narrative:       o.Narrative,
+		Confidence:      o.Confidence,
+		File:            o.File,
+	}
+}
+
+// localTime keeps the wall clock of t, in the monitoring time zone.
+func localTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), config.TIMEZONE)
 }
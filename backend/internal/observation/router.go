@@ -2,8 +2,15 @@ package observation
 
 import "github.com/gin-gonic/gin"
 
-func Register(r gin.IRouter, ctl *Controller) {
+// Register adds the read routes to r and the editing routes to editor.
+func Register(r, editor gin.IRouter, ctl *Controller) {
 	g := r.Group("/observations")
 	g.GET("", ctl.ListObservations)
 	g.GET("/:id", ctl.GetObservationByID)
+
+	e := editor.Group("/observations")
+	e.POST("", ctl.CreateObservation)
+	e.PUT("/:id", ctl.UpdateObservation)
+	e.PATCH("/:id", ctl.PatchObservation)
+	e.DELETE("/:id", ctl.DeleteObservation)
 }
@@ -0,0 +1,146 @@
+// Package occupancy builds the site by occasion detection histories that
+// occupancy models, such as those of the R package unmarked, are fitted to.
+package occupancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MaxOccasions bounds the columns of a history, so that a short occasion
+// over a long period cannot build a huge matrix.
+const MaxOccasions = 1000
+
+var (
+	ErrNoOccasions      = errors.New("the period must end after it starts")
+	ErrTooManyOccasions = fmt.Errorf("the period has more than %d occasions, choose longer occasions or a shorter period", MaxOccasions)
+)
+
+// History is the detection history of a species: a row per site and a
+// column per occasion.
+type History struct {
+	Species    db.Species       `json:"species"`
+	Occasions  []Occasion       `json:"occasions"`
+	Detections []SiteDetections `json:"detections"`
+	Covariates []SiteCovariates `json:"covariates"`
+}
+
+// Occasion is a survey occasion, from Start up to but excluding End.
+type Occasion struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// SiteDetections holds, for each occasion, 1 when the species was detected
+// at the site, 0 when the site was surveyed without detecting it and nil
+// (NA) when it was not surveyed.
+type SiteDetections struct {
+	SiteCode  string   `json:"siteCode"`
+	Occasions []*int32 `json:"occasions"`
+}
+
+// SiteCovariates are the site covariates of the history, in the same order
+// as its detections.
+type SiteCovariates struct {
+	SiteCode string        `json:"siteCode"`
+	Block    int32         `json:"block"`
+	Tenure   db.TenureType `json:"tenure"`
+	Forest   db.ForestType `json:"forest"`
+}
+
+// Build works out the detection history of the species at the sites matching
+// params, over occasions of params.OccasionDays from params.StartTime to
+// params.EndTime. A site was surveyed in an occasion when one of its
+// deployments overlaps it, or when it has any observation in it.
+func Build(ctx context.Context, q db.Querier, species db.Species, params db.ListOccupancyOccasionsParams) (*History, error) {
+	length := time.Duration(params.OccasionDays) * 24 * time.Hour
+	if length <= 0 || !params.EndTime.After(params.StartTime) {
+		return nil, ErrNoOccasions
+	}
+	count := int((params.EndTime.Sub(params.StartTime) + length - 1) / length)
+	if count > MaxOccasions {
+		return nil, ErrTooManyOccasions
+	}
+
+	h := &History{Species: species}
+	for start := params.StartTime; start.Before(params.EndTime); start = start.Add(length) {
+		// The last occasion is cut short at the end of the period
+		end := start.Add(length)
+		if end.After(params.EndTime) {
+			end = params.EndTime
+		}
+		h.Occasions = append(h.Occasions, Occasion{Start: start, End: end})
+	}
+
+	sites, err := q.ListOccupancySites(ctx, db.ListOccupancySitesParams{
+		Block:    params.Block,
+		Tenure:   params.Tenure,
+		Forest:   params.Forest,
+		SiteCode: params.SiteCode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sites: %w", err)
+	}
+	rows := make(map[int64][]*int32, len(sites))
+	for _, s := range sites {
+		row := make([]*int32, len(h.Occasions))
+		rows[s.ID] = row
+		h.Detections = append(h.Detections, SiteDetections{SiteCode: s.Code, Occasions: row})
+		h.Covariates = append(h.Covariates, SiteCovariates{
+			SiteCode: s.Code,
+			Block:    s.Block,
+			Tenure:   s.Tenure,
+			Forest:   s.Forest,
+		})
+	}
+
+	deployments, err := q.ListDeployments(ctx, db.ListDeploymentsParams{
+		SiteCode: params.SiteCode,
+		Method:   params.Method,
+		From:     pgtype.Timestamp{Time: params.StartTime, Valid: true},
+		To:       pgtype.Timestamp{Time: params.EndTime, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments {
+		row, ok := rows[d.SiteID]
+		if !ok {
+			continue
+		}
+		first := max(int(d.StartTime.Sub(params.StartTime)/length), 0)
+		last := min(int((d.EndTime.Sub(params.StartTime)+length-1)/length), len(row))
+		for i := first; i < last; i++ {
+			if row[i] == nil {
+				row[i] = detection(0)
+			}
+		}
+	}
+
+	params.SpeciesID = species.ID
+	occasions, err := q.ListOccupancyOccasions(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list observed occasions: %w", err)
+	}
+	for _, o := range occasions {
+		row, ok := rows[o.SiteID]
+		if !ok || int(o.Occasion) >= len(row) {
+			continue
+		}
+		if o.Detected {
+			row[o.Occasion] = detection(1)
+		} else if row[o.Occasion] == nil {
+			row[o.Occasion] = detection(0)
+		}
+	}
+	return h, nil
+}
+
+func detection(v int32) *int32 {
+	return &v
+}
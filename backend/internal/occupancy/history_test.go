@@ -0,0 +1,188 @@
+package occupancy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 10, d, 0, 0, 0, 0, time.UTC)
+}
+
+// historyQuerier serves three sites: A deployed over the first two weeks,
+// B over the last one and C never surveyed.
+type historyQuerier struct {
+	db.Querier
+}
+
+func (historyQuerier) ListOccupancySites(ctx context.Context, arg db.ListOccupancySitesParams) ([]db.Site, error) {
+	return []db.Site{
+		{ID: 1, Code: "A", Block: 1, Tenure: db.TenureTypePublic, Forest: db.ForestTypeDry},
+		{ID: 2, Code: "B", Block: 1, Tenure: db.TenureTypePrivate, Forest: db.ForestTypeWet},
+		{ID: 3, Code: "C", Block: 2, Tenure: db.TenureTypePublic, Forest: db.ForestTypeWet},
+	}, nil
+}
+
+func (historyQuerier) ListDeployments(ctx context.Context, arg db.ListDeploymentsParams) ([]db.Deployment, error) {
+	return []db.Deployment{
+		{SiteID: 1, StartTime: day(1).Add(12 * time.Hour), EndTime: day(9)},
+		{SiteID: 2, StartTime: day(16), EndTime: day(30)},
+		// Not one of the sites listed
+		{SiteID: 9, StartTime: day(1), EndTime: day(30)},
+	}, nil
+}
+
+func (historyQuerier) ListOccupancyOccasions(ctx context.Context, arg db.ListOccupancyOccasionsParams) ([]db.ListOccupancyOccasionsRow, error) {
+	if arg.SpeciesID != 7 {
+		return nil, errors.New("unexpected species")
+	}
+	return []db.ListOccupancyOccasionsRow{
+		{SiteID: 1, Occasion: 0, Detected: true},
+		// Other species observed without a deployment
+		{SiteID: 1, Occasion: 2, Detected: false},
+		{SiteID: 2, Occasion: 2, Detected: false},
+		{SiteID: 9, Occasion: 1, Detected: true},
+		{SiteID: 2, Occasion: 5, Detected: true},
+	}, nil
+}
+
+func TestBuild(t *testing.T) {
+	h, err := Build(context.Background(), historyQuerier{}, db.Species{ID: 7}, db.ListOccupancyOccasionsParams{
+		StartTime:    day(1),
+		EndTime:      day(22),
+		OccasionDays: 7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]*int32{
+		"A": {detection(1), detection(0), detection(0)},
+		"B": {nil, nil, detection(0)},
+		"C": {nil, nil, nil},
+	}
+	if len(h.Detections) != len(want) {
+		t.Fatalf("got %d sites, want %d", len(h.Detections), len(want))
+	}
+	for i, site := range h.Detections {
+		if !slices.EqualFunc(site.Occasions, want[site.SiteCode], func(a, b *int32) bool {
+			return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+		}) {
+			t.Errorf("site %s = %s, want %s", site.SiteCode, format(site.Occasions), format(want[site.SiteCode]))
+		}
+		if h.Covariates[i].SiteCode != site.SiteCode {
+			t.Errorf("covariate %d is of site %s, detections of %s", i, h.Covariates[i].SiteCode, site.SiteCode)
+		}
+	}
+}
+
+func TestBuildOccasions(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end time.Time
+		days       int32
+		want       []Occasion
+		err        error
+	}{
+		{
+			"whole occasions", day(1), day(15), 7,
+			[]Occasion{{day(1), day(8)}, {day(8), day(15)}}, nil,
+		},
+		{
+			"last occasion cut short", day(1), day(18), 7,
+			[]Occasion{{day(1), day(8)}, {day(8), day(15)}, {day(15), day(18)}}, nil,
+		},
+		{"end before start", day(10), day(1), 7, nil, ErrNoOccasions},
+		{"no occasion length", day(1), day(10), 0, nil, ErrNoOccasions},
+		{"too many occasions", day(1), day(1).AddDate(3, 0, 0), 1, nil, ErrTooManyOccasions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := Build(context.Background(), historyQuerier{}, db.Species{ID: 7}, db.ListOccupancyOccasionsParams{
+				StartTime:    tt.start,
+				EndTime:      tt.end,
+				OccasionDays: tt.days,
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !slices.Equal(h.Occasions, tt.want) {
+				t.Errorf("occasions = %v, want %v", h.Occasions, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	h, err := Build(context.Background(), historyQuerier{}, db.Species{ID: 7}, db.ListOccupancyOccasionsParams{
+		StartTime:    day(1),
+		EndTime:      day(22),
+		OccasionDays: 7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, h); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file string
+		want [][]string
+	}{
+		{"detections.csv", [][]string{
+			{"site", "2024-10-01", "2024-10-08", "2024-10-15"},
+			{"A", "1", "0", "0"},
+			{"B", "NA", "NA", "0"},
+			{"C", "NA", "NA", "NA"},
+		}},
+		{"covariates.csv", [][]string{
+			{"site", "block", "tenure", "forest"},
+			{"A", "1", "public", "dry"},
+			{"B", "1", "private", "wet"},
+			{"C", "2", "public", "wet"},
+		}},
+	}
+	for _, tt := range tests {
+		f, err := archive.Open(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(got, tt.want, slices.Equal) {
+			t.Errorf("%s = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func format(row []*int32) []string {
+	s := make([]string, len(row))
+	for i, v := range row {
+		s[i] = "NA"
+		if v != nil {
+			s[i] = strconv.Itoa(int(*v))
+		}
+	}
+	return s
+}
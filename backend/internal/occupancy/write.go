@@ -0,0 +1,80 @@
+package occupancy
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// dateLayout names the occasions in the detections header by their start.
+const dateLayout = "2006-01-02"
+
+// Write writes the history as a zip holding detections.csv, a row per site
+// with 1, 0 or NA for each occasion, and covariates.csv, a row per site with
+// its block, tenure and forest. Both list the sites in the same order, as
+// unmarked's unmarkedFrameOccu expects.
+func Write(w io.Writer, h *History) error {
+	archive := zip.NewWriter(w)
+
+	f, err := archive.Create("detections.csv")
+	if err != nil {
+		return err
+	}
+	if err := writeDetections(f, h); err != nil {
+		return fmt.Errorf("failed to write detections.csv: %w", err)
+	}
+
+	f, err = archive.Create("covariates.csv")
+	if err != nil {
+		return err
+	}
+	if err := writeCovariates(f, h); err != nil {
+		return fmt.Errorf("failed to write covariates.csv: %w", err)
+	}
+
+	return archive.Close()
+}
+
+func writeDetections(w io.Writer, h *History) error {
+	out := csv.NewWriter(w)
+	record := make([]string, len(h.Occasions)+1)
+	record[0] = "site"
+	for i, o := range h.Occasions {
+		record[i+1] = o.Start.Format(dateLayout)
+	}
+	if err := out.Write(record); err != nil {
+		return err
+	}
+
+	for _, site := range h.Detections {
+		record[0] = site.SiteCode
+		for i, v := range site.Occasions {
+			record[i+1] = "NA"
+			if v != nil {
+				record[i+1] = strconv.Itoa(int(*v))
+			}
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func writeCovariates(w io.Writer, h *History) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"site", "block", "tenure", "forest"}); err != nil {
+		return err
+	}
+	for _, site := range h.Covariates {
+		record := []string{site.SiteCode, strconv.Itoa(int(site.Block)), string(site.Tenure), string(site.Forest)}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
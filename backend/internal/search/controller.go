@@ -0,0 +1,103 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	q db.Querier
+}
+
+func NewController(queries db.Querier) *Controller {
+	return &Controller{
+		q: queries,
+	}
+}
+
+// Search godoc
+//
+//	@Summary		Search
+//	@Description	Full-text search of site codes and names, species common and scientific names, and observation narratives. Every word of the query must match, as a word prefix and ignoring case and plural forms. Results are ranked and grouped by type, observations also matching by their site or species; limit and offset page each group.
+//	@Tags			search
+//	@Param			request	query	SearchRequest	true	"Query"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	SearchResponse
+//	@Failure		400	{object}	utils.HttpError
+//	@Router			/search [get]
+func (u *Controller) Search(c *gin.Context) {
+	var req SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	query := tsQuery(req.Query)
+	if query == "" {
+		c.Error(utils.NewHttpError(400, "validation failed", errors.New("the query has no word to search for")))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultLimit
+	}
+
+	ctx := c.Request.Context()
+	res := SearchResponse{Query: req.Query}
+	if req.Type == "" || req.Type == TypeSites {
+		rows, err := u.q.FullTextSearchSites(ctx, db.FullTextSearchSitesParams{
+			Query:  query,
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+		if err != nil {
+			c.Error(fmt.Errorf("failed to search sites: %w", err))
+			return
+		}
+		total, err := u.q.CountSearchSites(ctx, query)
+		if err != nil {
+			c.Error(fmt.Errorf("failed to count matching sites: %w", err))
+			return
+		}
+		res.Sites = &SiteResults{Total: total, Results: rows}
+	}
+	if req.Type == "" || req.Type == TypeSpecies {
+		rows, err := u.q.FullTextSearchSpecies(ctx, db.FullTextSearchSpeciesParams{
+			Query:  query,
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+		if err != nil {
+			c.Error(fmt.Errorf("failed to search species: %w", err))
+			return
+		}
+		total, err := u.q.CountSearchSpecies(ctx, query)
+		if err != nil {
+			c.Error(fmt.Errorf("failed to count matching species: %w", err))
+			return
+		}
+		res.Species = &SpeciesResults{Total: total, Results: rows}
+	}
+	if req.Type == "" || req.Type == TypeObservations {
+		rows, err := u.q.FullTextSearchObservations(ctx, db.FullTextSearchObservationsParams{
+			Query:  query,
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+		if err != nil {
+			c.Error(fmt.Errorf("failed to search observations: %w", err))
+			return
+		}
+		total, err := u.q.CountSearchObservations(ctx, query)
+		if err != nil {
+			c.Error(fmt.Errorf("failed to count matching observations: %w", err))
+			return
+		}
+		res.Observations = &ObservationResults{Total: total, Results: rows}
+	}
+
+	c.JSON(200, res)
+}
@@ -0,0 +1,44 @@
+package search
+
+import "github.com/biomonash/nillumbik/internal/db"
+
+const defaultLimit = 10
+
+// Entity types results are grouped by.
+const (
+	TypeSites        = "sites"
+	TypeSpecies      = "species"
+	TypeObservations = "observations"
+)
+
+type SearchRequest struct {
+	Query string `form:"q" binding:"required"`
+	// Type limits the search to one entity type
+	Type   string `form:"type" binding:"omitempty,oneof=sites species observations"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int32  `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SearchResponse holds a page of ranked results for each entity type
+// searched, with the total number of matches of each.
+type SearchResponse struct {
+	Query        string              `json:"query"`
+	Sites        *SiteResults        `json:"sites,omitempty"`
+	Species      *SpeciesResults     `json:"species,omitempty"`
+	Observations *ObservationResults `json:"observations,omitempty"`
+}
+
+type SiteResults struct {
+	Total   int64                       `json:"total"`
+	Results []db.FullTextSearchSitesRow `json:"results"`
+}
+
+type SpeciesResults struct {
+	Total   int64                         `json:"total"`
+	Results []db.FullTextSearchSpeciesRow `json:"results"`
+}
+
+type ObservationResults struct {
+	Total   int64                              `json:"total"`
+	Results []db.FullTextSearchObservationsRow `json:"results"`
+}
@@ -0,0 +1,20 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tsQuery turns free text into a to_tsquery expression matching every word
+// as a prefix, so that results show up while a word is being typed. Anything
+// but letters and digits separates words, which keeps tsquery operators in
+// the text from reaching PostgreSQL. It returns "" when there is no word.
+func tsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,25 @@
+package search
+
+import "testing"
+
+func TestTsQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"superb lyre", "superb:* & lyre:*"},
+		{"  Superb   Lyrebird ", "Superb:* & Lyrebird:*"},
+		{"SG01", "SG01:*"},
+		{"sugar-glider", "sugar:* & glider:*"},
+		// Operators in the text are dropped
+		{"a & !b | (c:*)", "a:* & b:* & c:*"},
+		{"émeu", "émeu:*"},
+		{"", ""},
+		{"&!|", ""},
+	}
+	for _, tt := range tests {
+		if got := tsQuery(tt.text); got != tt.want {
+			t.Errorf("tsQuery(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package search
+
+import "github.com/gin-gonic/gin"
+
+func Register(r gin.IRouter, ctl *Controller) {
+	r.GET("/search", ctl.Search)
+}
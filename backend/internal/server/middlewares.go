@@ -1,9 +1,13 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 
+	"github.com/biomonash/nillumbik/internal/auth"
+	"github.com/biomonash/nillumbik/internal/db"
 	"github.com/biomonash/nillumbik/internal/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -49,3 +53,108 @@ func panicRecovery() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// credentialErrorKey holds why the credentials of a request were refused.
+const credentialErrorKey = "credentialError"
+
+// anyone lets anonymous requests through requireRole, which still refuses
+// bad credentials and API keys without the scopes.
+const anyone db.UserRole = ""
+
+// authenticate identifies the user of a request by its bearer session token
+// or basic email and password, or else the API key it carries. Requests
+// without credentials, or with invalid ones, carry on anonymously: only
+// requireRole refuses bad credentials, so that public routes keep working
+// with a stale token.
+func authenticate(q db.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(auth.APIKeyHeader); key != "" {
+			apiKey, err := auth.AuthenticateKey(c.Request.Context(), q, key)
+			if errors.Is(err, auth.ErrInvalidAPIKey) {
+				c.Set(credentialErrorKey, err)
+				c.Next()
+				return
+			}
+			if err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			auth.SetAPIKey(c, apiKey)
+			c.Next()
+			return
+		}
+
+		var user db.User
+		var err error
+		if token, ok := auth.BearerToken(c.Request); ok {
+			user, err = auth.Authenticate(c.Request.Context(), q, token)
+		} else if email, password, ok := c.Request.BasicAuth(); ok {
+			user, err = auth.CheckCredentials(c.Request.Context(), q, email, password)
+		} else {
+			c.Next()
+			return
+		}
+
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrInvalidCredentials) {
+			c.Set(credentialErrorKey, err)
+			c.Next()
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		auth.SetUser(c, user)
+		c.Next()
+	}
+}
+
+// requireRole only lets through users with at least the given role, and
+// API keys with any of the given scopes. With the role anyone, anonymous
+// requests are let through too.
+func requireRole(role db.UserRole, scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err, ok := c.Get(credentialErrorKey); ok {
+			unauthorized(c, err.(error))
+			return
+		}
+		if key, ok := auth.CurrentAPIKey(c); ok {
+			for _, scope := range scopes {
+				if slices.Contains(key.Scopes, scope) {
+					c.Next()
+					return
+				}
+			}
+			forbidden(c, fmt.Errorf("API key %s lacks the scope for this route", key.Prefix))
+			return
+		}
+
+		user, ok := auth.CurrentUser(c)
+		if !ok && role == anyone {
+			c.Next()
+			return
+		}
+		if !ok {
+			unauthorized(c, errors.New("no credentials"))
+			return
+		}
+		if role != anyone && !auth.Allows(user.Role, role) {
+			forbidden(c, fmt.Errorf("requires the %s role", role))
+			return
+		}
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.Header("WWW-Authenticate", `Bearer realm="nillumbik"`)
+	c.Error(utils.NewHttpError(http.StatusUnauthorized, "authentication required", err))
+	c.Abort()
+}
+
+func forbidden(c *gin.Context, err error) {
+	c.Error(utils.NewHttpError(http.StatusForbidden, "forbidden", err))
+	c.Abort()
+}
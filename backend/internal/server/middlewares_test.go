@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/auth"
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Credentials the stub querier knows.
+const (
+	viewerToken    = "viewer-token"
+	ecologistToken = "ecologist-token"
+	adminToken     = "admin-token"
+	statsKey       = "nlk_statskey"
+	exportKey      = "nlk_exportkey"
+	importKey      = "nlk_importkey"
+	noScopeKey     = "nlk_noscopekey"
+)
+
+type authQuerier struct {
+	db.Querier
+}
+
+func (authQuerier) GetSessionUser(ctx context.Context, hash []byte) (db.User, error) {
+	for token, role := range map[string]db.UserRole{
+		viewerToken:    db.UserRoleViewer,
+		ecologistToken: db.UserRoleEcologist,
+		adminToken:     db.UserRoleAdmin,
+	} {
+		if bytes.Equal(hash, auth.HashToken(token)) {
+			return db.User{Email: string(role) + "@example.org", Role: role}, nil
+		}
+	}
+	return db.User{}, pgx.ErrNoRows
+}
+
+func (authQuerier) UseAPIKey(ctx context.Context, hash []byte) (db.ApiKey, error) {
+	for key, scopes := range map[string][]string{
+		statsKey:   {auth.ScopeStatsRead},
+		exportKey:  {auth.ScopeExport},
+		importKey:  {auth.ScopeImport},
+		noScopeKey: {},
+	} {
+		if bytes.Equal(hash, auth.HashToken(key)) {
+			return db.ApiKey{Prefix: key[:8], Scopes: scopes}, nil
+		}
+	}
+	return db.ApiKey{}, pgx.ErrNoRows
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(errorHandler(), authenticate(authQuerier{}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/public", ok)
+	r.GET("/signed-in", requireRole(db.UserRoleViewer), ok)
+	r.GET("/ecologist", requireRole(db.UserRoleEcologist), ok)
+	r.GET("/admin", requireRole(db.UserRoleAdmin), ok)
+	r.GET("/stats", requireRole(anyone, auth.ScopeStatsRead), ok)
+	r.GET("/export", requireRole(db.UserRoleViewer, auth.ScopeExport), ok)
+	r.GET("/import", requireRole(db.UserRoleEcologist, auth.ScopeImport), ok)
+
+	header := func(name, value string) http.Header {
+		h := http.Header{}
+		h.Set(name, value)
+		return h
+	}
+	bearer := func(token string) http.Header {
+		return header("Authorization", "Bearer "+token)
+	}
+	apiKey := func(key string) http.Header {
+		return header(auth.APIKeyHeader, key)
+	}
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		want   int
+	}{
+		{"anonymous on a public route", "/public", nil, http.StatusOK},
+		{"anonymous", "/signed-in", nil, http.StatusUnauthorized},
+		{"unknown token on a public route", "/public", bearer("stale"), http.StatusOK},
+		{"unknown token", "/signed-in", bearer("stale"), http.StatusUnauthorized},
+		{"unknown key on a public route", "/public", apiKey("nlk_unknown"), http.StatusOK},
+		{"viewer", "/signed-in", bearer(viewerToken), http.StatusOK},
+		{"viewer editing", "/ecologist", bearer(viewerToken), http.StatusForbidden},
+		{"ecologist editing", "/ecologist", bearer(ecologistToken), http.StatusOK},
+		{"ecologist managing users", "/admin", bearer(ecologistToken), http.StatusForbidden},
+		{"admin editing", "/ecologist", bearer(adminToken), http.StatusOK},
+		{"admin managing users", "/admin", bearer(adminToken), http.StatusOK},
+		{"viewer exporting", "/export", bearer(viewerToken), http.StatusOK},
+		{"key with the scope exporting", "/export", apiKey(exportKey), http.StatusOK},
+		{"key without the scope exporting", "/export", apiKey(noScopeKey), http.StatusForbidden},
+		{"key editing", "/ecologist", apiKey(exportKey), http.StatusForbidden},
+		{"key signed in", "/signed-in", apiKey(exportKey), http.StatusForbidden},
+		{"unknown key", "/export", apiKey("nlk_unknown"), http.StatusUnauthorized},
+		{"key without the prefix", "/export", apiKey("exportkey"), http.StatusUnauthorized},
+		{"anonymous reading stats", "/stats", nil, http.StatusOK},
+		{"viewer reading stats", "/stats", bearer(viewerToken), http.StatusOK},
+		{"key with the scope reading stats", "/stats", apiKey(statsKey), http.StatusOK},
+		{"key without the scope reading stats", "/stats", apiKey(exportKey), http.StatusForbidden},
+		{"unknown token reading stats", "/stats", bearer("stale"), http.StatusUnauthorized},
+		{"unknown key reading stats", "/stats", apiKey("nlk_unknown"), http.StatusUnauthorized},
+		{"key with the scope importing", "/import", apiKey(importKey), http.StatusOK},
+		{"key without the scope importing", "/import", apiKey(statsKey), http.StatusForbidden},
+		{"ecologist importing", "/import", bearer(ecologistToken), http.StatusOK},
+		{"viewer importing", "/import", bearer(viewerToken), http.StatusForbidden},
+		{"anonymous importing", "/import", nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
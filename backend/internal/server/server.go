@@ -2,8 +2,14 @@ package server
 
 import (
 	"github.com/biomonash/nillumbik/assets"
+	"github.com/biomonash/nillumbik/internal/auth"
 	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/deployment"
+	"github.com/biomonash/nillumbik/internal/export"
+	"github.com/biomonash/nillumbik/internal/importer"
+	"github.com/biomonash/nillumbik/internal/imports"
 	"github.com/biomonash/nillumbik/internal/observation"
+	"github.com/biomonash/nillumbik/internal/search"
 	"github.com/biomonash/nillumbik/internal/site"
 	"github.com/biomonash/nillumbik/internal/species"
 	"github.com/biomonash/nillumbik/internal/stats"
@@ -34,27 +40,61 @@ type Server struct {
 
 //	@securityDefinitions.basic	BasicAuth
 
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Session token from /auth/login, as "Bearer <token>"
+
+//	@securityDefinitions.apikey	APIKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key from /api-keys, for scripts and partners
+
 // @externalDocs.description	OpenAPI
 // @externalDocs.url			https://swagger.io/resources/open-api/
-func New(querier db.Querier) *Server {
+func New(conn importer.Conn) *Server {
+	querier := db.New(conn)
 	r := gin.New()
 
 	r.Use(gin.Logger())
 	r.Use(panicRecovery())
 	r.Use(errorHandler())
-	r.Use(cors.Default()) // TODO: development only
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AddAllowHeaders("Authorization", auth.APIKeyHeader)
+	r.Use(cors.New(corsConfig)) // TODO: development only
 
 	r.NoRoute(assets.Serve)
 
 	api := r.Group("/api")
+	api.Use(authenticate(querier))
+
+	// Reading stays public for the dashboards, editing needs a role. API
+	// keys get at the stats, exports and imports through their scopes.
+	signedIn := api.Group("", requireRole(db.UserRoleViewer))
+	ecologist := api.Group("", requireRole(db.UserRoleEcologist))
+	admin := api.Group("", requireRole(db.UserRoleAdmin))
+	statsReaders := api.Group("", requireRole(anyone, auth.ScopeStatsRead))
+	exporters := api.Group("", requireRole(db.UserRoleViewer, auth.ScopeExport))
+	importers := api.Group("", requireRole(db.UserRoleEcologist, auth.ScopeImport))
+
+	auth.Register(api, signedIn, admin, auth.NewController(querier))
+
+	site.Register(api, ecologist, site.NewController(querier))
+
+	species.Register(api, ecologist, species.NewController(querier))
+
+	observation.Register(api, ecologist, observation.NewController(querier))
+
+	deployment.Register(api, ecologist, deployment.NewController(querier))
 
-	site.Register(api, site.NewController(querier))
+	stats.Register(statsReaders, stats.NewController(querier))
 
-	species.Register(api, species.NewController(querier))
+	search.Register(api, search.NewController(querier))
 
-	observation.Register(api, observation.NewController(querier))
+	export.Register(exporters, export.NewController(querier))
 
-	stats.Register(api, stats.NewController(querier))
+	imports.Register(importers, imports.NewController(conn))
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -61,3 +61,179 @@ func (u *Controller) GetSiteByCode(c *gin.Context) {
 
 	c.JSON(200, site)
 }
+
+// SearchSites godoc
+//
+//	@Summary		Search sites
+//	@Description	Find the sites whose code or name contains the query, ignoring case
+//	@Tags			site
+//	@Param			q	query	string	true	"Text to search for"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]db.Site
+//	@Failure		400	{object}	utils.HttpError
+//	@Router			/sites/search [get]
+func (u *Controller) SearchSites(c *gin.Context) {
+	var req SearchSitesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	sites, err := u.q.SearchSites(c.Request.Context(), utils.ContainsPattern(req.Query))
+	if err != nil {
+		c.Error(fmt.Errorf("failed to search sites: %w", err))
+		return
+	}
+	c.JSON(200, sites)
+}
+
+// CreateSite godoc
+//
+//	@Summary		Create site
+//	@Description	Add a monitoring site
+//	@Tags			site
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			site	body	CreateSiteInput	true	"The site"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	db.Site
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		409	{object}	utils.HttpError
+//	@Router			/sites [post]
+func (u *Controller) CreateSite(c *gin.Context) {
+	var input CreateSiteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if err := validate(input.SiteInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	site, err := u.q.CreateSite(c.Request.Context(), db.CreateSiteParams{
+		Code:     input.Code,
+		Block:    input.Block,
+		Name:     input.Name,
+		Location: input.Location,
+		Tenure:   input.Tenure,
+		Forest:   input.Forest,
+	})
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "Site code already exists", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to create site: %w", err))
+		return
+	}
+
+	c.JSON(201, site)
+}
+
+// UpdateSite godoc
+//
+//	@Summary		Update site
+//	@Description	Replace the values of a site. Its code cannot change.
+//	@Tags			site
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			code	path	string		True	"Code of the site"
+//	@Param			site	body	SiteInput	true	"The site"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	db.Site
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Router			/sites/{code} [put]
+func (u *Controller) UpdateSite(c *gin.Context) {
+	var input SiteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if err := validate(input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	site, err := u.q.UpdateSiteByCode(c.Request.Context(), db.UpdateSiteByCodeParams{
+		Code:     c.Param("code"),
+		Block:    input.Block,
+		Name:     input.Name,
+		Location: input.Location,
+		Tenure:   input.Tenure,
+		Forest:   input.Forest,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "Site code not found", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to update site: %w", err))
+		return
+	}
+
+	c.JSON(200, site)
+}
+
+// DeleteSite godoc
+//
+//	@Summary		Delete site
+//	@Description	Delete a site. A site with observations or deployments is only deleted, along with them, when cascade is set.
+//	@Tags			site
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			code	path	string	True	"Code of the site"
+//	@Param			cascade	query	bool	false	"Delete the observations and deployments of the site too"
+//	@Success		204
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Failure		409	{object}	utils.HttpError
+//	@Router			/sites/{code} [delete]
+func (u *Controller) DeleteSite(c *gin.Context) {
+	var req DeleteSiteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+
+	code := c.Param("code")
+	var deleted int64
+	var err error
+	if req.Cascade {
+		deleted, err = u.q.DeleteSiteByCodeCascade(c.Request.Context(), code)
+	} else {
+		deleted, err = u.q.DeleteSiteByCode(c.Request.Context(), code)
+	}
+	if utils.IsForeignKeyViolation(err) {
+		c.Error(utils.NewHttpError(409, "Site has observations or deployments, delete with cascade=true to remove them too", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to delete site: %w", err))
+		return
+	}
+	if deleted == 0 {
+		c.Error(utils.NewHttpError(404, "Site code not found", pgx.ErrNoRows))
+		return
+	}
+
+	c.Status(204)
+}
+
+func validate(input SiteInput) error {
+	if !input.Tenure.Valid() {
+		return utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown tenure %q", input.Tenure))
+	}
+	if !input.Forest.Valid() {
+		return utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown forest %q", input.Forest))
+	}
+	return nil
+}
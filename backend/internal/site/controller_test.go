@@ -0,0 +1,225 @@
+package site
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// siteQuerier keeps sites by code, with the number of observations of each.
+// Like the foreign keys, it refuses to delete a site with observations
+// unless the delete cascades.
+type siteQuerier struct {
+	db.Querier
+	sites        map[string]db.Site
+	observations map[string]int
+	pattern      string
+}
+
+func newSiteQuerier() *siteQuerier {
+	return &siteQuerier{
+		sites: map[string]db.Site{
+			"NIL01": {ID: 1, Code: "NIL01", Block: 1, Tenure: db.TenureTypePublic, Forest: db.ForestTypeDry},
+			"NIL02": {ID: 2, Code: "NIL02", Block: 1, Tenure: db.TenureTypePrivate, Forest: db.ForestTypeWet},
+		},
+		observations: map[string]int{"NIL01": 3},
+	}
+}
+
+func (q *siteQuerier) SearchSites(ctx context.Context, pattern string) ([]db.Site, error) {
+	q.pattern = pattern
+	return []db.Site{}, nil
+}
+
+func (q *siteQuerier) CreateSite(ctx context.Context, arg db.CreateSiteParams) (db.Site, error) {
+	if _, ok := q.sites[arg.Code]; ok {
+		return db.Site{}, &pgconn.PgError{Code: "23505"}
+	}
+	site := db.Site{ID: int64(len(q.sites) + 1), Code: arg.Code, Block: arg.Block, Name: arg.Name, Location: arg.Location,
+		Tenure: arg.Tenure, Forest: arg.Forest}
+	q.sites[arg.Code] = site
+	return site, nil
+}
+
+func (q *siteQuerier) UpdateSiteByCode(ctx context.Context, arg db.UpdateSiteByCodeParams) (db.Site, error) {
+	site, ok := q.sites[arg.Code]
+	if !ok {
+		return db.Site{}, pgx.ErrNoRows
+	}
+	site.Block, site.Name, site.Location, site.Tenure, site.Forest = arg.Block, arg.Name, arg.Location, arg.Tenure, arg.Forest
+	q.sites[arg.Code] = site
+	return site, nil
+}
+
+func (q *siteQuerier) DeleteSiteByCode(ctx context.Context, code string) (int64, error) {
+	if q.observations[code] > 0 {
+		return 0, &pgconn.PgError{Code: "23503"}
+	}
+	return q.DeleteSiteByCodeCascade(ctx, code)
+}
+
+func (q *siteQuerier) DeleteSiteByCodeCascade(ctx context.Context, code string) (int64, error) {
+	if _, ok := q.sites[code]; !ok {
+		return 0, nil
+	}
+	delete(q.sites, code)
+	delete(q.observations, code)
+	return 1, nil
+}
+
+// serve runs handler on a request to target, returning the status the
+// error handler would answer with.
+func serve(handler gin.HandlerFunc, method, target, code, body string) (int, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if code != "" {
+		c.Params = gin.Params{{Key: "code", Value: code}}
+	}
+	handler(c)
+	if len(c.Errors) == 0 {
+		return c.Writer.Status(), w
+	}
+	var httpErr utils.HttpError
+	if errors.As(c.Errors.Last().Err, &httpErr) {
+		return httpErr.Code, w
+	}
+	return http.StatusInternalServerError, w
+}
+
+func TestSearchSites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		query   string
+		want    int
+		pattern string
+	}{
+		{"code", "q=NIL", 200, "%NIL%"},
+		{"wildcards are literal", "q=" + "50%25_off", 200, `%50\%\_off%`},
+		{"missing query", "", 400, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSiteQuerier()
+			code, _ := serve(NewController(q).SearchSites, http.MethodGet, "/sites/search?"+tt.query, "", "")
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if q.pattern != tt.pattern {
+				t.Errorf("pattern = %q, want %q", q.pattern, tt.pattern)
+			}
+		})
+	}
+}
+
+func TestCreateSite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"valid", `{"code":"NIL03","block":2,"name":"Sugarloaf","tenure":"public","forest":"wet"}`, 201},
+		{"existing code", `{"code":"NIL01","block":2,"tenure":"public","forest":"wet"}`, 409},
+		{"missing code", `{"block":2,"tenure":"public","forest":"wet"}`, 400},
+		{"missing block", `{"code":"NIL03","tenure":"public","forest":"wet"}`, 400},
+		{"unknown tenure", `{"code":"NIL03","block":2,"tenure":"crown","forest":"wet"}`, 400},
+		{"unknown forest", `{"code":"NIL03","block":2,"tenure":"public","forest":"damp"}`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSiteQuerier()
+			code, _ := serve(NewController(q).CreateSite, http.MethodPost, "/sites", "", tt.body)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if _, created := q.sites["NIL03"]; created != (tt.want == 201) {
+				t.Errorf("created = %v, want %v", created, tt.want == 201)
+			}
+		})
+	}
+}
+
+func TestUpdateSite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		code string
+		body string
+		want int
+	}{
+		{"valid", "NIL02", `{"block":3,"name":"Kangaroo Ground","tenure":"public","forest":"dry"}`, 200},
+		// The code in the body is not one of the values that can change
+		{"code in the body", "NIL02", `{"code":"NIL09","block":3,"name":"Kangaroo Ground","tenure":"public","forest":"dry"}`, 200},
+		{"unknown site", "NIL09", `{"block":3,"tenure":"public","forest":"dry"}`, 404},
+		{"unknown forest", "NIL02", `{"block":3,"tenure":"public","forest":"damp"}`, 400},
+		{"missing tenure", "NIL02", `{"block":3,"forest":"dry"}`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSiteQuerier()
+			code, w := serve(NewController(q).UpdateSite, http.MethodPut, "/sites/"+tt.code, tt.code, tt.body)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if tt.want != 200 {
+				return
+			}
+			var site db.Site
+			if err := json.Unmarshal(w.Body.Bytes(), &site); err != nil {
+				t.Fatal(err)
+			}
+			if site.Code != "NIL02" || site.Block != 3 || site.Tenure != db.TenureTypePublic || site.Forest != db.ForestTypeDry {
+				t.Errorf("site = %+v, want NIL02 in block 3, public and dry", site)
+			}
+			if _, ok := q.sites["NIL09"]; ok {
+				t.Error("site NIL09 was created")
+			}
+		})
+	}
+}
+
+func TestDeleteSite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		code    string
+		query   string
+		want    int
+		deleted bool
+	}{
+		{"without observations", "NIL02", "", 204, true},
+		{"with observations", "NIL01", "", 409, false},
+		{"with observations, cascading", "NIL01", "?cascade=true", 204, true},
+		{"not cascading", "NIL01", "?cascade=false", 409, false},
+		{"unknown site", "NIL09", "?cascade=true", 404, false},
+		{"invalid cascade", "NIL01", "?cascade=maybe", 400, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSiteQuerier()
+			code, _ := serve(NewController(q).DeleteSite, http.MethodDelete, "/sites/"+tt.code+tt.query, tt.code, "")
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if deleted := len(q.sites) < 2; deleted != tt.deleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.deleted)
+			}
+			if _, kept := q.observations[tt.code]; tt.code == "NIL01" && kept == tt.deleted {
+				t.Errorf("observations kept = %v, want %v", kept, !tt.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package site
+
+import "github.com/biomonash/nillumbik/internal/db"
+
+// SiteInput is the body of requests editing a site.
+type SiteInput struct {
+	Block    int32         `json:"block" binding:"required"`
+	Name     *string       `json:"name"`
+	Location *string       `json:"location"`
+	Tenure   db.TenureType `json:"tenure" binding:"required"`
+	Forest   db.ForestType `json:"forest" binding:"required"`
+}
+
+// CreateSiteInput is the body of requests creating a site.
+type CreateSiteInput struct {
+	Code string `json:"code" binding:"required"`
+	SiteInput
+}
+
+type SearchSitesRequest struct {
+	Query string `form:"q" binding:"required"`
+}
+
+type DeleteSiteRequest struct {
+	// Cascade deletes the observations of the site along with it
+	Cascade bool `form:"cascade"`
+}
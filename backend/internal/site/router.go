@@ -2,8 +2,15 @@ package site
 
 import "github.com/gin-gonic/gin"
 
-func Register(r gin.IRouter, ctl *Controller) {
+// Register adds the read routes to r and the editing routes to editor.
+func Register(r, editor gin.IRouter, ctl *Controller) {
 	g := r.Group("/sites")
 	g.GET("", ctl.ListSites)
+	g.GET("/search", ctl.SearchSites)
 	g.GET("/:code", ctl.GetSiteByCode)
+
+	e := editor.Group("/sites")
+	e.POST("", ctl.CreateSite)
+	e.PUT("/:code", ctl.UpdateSite)
+	e.DELETE("/:code", ctl.DeleteSite)
 }
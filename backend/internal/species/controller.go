@@ -167,3 +167,269 @@ func (u *Controller) GetObservedSpecies(c *gin.Context) {
 		Species: result,
 	})
 }
+
+// SpeciesInput is the body of requests creating or editing a species.
+type SpeciesInput struct {
+	ScientificName string  `json:"scientificName" binding:"required"`
+	CommonName     string  `json:"commonName" binding:"required"`
+	Native         bool    `json:"native"`
+	Taxa           db.Taxa `json:"taxa" binding:"required"`
+	Indicator      bool    `json:"indicator"`
+	Reportable     bool    `json:"reportable"`
+}
+
+type SearchSpeciesRequest struct {
+	Query string `form:"q" binding:"required"`
+}
+
+type MergeSpeciesRequest struct {
+	// TargetID is the species that keeps the observations
+	TargetID int64 `json:"targetId" binding:"required"`
+}
+
+// MergeSpeciesResponse is the merged species with the number of
+// observations moved to it and dropped as duplicates.
+type MergeSpeciesResponse struct {
+	Species               db.Species `json:"species"`
+	MovedObservations     int64      `json:"movedObservations"`
+	DuplicateObservations int64      `json:"duplicateObservations"`
+}
+
+// SearchSpecies godoc
+//
+//	@Summary		Search species
+//	@Description	Find the species whose scientific or common name contains the query, ignoring case
+//	@Tags			species
+//	@Param			q	query	string	true	"Text to search for"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]db.Species
+//	@Failure		400	{object}	utils.HttpError
+//	@Router			/species/search [get]
+func (u *Controller) SearchSpecies(c *gin.Context) {
+	var req SearchSpeciesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	species, err := u.q.SearchSpecies(c.Request.Context(), utils.ContainsPattern(req.Query))
+	if err != nil {
+		c.Error(fmt.Errorf("failed to search species: %w", err))
+		return
+	}
+	c.JSON(200, species)
+}
+
+// CreateSpecies godoc
+//
+//	@Summary		Create species
+//	@Description	Add a species to the catalogue
+//	@Tags			species
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			species	body	SpeciesInput	true	"The species"
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	db.Species
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		409	{object}	utils.HttpError
+//	@Router			/species [post]
+func (u *Controller) CreateSpecies(c *gin.Context) {
+	var input SpeciesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if !input.Taxa.Valid() {
+		c.Error(utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown taxa %q", input.Taxa)))
+		return
+	}
+
+	species, err := u.q.CreateSpecies(c.Request.Context(), db.CreateSpeciesParams{
+		ScientificName: input.ScientificName,
+		CommonName:     input.CommonName,
+		Native:         input.Native,
+		Taxa:           input.Taxa,
+		Indicator:      input.Indicator,
+		Reportable:     input.Reportable,
+	})
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "species already exists", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to create species: %w", err))
+		return
+	}
+
+	c.JSON(201, species)
+}
+
+// UpdateSpecies godoc
+//
+//	@Summary		Update species
+//	@Description	Replace the values of a species
+//	@Tags			species
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			id		path	int				true	"id of the species"
+//	@Param			species	body	SpeciesInput	true	"The species"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	db.Species
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Failure		409	{object}	utils.HttpError
+//	@Router			/species/{id} [put]
+func (u *Controller) UpdateSpecies(c *gin.Context) {
+	id, err := speciesID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var input SpeciesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if !input.Taxa.Valid() {
+		c.Error(utils.NewHttpError(400, "validation failed", fmt.Errorf("unknown taxa %q", input.Taxa)))
+		return
+	}
+
+	species, err := u.q.UpdateSpecies(c.Request.Context(), db.UpdateSpeciesParams{
+		ID:             id,
+		ScientificName: input.ScientificName,
+		CommonName:     input.CommonName,
+		Native:         input.Native,
+		Taxa:           input.Taxa,
+		Indicator:      input.Indicator,
+		Reportable:     input.Reportable,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "species not found", err))
+		return
+	}
+	if utils.IsUniqueViolation(err) {
+		c.Error(utils.NewHttpError(409, "another species has this scientific name, merge them instead", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to update species: %w", err))
+		return
+	}
+
+	c.JSON(200, species)
+}
+
+// DeleteSpecies godoc
+//
+//	@Summary		Delete species
+//	@Description	Delete a species without observations. Merge a species with observations into another instead.
+//	@Tags			species
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			id	path	int	true	"id of the species"
+//	@Success		204
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Failure		409	{object}	utils.HttpError
+//	@Router			/species/{id} [delete]
+func (u *Controller) DeleteSpecies(c *gin.Context) {
+	id, err := speciesID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	deleted, err := u.q.DeleteSpecies(c.Request.Context(), id)
+	if utils.IsForeignKeyViolation(err) {
+		c.Error(utils.NewHttpError(409, "species has observations, merge it into another species instead", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to delete species: %w", err))
+		return
+	}
+	if deleted == 0 {
+		c.Error(utils.NewHttpError(404, "species not found", pgx.ErrNoRows))
+		return
+	}
+
+	c.Status(204)
+}
+
+// MergeSpecies godoc
+//
+//	@Summary		Merge species
+//	@Description	Move the observations of a duplicate species to the target species, then delete the duplicate. Observations the target already has are dropped.
+//	@Tags			species
+//	@Security		BearerAuth
+//	@Security		BasicAuth
+//	@Param			id		path	int					true	"id of the duplicate species"
+//	@Param			merge	body	MergeSpeciesRequest	true	"The species to merge into"
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	MergeSpeciesResponse
+//	@Failure		400	{object}	utils.HttpError
+//	@Failure		401	{object}	utils.HttpError
+//	@Failure		403	{object}	utils.HttpError
+//	@Failure		404	{object}	utils.HttpError
+//	@Router			/species/{id}/merge [post]
+func (u *Controller) MergeSpecies(c *gin.Context) {
+	id, err := speciesID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var req MergeSpeciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(utils.NewHttpError(400, "validation failed", err))
+		return
+	}
+	if req.TargetID == id {
+		c.Error(utils.NewHttpError(400, "validation failed", errors.New("cannot merge a species into itself")))
+		return
+	}
+
+	target, err := u.q.GetSpecies(c.Request.Context(), req.TargetID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.Error(utils.NewHttpError(404, "target species not found", err))
+		return
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("failed to get species by id: %w", err))
+		return
+	}
+
+	merged, err := u.q.MergeSpecies(c.Request.Context(), db.MergeSpeciesParams{
+		TargetID: req.TargetID,
+		SourceID: id,
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("failed to merge species: %w", err))
+		return
+	}
+	if merged.DeletedSpecies == 0 {
+		c.Error(utils.NewHttpError(404, "species not found", pgx.ErrNoRows))
+		return
+	}
+
+	c.JSON(200, MergeSpeciesResponse{
+		Species:               target,
+		MovedObservations:     merged.MovedObservations,
+		DuplicateObservations: merged.DuplicateObservations,
+	})
+}
+
+func speciesID(c *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, utils.NewHttpError(400, "invalid id", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,325 @@
+package species
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// speciesQuerier keeps species by id and their observations. Like the
+// constraints, it refuses two species with one scientific name and deleting
+// a species with observations; MergeSpecies does what its query does.
+type speciesQuerier struct {
+	db.Querier
+	species      map[int64]db.Species
+	observations []db.Observation
+}
+
+func newSpeciesQuerier() *speciesQuerier {
+	at := func(day int) time.Time { return time.Date(2024, 10, day, 6, 30, 0, 0, time.UTC) }
+	file := "NIL01_20241005.wav"
+	return &speciesQuerier{
+		species: map[int64]db.Species{
+			1: {ID: 1, ScientificName: "Menura novaehollandiae", CommonName: "Superb Lyrebird", Native: true, Taxa: db.TaxaBird},
+			2: {ID: 2, ScientificName: "Menura novaehollandae", CommonName: "Superb Lyrebird", Native: true, Taxa: db.TaxaBird},
+			3: {ID: 3, ScientificName: "Vombatus ursinus", CommonName: "Common Wombat", Native: true, Taxa: db.TaxaMammal},
+		},
+		observations: []db.Observation{
+			{ID: 1, SiteID: 1, SpeciesID: 1, Timestamp: at(5), Method: db.ObservationMethodAudio, File: &file},
+			{ID: 2, SiteID: 1, SpeciesID: 1, Timestamp: at(6), Method: db.ObservationMethodAudio},
+			// The same as observation 1
+			{ID: 3, SiteID: 1, SpeciesID: 2, Timestamp: at(5), Method: db.ObservationMethodAudio, File: &file},
+			// Differs from observation 1 by the file, site or method
+			{ID: 4, SiteID: 1, SpeciesID: 2, Timestamp: at(5), Method: db.ObservationMethodAudio},
+			{ID: 5, SiteID: 2, SpeciesID: 2, Timestamp: at(5), Method: db.ObservationMethodAudio, File: &file},
+			{ID: 6, SiteID: 1, SpeciesID: 2, Timestamp: at(6), Method: db.ObservationMethodCamera},
+			// The same as observation 2
+			{ID: 7, SiteID: 1, SpeciesID: 2, Timestamp: at(6), Method: db.ObservationMethodAudio},
+		},
+	}
+}
+
+func (q *speciesQuerier) GetSpecies(ctx context.Context, id int64) (db.Species, error) {
+	s, ok := q.species[id]
+	if !ok {
+		return db.Species{}, pgx.ErrNoRows
+	}
+	return s, nil
+}
+
+func (q *speciesQuerier) CreateSpecies(ctx context.Context, arg db.CreateSpeciesParams) (db.Species, error) {
+	s := db.Species{ID: int64(len(q.species) + 1), ScientificName: arg.ScientificName, CommonName: arg.CommonName,
+		Native: arg.Native, Taxa: arg.Taxa, Indicator: arg.Indicator, Reportable: arg.Reportable}
+	return q.put(s)
+}
+
+func (q *speciesQuerier) UpdateSpecies(ctx context.Context, arg db.UpdateSpeciesParams) (db.Species, error) {
+	if _, ok := q.species[arg.ID]; !ok {
+		return db.Species{}, pgx.ErrNoRows
+	}
+	s := db.Species{ID: arg.ID, ScientificName: arg.ScientificName, CommonName: arg.CommonName,
+		Native: arg.Native, Taxa: arg.Taxa, Indicator: arg.Indicator, Reportable: arg.Reportable}
+	return q.put(s)
+}
+
+func (q *speciesQuerier) put(s db.Species) (db.Species, error) {
+	for _, other := range q.species {
+		if other.ID != s.ID && other.ScientificName == s.ScientificName {
+			return db.Species{}, &pgconn.PgError{Code: "23505"}
+		}
+	}
+	q.species[s.ID] = s
+	return s, nil
+}
+
+func (q *speciesQuerier) DeleteSpecies(ctx context.Context, id int64) (int64, error) {
+	if _, ok := q.species[id]; !ok {
+		return 0, nil
+	}
+	if slices.ContainsFunc(q.observations, func(o db.Observation) bool { return o.SpeciesID == id }) {
+		return 0, &pgconn.PgError{Code: "23503"}
+	}
+	delete(q.species, id)
+	return 1, nil
+}
+
+func (q *speciesQuerier) MergeSpecies(ctx context.Context, arg db.MergeSpeciesParams) (db.MergeSpeciesRow, error) {
+	var row db.MergeSpeciesRow
+	before := slices.Clone(q.observations)
+	q.observations = q.observations[:0]
+	for _, o := range before {
+		if o.SpeciesID != arg.SourceID {
+			q.observations = append(q.observations, o)
+			continue
+		}
+		duplicate := slices.ContainsFunc(before, func(t db.Observation) bool {
+			return t.SpeciesID == arg.TargetID && t.SiteID == o.SiteID && t.Timestamp.Equal(o.Timestamp) &&
+				t.Method == o.Method && deref(t.File) == deref(o.File)
+		})
+		if duplicate {
+			row.DuplicateObservations++
+			continue
+		}
+		o.SpeciesID = arg.TargetID
+		q.observations = append(q.observations, o)
+		row.MovedObservations++
+	}
+	if _, ok := q.species[arg.SourceID]; ok {
+		delete(q.species, arg.SourceID)
+		row.DeletedSpecies = 1
+	}
+	return row, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// speciesOf returns the ids of the observations of a species.
+func (q *speciesQuerier) speciesOf(id int64) []int64 {
+	var ids []int64
+	for _, o := range q.observations {
+		if o.SpeciesID == id {
+			ids = append(ids, o.ID)
+		}
+	}
+	return ids
+}
+
+// serve runs handler on a request to target, returning the status the
+// error handler would answer with.
+func serve(handler gin.HandlerFunc, method, target, id, body string) (int, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if id != "" {
+		c.Params = gin.Params{{Key: "id", Value: id}}
+	}
+	handler(c)
+	if len(c.Errors) == 0 {
+		return c.Writer.Status(), w
+	}
+	var httpErr utils.HttpError
+	if errors.As(c.Errors.Last().Err, &httpErr) {
+		return httpErr.Code, w
+	}
+	return http.StatusInternalServerError, w
+}
+
+func TestCreateSpecies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"valid", `{"scientificName":"Wallabia bicolor","commonName":"Swamp Wallaby","native":true,"taxa":"mammal"}`, 201},
+		{"existing scientific name", `{"scientificName":"Vombatus ursinus","commonName":"Wombat","taxa":"mammal"}`, 409},
+		{"missing common name", `{"scientificName":"Wallabia bicolor","taxa":"mammal"}`, 400},
+		{"unknown taxa", `{"scientificName":"Wallabia bicolor","commonName":"Swamp Wallaby","taxa":"marsupial"}`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSpeciesQuerier()
+			code, _ := serve(NewController(q).CreateSpecies, http.MethodPost, "/species", "", tt.body)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if created := len(q.species) == 4; created != (tt.want == 201) {
+				t.Errorf("created = %v, want %v", created, tt.want == 201)
+			}
+		})
+	}
+}
+
+func TestUpdateSpecies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		id   string
+		body string
+		want int
+	}{
+		{"valid", "3", `{"scientificName":"Vombatus ursinus","commonName":"Bare-nosed Wombat","native":true,"taxa":"mammal"}`, 200},
+		{"scientific name of another", "2", `{"scientificName":"Menura novaehollandiae","commonName":"Superb Lyrebird","taxa":"bird"}`, 409},
+		{"unknown species", "9", `{"scientificName":"Wallabia bicolor","commonName":"Swamp Wallaby","taxa":"mammal"}`, 404},
+		{"unknown taxa", "3", `{"scientificName":"Vombatus ursinus","commonName":"Wombat","taxa":"marsupial"}`, 400},
+		{"invalid id", "wombat", `{"scientificName":"Vombatus ursinus","commonName":"Wombat","taxa":"mammal"}`, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSpeciesQuerier()
+			code, w := serve(NewController(q).UpdateSpecies, http.MethodPut, "/species/"+tt.id, tt.id, tt.body)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if tt.want != 200 {
+				return
+			}
+			var s db.Species
+			if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+				t.Fatal(err)
+			}
+			if s.ID != 3 || s.CommonName != "Bare-nosed Wombat" || q.species[3] != s {
+				t.Errorf("species = %+v, want 3 renamed Bare-nosed Wombat", s)
+			}
+		})
+	}
+}
+
+func TestDeleteSpecies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name string
+		id   string
+		want int
+	}{
+		{"without observations", "3", 204},
+		{"with observations", "2", 409},
+		{"unknown species", "9", 404},
+		{"invalid id", "wombat", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSpeciesQuerier()
+			code, _ := serve(NewController(q).DeleteSpecies, http.MethodDelete, "/species/"+tt.id, tt.id, "")
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if deleted := len(q.species) == 2; deleted != (tt.want == 204) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.want == 204)
+			}
+		})
+	}
+}
+
+func TestMergeSpecies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		want       int
+		res        MergeSpeciesResponse
+		targetObs  []int64
+		deletedObs []int64
+	}{
+		{
+			name: "drops the observations the target has",
+			id:   "2",
+			body: `{"targetId":1}`,
+			want: 200,
+			res: MergeSpeciesResponse{
+				Species:               db.Species{ID: 1, ScientificName: "Menura novaehollandiae", CommonName: "Superb Lyrebird", Native: true, Taxa: db.TaxaBird},
+				MovedObservations:     3,
+				DuplicateObservations: 2,
+			},
+			targetObs:  []int64{1, 2, 4, 5, 6},
+			deletedObs: []int64{3, 7},
+		},
+		{
+			name: "into a species without observations",
+			id:   "2",
+			body: `{"targetId":3}`,
+			want: 200,
+			res: MergeSpeciesResponse{
+				Species:           db.Species{ID: 3, ScientificName: "Vombatus ursinus", CommonName: "Common Wombat", Native: true, Taxa: db.TaxaMammal},
+				MovedObservations: 5,
+			},
+			targetObs: []int64{3, 4, 5, 6, 7},
+		},
+		{name: "into itself", id: "2", body: `{"targetId":2}`, want: 400},
+		{name: "missing target", id: "2", body: `{}`, want: 400},
+		{name: "unknown target", id: "2", body: `{"targetId":9}`, want: 404},
+		{name: "unknown species", id: "9", body: `{"targetId":1}`, want: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newSpeciesQuerier()
+			code, w := serve(NewController(q).MergeSpecies, http.MethodPost, "/species/"+tt.id+"/merge", tt.id, tt.body)
+			if code != tt.want {
+				t.Fatalf("status = %d, want %d", code, tt.want)
+			}
+			if tt.want != 200 {
+				if len(q.species) != 3 || len(q.observations) != 7 {
+					t.Errorf("%d species and %d observations left, want all", len(q.species), len(q.observations))
+				}
+				return
+			}
+			var res MergeSpeciesResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if res != tt.res {
+				t.Errorf("response = %+v, want %+v", res, tt.res)
+			}
+			if _, ok := q.species[2]; ok {
+				t.Error("species 2 was kept")
+			}
+			if got := q.speciesOf(tt.res.Species.ID); !slices.Equal(got, tt.targetObs) {
+				t.Errorf("target observations = %v, want %v", got, tt.targetObs)
+			}
+			for _, id := range tt.deletedObs {
+				if slices.ContainsFunc(q.observations, func(o db.Observation) bool { return o.ID == id }) {
+					t.Errorf("duplicate observation %d was kept", id)
+				}
+			}
+		})
+	}
+}
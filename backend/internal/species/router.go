@@ -2,10 +2,18 @@ package species
 
 import "github.com/gin-gonic/gin"
 
-func Register(r gin.IRouter, ctl *Controller) {
+// Register adds the read routes to r and the editing routes to editor.
+func Register(r, editor gin.IRouter, ctl *Controller) {
 	g := r.Group("/species")
 	g.GET("", ctl.ListSpecies)
 	g.GET("/by-common-name/:name", ctl.GetSpeciesByCommonName)
 	g.GET("/:id", ctl.GetSpeciesByID)
 	g.GET("/observed", ctl.GetObservedSpecies)
+	g.GET("/search", ctl.SearchSpecies)
+
+	e := editor.Group("/species")
+	e.POST("", ctl.CreateSpecies)
+	e.PUT("/:id", ctl.UpdateSpecies)
+	e.DELETE("/:id", ctl.DeleteSpecies)
+	e.POST("/:id/merge", ctl.MergeSpecies)
 }
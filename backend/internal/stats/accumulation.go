@@ -0,0 +1,311 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPermutations = 100
+	// rarefactionPoints caps the points of a rarefied curve, spread evenly
+	// over its samples
+	rarefactionPoints = 100
+)
+
+type AccumulationRequest struct {
+	ObservationStatsInput
+	// Unit is the sampling unit species accumulate over, days by default
+	Unit string `form:"unit" binding:"omitempty,oneof=days observations"`
+	// By gives a curve per site or block instead of a single one
+	By          string `form:"by" binding:"omitempty,oneof=overall site block"`
+	Rarefaction bool   `form:"rarefaction"`
+	// Permutations is the number of random sample orders rarefied curves
+	// average over
+	Permutations int     `form:"permutations" binding:"omitempty,min=10,max=1000"`
+	Seed         *uint64 `form:"seed"`
+}
+
+type AccumulationResponse struct {
+	Unit string `json:"unit"`
+	// Seed reproduces the rarefied curves when sent back
+	Seed   *uint64             `json:"seed,omitempty"`
+	Curves []AccumulationCurve `json:"curves"`
+}
+
+// AccumulationCurve is the species accumulation of a site, a block or of
+// every observation.
+type AccumulationCurve struct {
+	SiteCode *string `json:"siteCode,omitempty"`
+	Block    *int32  `json:"block,omitempty"`
+	Samples  int     `json:"samples"`
+	Species  int     `json:"species"`
+	// Observed counts the species found in time order. It only has the
+	// points where new species were found, and the first and last ones.
+	Observed []AccumulationPoint `json:"observed"`
+	Rarefied []RarefactionPoint  `json:"rarefied,omitempty"`
+}
+
+type AccumulationPoint struct {
+	Samples int `json:"samples"`
+	Species int `json:"species"`
+}
+
+// RarefactionPoint is the mean number of species found in a number of
+// samples drawn at random, with the 95% interval of the permutations.
+type RarefactionPoint struct {
+	Samples int     `json:"samples"`
+	Mean    float64 `json:"mean"`
+	Lower   float64 `json:"lower"`
+	Upper   float64 `json:"upper"`
+}
+
+// accumulationGroup holds the samples of a curve, each listing the distinct
+// species found in it by their index in the group.
+type accumulationGroup struct {
+	curve   AccumulationCurve
+	species map[int64]int
+	samples [][]int
+	lastDay string
+}
+
+// ObservationAccumulation godoc
+//
+//	@Summary		Species accumulation curves
+//	@Description	Cumulative distinct species against sampling days, the calendar days with observations at a site, block or overall, or against observations. Rarefaction averages the curve over random orders of the samples, with a 95% interval.
+//	@Tags			statistics
+//	@Accept			json
+//	@Produce		json
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)					maximum(10080)
+//	@Param			unit			query		string	False	"Sampling unit"																																	Enums(days, observations)	default(days)
+//	@Param			by				query		string	False	"Curve per site or block"																														Enums(overall, site, block)	default(overall)
+//	@Param			rarefaction		query		boolean	False	"Add randomised rarefaction curves"
+//	@Param			permutations	query		integer	False	"Random orders to average over"	minimum(10)	maximum(1000)	default(100)
+//	@Param			seed			query		integer	False	"Seed of the random orders"
+//	@Success		200				{object}	AccumulationResponse
+//	@Failure		400				{object}	utils.HttpError
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
+//	@Router			/stats/observations/accumulation [get]
+func (u *Controller) ObservationAccumulation(c *gin.Context) {
+	var req AccumulationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+	if req.Unit == "" {
+		req.Unit = "days"
+	}
+	if req.Permutations == 0 {
+		req.Permutations = defaultPermutations
+	}
+	ctx := c.Request.Context()
+
+	from, to, taxa, commonName := ParseObservationStatsInput(req.ObservationStatsInput)
+
+	rows, err := u.q.ObservationSpeciesSequence(ctx, db.ObservationSpeciesSequenceParams{
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("Failed to fetch observed species: %w", err))
+		return
+	}
+
+	groups := make(map[string]*accumulationGroup)
+	var keys []string
+	for _, row := range rows {
+		key := "overall"
+		switch req.By {
+		case "site":
+			key = row.SiteCode
+		case "block":
+			key = fmt.Sprint(row.Block)
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &accumulationGroup{species: make(map[int64]int)}
+			switch req.By {
+			case "site":
+				g.curve.SiteCode, g.curve.Block = &row.SiteCode, &row.Block
+			case "block":
+				g.curve.Block = &row.Block
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.add(row, req.Unit == "observations")
+	}
+
+	resp := AccumulationResponse{
+		Unit:   req.Unit,
+		Curves: make([]AccumulationCurve, 0, len(groups)),
+	}
+	var rng *rand.Rand
+	if req.Rarefaction {
+		seed := rand.Uint64()
+		if req.Seed != nil {
+			seed = *req.Seed
+		}
+		resp.Seed = &seed
+		rng = rand.New(rand.NewPCG(seed, seed))
+	}
+	// Sorted so that a seed gives the same curves
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(
+			cmp.Compare(groupBlock(groups[a]), groupBlock(groups[b])),
+			cmp.Compare(a, b),
+		)
+	})
+	for _, key := range keys {
+		g := groups[key]
+		g.curve.Samples = len(g.samples)
+		g.curve.Species = len(g.species)
+		g.curve.Observed = observedAccumulation(g.samples)
+		if rng != nil {
+			g.curve.Rarefied = rarefy(g.samples, len(g.species), req.Permutations, rng)
+		}
+		resp.Curves = append(resp.Curves, g.curve)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func groupBlock(g *accumulationGroup) int32 {
+	if g.curve.Block == nil {
+		return 0
+	}
+	return *g.curve.Block
+}
+
+// add records the species of an observation, in a sample of its own or in
+// the sample of its day.
+func (g *accumulationGroup) add(row db.ObservationSpeciesSequenceRow, perObservation bool) {
+	sp, ok := g.species[row.SpeciesID]
+	if !ok {
+		sp = len(g.species)
+		g.species[row.SpeciesID] = sp
+	}
+	day := row.Timestamp.Format("2006-01-02")
+	if perObservation || len(g.samples) == 0 || day != g.lastDay {
+		g.samples = append(g.samples, nil)
+		g.lastDay = day
+	}
+	last := &g.samples[len(g.samples)-1]
+	if !slices.Contains(*last, sp) {
+		*last = append(*last, sp)
+	}
+}
+
+// observedAccumulation counts the species found over the samples in order.
+func observedAccumulation(samples [][]int) []AccumulationPoint {
+	seen := make(map[int]bool)
+	points := make([]AccumulationPoint, 0)
+	for i, sample := range samples {
+		found := false
+		for _, sp := range sample {
+			if !seen[sp] {
+				seen[sp] = true
+				found = true
+			}
+		}
+		if found || i == 0 || i == len(samples)-1 {
+			points = append(points, AccumulationPoint{Samples: i + 1, Species: len(seen)})
+		}
+	}
+	return points
+}
+
+// rarefy averages the species accumulation over random orders of the
+// samples, at up to rarefactionPoints sample counts.
+func rarefy(samples [][]int, speciesCount, permutations int, rng *rand.Rand) []RarefactionPoint {
+	n := len(samples)
+	if n == 0 {
+		return nil
+	}
+	var steps []int
+	for i := 1; i <= rarefactionPoints; i++ {
+		step := int(math.Ceil(float64(i) * float64(n) / rarefactionPoints))
+		if len(steps) == 0 || step > steps[len(steps)-1] {
+			steps = append(steps, step)
+		}
+	}
+
+	counts := make([][]float64, len(steps))
+	for i := range counts {
+		counts[i] = make([]float64, permutations)
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	// seen holds the permutation a species was last found in, saving a reset
+	// per permutation
+	seen := make([]int, speciesCount)
+	for p := 1; p <= permutations; p++ {
+		rng.Shuffle(n, func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+		found, step := 0, 0
+		for i, sample := range order {
+			for _, sp := range samples[sample] {
+				if seen[sp] != p {
+					seen[sp] = p
+					found++
+				}
+			}
+			if i+1 == steps[step] {
+				counts[step][p-1] = float64(found)
+				step++
+			}
+		}
+	}
+
+	points := make([]RarefactionPoint, len(steps))
+	for i, step := range steps {
+		slices.Sort(counts[i])
+		var sum float64
+		for _, v := range counts[i] {
+			sum += v
+		}
+		points[i] = RarefactionPoint{
+			Samples: step,
+			Mean:    sum / float64(permutations),
+			Lower:   quantile(counts[i], 0.025),
+			Upper:   quantile(counts[i], 0.975),
+		}
+	}
+	return points
+}
+
+// quantile interpolates the q quantile of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
@@ -0,0 +1,164 @@
+package stats
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+func TestAccumulationGroupAdd(t *testing.T) {
+	day := func(d, h int) time.Time {
+		return time.Date(2024, 10, d, h, 0, 0, 0, time.UTC)
+	}
+	rows := []db.ObservationSpeciesSequenceRow{
+		{SpeciesID: 10, Timestamp: day(1, 6)},
+		{SpeciesID: 20, Timestamp: day(1, 7)},
+		{SpeciesID: 10, Timestamp: day(1, 8)},
+		{SpeciesID: 10, Timestamp: day(3, 6)},
+		{SpeciesID: 30, Timestamp: day(4, 6)},
+	}
+	tests := []struct {
+		name           string
+		perObservation bool
+		want           [][]int
+	}{
+		{"days", false, [][]int{{0, 1}, {0}, {2}}},
+		{"observations", true, [][]int{{0}, {1}, {0}, {0}, {2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &accumulationGroup{species: make(map[int64]int)}
+			for _, row := range rows {
+				g.add(row, tt.perObservation)
+			}
+			if !slices.EqualFunc(g.samples, tt.want, slices.Equal) {
+				t.Errorf("samples = %v, want %v", g.samples, tt.want)
+			}
+		})
+	}
+}
+
+func TestObservedAccumulation(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples [][]int
+		want    []AccumulationPoint
+	}{
+		{"none", nil, []AccumulationPoint{}},
+		{"one", [][]int{{0, 1}}, []AccumulationPoint{{1, 2}}},
+		{
+			"keeps the points with new species, the first and the last",
+			[][]int{{0}, {0}, {1, 2}, {1}, {0}, {3}, {2}},
+			[]AccumulationPoint{{1, 1}, {3, 3}, {6, 4}, {7, 4}},
+		},
+		{"nothing new after the first", [][]int{{0}, {0}, {0}}, []AccumulationPoint{{1, 1}, {3, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := observedAccumulation(tt.samples); !slices.Equal(got, tt.want) {
+				t.Errorf("observedAccumulation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// expectedSpecies is the exact mean of the species found in k of the
+// samples drawn without replacement: the sum over the species of the
+// chance that one of its samples is drawn.
+func expectedSpecies(samples [][]int, speciesCount, k int) float64 {
+	n := len(samples)
+	with := make([]int, speciesCount)
+	for _, sample := range samples {
+		for _, sp := range sample {
+			with[sp]++
+		}
+	}
+	var sum float64
+	for _, m := range with {
+		// C(n-m, k) / C(n, k)
+		missed := 1.0
+		for i := range k {
+			missed *= float64(n-m-i) / float64(n-i)
+		}
+		sum += 1 - max(missed, 0)
+	}
+	return sum
+}
+
+func TestRarefy(t *testing.T) {
+	tests := []struct {
+		name         string
+		samples      [][]int
+		speciesCount int
+	}{
+		{"one sample", [][]int{{0, 1, 2}}, 3},
+		{"common and rare species", [][]int{{0}, {0, 1}, {0}, {0, 2}, {0}, {0}, {3}, {0, 1}}, 4},
+		{"every sample new", [][]int{{0}, {1}, {2}, {3}, {4}}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const permutations = 4000
+			points := rarefy(tt.samples, tt.speciesCount, permutations, rand.New(rand.NewPCG(1, 2)))
+			if len(points) != len(tt.samples) {
+				t.Fatalf("got %d points, want one per sample count", len(points))
+			}
+			for i, p := range points {
+				if p.Samples != i+1 {
+					t.Errorf("point %d is at %d samples", i, p.Samples)
+				}
+				want := expectedSpecies(tt.samples, tt.speciesCount, p.Samples)
+				if math.Abs(p.Mean-want) > 0.05 {
+					t.Errorf("mean at %d samples = %v, want %v", p.Samples, p.Mean, want)
+				}
+				if p.Lower > p.Mean || p.Mean > p.Upper {
+					t.Errorf("interval at %d samples [%v, %v] does not hold the mean %v", p.Samples, p.Lower, p.Upper, p.Mean)
+				}
+			}
+			last := points[len(points)-1]
+			if last.Mean != float64(tt.speciesCount) || last.Lower != last.Mean || last.Upper != last.Mean {
+				t.Errorf("every sample gives %+v, want all %d species", last, tt.speciesCount)
+			}
+		})
+	}
+}
+
+func TestRarefyPoints(t *testing.T) {
+	samples := make([][]int, 250)
+	for i := range samples {
+		samples[i] = []int{i % 7}
+	}
+	points := rarefy(samples, 7, 10, rand.New(rand.NewPCG(1, 2)))
+	if len(points) != rarefactionPoints {
+		t.Fatalf("got %d points, want %d", len(points), rarefactionPoints)
+	}
+	if points[0].Samples != 3 || points[len(points)-1].Samples != 250 {
+		t.Errorf("points go from %d to %d samples, want 3 to 250", points[0].Samples, points[len(points)-1].Samples)
+	}
+	if rarefy(nil, 0, 10, rand.New(rand.NewPCG(1, 2))) != nil {
+		t.Error("rarefy of no samples is not nil")
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		sorted []float64
+		q      float64
+		want   float64
+	}{
+		{[]float64{5}, 0.5, 5},
+		{[]float64{1, 2, 3, 4, 5}, 0, 1},
+		{[]float64{1, 2, 3, 4, 5}, 0.5, 3},
+		{[]float64{1, 2, 3, 4, 5}, 1, 5},
+		{[]float64{1, 2, 3, 4}, 0.5, 2.5},
+		{[]float64{0, 10}, 0.025, 0.25},
+	}
+	for _, tt := range tests {
+		if got := quantile(tt.sorted, tt.q); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("quantile(%v, %v) = %v, want %v", tt.sorted, tt.q, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// densityStep is the time between the points of density curves, in minutes.
+const densityStep = 15
+
+type ActivityRequest struct {
+	ObservationStatsInput
+	// By gives a histogram per species, the default, or per taxa
+	By string `form:"by" binding:"omitempty,oneof=species taxa"`
+	// Months adds month-of-year histograms
+	Months bool `form:"months"`
+	// Density adds kernel density curves of the time of day
+	Density bool `form:"density"`
+	// SpeciesA and SpeciesB are compared for activity overlap
+	SpeciesA *int64 `form:"speciesA" binding:"required_with=SpeciesB"`
+	SpeciesB *int64 `form:"speciesB" binding:"required_with=SpeciesA"`
+}
+
+type ActivityResponse struct {
+	Groups  []ActivityGroup  `json:"groups"`
+	Overlap *ActivityOverlap `json:"overlap,omitempty"`
+}
+
+// ActivityGroup is the activity pattern of a species or of a taxa.
+type ActivityGroup struct {
+	SpeciesID        *int64  `json:"speciesId,omitempty"`
+	ScientificName   *string `json:"scientificName,omitempty"`
+	CommonName       *string `json:"commonName,omitempty"`
+	Taxa             db.Taxa `json:"taxa"`
+	ObservationCount int64   `json:"observationCount"`
+	// Hours counts the observations in each hour of the day, local time
+	Hours []int64 `json:"hours"`
+	// Months counts the observations in each month, January first
+	Months  []int64        `json:"months,omitempty"`
+	Density []DensityPoint `json:"density,omitempty"`
+}
+
+// DensityPoint is the estimated density of the activity at a time of day,
+// per hour so that the curve integrates to 1 over the day.
+type DensityPoint struct {
+	Hour    float64 `json:"hour"`
+	Density float64 `json:"density"`
+}
+
+// ActivityOverlap is the coefficient of overlapping Δ of the activity of
+// two species, from 0 for no overlap to 1 for the same pattern.
+type ActivityOverlap struct {
+	SpeciesA  int64   `json:"speciesA"`
+	SpeciesB  int64   `json:"speciesB"`
+	Estimator string  `json:"estimator" enums:"Dhat1,Dhat4"`
+	Overlap   float64 `json:"overlap"`
+}
+
+type activityGroup struct {
+	ActivityGroup
+	activity activity
+}
+
+// ObservationActivity godoc
+//
+//	@Summary		Diel activity patterns
+//	@Description	Hour-of-day, and optionally month-of-year, histograms of the observations of each species or taxa. Density adds von Mises kernel density curves of the time of day. Giving speciesA and speciesB estimates the overlap of their activity following Ridout and Linkie (2009), with Δ̂₁ below 75 observations of either species and Δ̂₄ above. The overlap ignores the taxa and commonName filters, which only narrow the histograms.
+//	@Tags			statistics
+//	@Accept			json
+//	@Produce		json
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)				maximum(10080)
+//	@Param			by				query		string	False	"Histogram per species or taxa"																													Enums(species, taxa)	default(species)
+//	@Param			months			query		boolean	False	"Add month-of-year histograms"
+//	@Param			density			query		boolean	False	"Add kernel density curves"
+//	@Param			speciesA		query		integer	False	"ID of a species to compare the activity of, whatever its taxa and common name"
+//	@Param			speciesB		query		integer	False	"ID of the species to compare it with"
+//	@Success		200				{object}	ActivityResponse
+//	@Failure		400				{object}	utils.HttpError
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
+//	@Router			/stats/activity [get]
+func (u *Controller) ObservationActivity(c *gin.Context) {
+	var req ActivityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+	ctx := c.Request.Context()
+
+	from, to, taxa, commonName := ParseObservationStatsInput(req.ObservationStatsInput)
+
+	params := db.ObservationActivityByMinuteParams{
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
+	}
+	rows, err := u.q.ObservationActivityByMinute(ctx, params)
+	if err != nil {
+		c.Error(fmt.Errorf("Failed to fetch activity: %w", err))
+		return
+	}
+
+	species := make(map[int64]*activityGroup)
+	groups := make(map[string]*activityGroup)
+	var keys []string
+	for _, row := range rows {
+		if species[row.SpeciesID] == nil {
+			species[row.SpeciesID] = &activityGroup{ActivityGroup: ActivityGroup{Taxa: row.Taxa}}
+		}
+		species[row.SpeciesID].activity[row.MinuteOfDay] += float64(row.ObservationCount)
+
+		key := string(row.Taxa)
+		if req.By != "taxa" {
+			key = fmt.Sprint(row.SpeciesID)
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &activityGroup{ActivityGroup: ActivityGroup{
+				Taxa:  row.Taxa,
+				Hours: make([]int64, 24),
+			}}
+			if req.By != "taxa" {
+				g.SpeciesID = &row.SpeciesID
+				g.ScientificName = &row.ScientificName
+				g.CommonName = &row.CommonName
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.ObservationCount += row.ObservationCount
+		g.Hours[row.MinuteOfDay/60] += row.ObservationCount
+		g.activity[row.MinuteOfDay] += float64(row.ObservationCount)
+	}
+
+	if req.Months {
+		months, err := u.q.ObservationActivityByMonth(ctx, db.ObservationActivityByMonthParams{
+			From:         from,
+			To:           to,
+			Block:        req.Block,
+			Tenure:       req.NullTenure(),
+			Forest:       req.NullForest(),
+			SiteCode:     req.SiteCode,
+			Taxa:         taxa,
+			CommonName:   commonName,
+			Independence: req.Independence,
+		})
+		if err != nil {
+			c.Error(fmt.Errorf("Failed to fetch activity by month: %w", err))
+			return
+		}
+		for _, g := range groups {
+			g.Months = make([]int64, 12)
+		}
+		for _, row := range months {
+			key := fmt.Sprint(row.SpeciesID)
+			if req.By == "taxa" {
+				if sp, ok := species[row.SpeciesID]; ok {
+					key = string(sp.Taxa)
+				}
+			}
+			if g, ok := groups[key]; ok {
+				g.Months[row.Month-1] += row.ObservationCount
+			}
+		}
+	}
+
+	resp := ActivityResponse{Groups: make([]ActivityGroup, 0, len(groups))}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Compare(groups[b].ObservationCount, groups[a].ObservationCount)
+	})
+	for _, key := range keys {
+		g := groups[key]
+		if req.Density {
+			g.Density = densityCurve(&g.activity)
+		}
+		resp.Groups = append(resp.Groups, g.ActivityGroup)
+	}
+
+	if req.SpeciesA != nil {
+		compared := species
+		// The compared species are picked by id, so the taxa and common name
+		// filters would only leave one of them out
+		if taxa.Valid || commonName != nil {
+			params.Taxa = db.NullTaxa{}
+			params.CommonName = nil
+			params.SpeciesIds = []int64{*req.SpeciesA, *req.SpeciesB}
+			rows, err := u.q.ObservationActivityByMinute(ctx, params)
+			if err != nil {
+				c.Error(fmt.Errorf("Failed to fetch activity of the compared species: %w", err))
+				return
+			}
+			compared = make(map[int64]*activityGroup)
+			for _, row := range rows {
+				if compared[row.SpeciesID] == nil {
+					compared[row.SpeciesID] = &activityGroup{}
+				}
+				compared[row.SpeciesID].activity[row.MinuteOfDay] += float64(row.ObservationCount)
+			}
+		}
+		a, b := compared[*req.SpeciesA], compared[*req.SpeciesB]
+		if a == nil || b == nil {
+			c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", fmt.Errorf("species %d or %d has no observations matching the filters", *req.SpeciesA, *req.SpeciesB)))
+			return
+		}
+		estimator, value := overlap(&a.activity, &b.activity)
+		resp.Overlap = &ActivityOverlap{
+			SpeciesA:  *req.SpeciesA,
+			SpeciesB:  *req.SpeciesB,
+			Estimator: estimator,
+			Overlap:   value,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// densityCurve samples the kernel density of the activity every
+// densityStep minutes.
+func densityCurve(a *activity) []DensityPoint {
+	f := a.density(a.bandwidth())
+	points := make([]DensityPoint, 0, minutesPerDay/densityStep)
+	for m := 0; m < minutesPerDay; m += densityStep {
+		points = append(points, DensityPoint{
+			Hour: float64(m) / 60,
+			// Per radian to per hour
+			Density: f[m] * 2 * math.Pi / 24,
+		})
+	}
+	return points
+}
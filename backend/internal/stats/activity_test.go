@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// activityQuerier serves the activity of a fox at dusk and a bandicoot at
+// night, applying the common name and species filters.
+type activityQuerier struct {
+	db.Querier
+}
+
+func (activityQuerier) ObservationActivityByMinute(ctx context.Context, arg db.ObservationActivityByMinuteParams) ([]db.ObservationActivityByMinuteRow, error) {
+	all := []db.ObservationActivityByMinuteRow{
+		{SpeciesID: 1, CommonName: "Red Fox", Taxa: db.TaxaMammal, MinuteOfDay: 1140, ObservationCount: 4},
+		{SpeciesID: 1, CommonName: "Red Fox", Taxa: db.TaxaMammal, MinuteOfDay: 1200, ObservationCount: 6},
+		{SpeciesID: 2, CommonName: "Southern Brown Bandicoot", Taxa: db.TaxaMammal, MinuteOfDay: 1380, ObservationCount: 5},
+		{SpeciesID: 2, CommonName: "Southern Brown Bandicoot", Taxa: db.TaxaMammal, MinuteOfDay: 60, ObservationCount: 5},
+	}
+	var rows []db.ObservationActivityByMinuteRow
+	for _, row := range all {
+		if arg.CommonName != nil && !strings.EqualFold(row.CommonName, *arg.CommonName) {
+			continue
+		}
+		if arg.SpeciesIds != nil && !slices.Contains(arg.SpeciesIds, row.SpeciesID) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func TestObservationActivityOverlap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		query   string
+		groups  int
+		overlap bool
+		wantErr bool
+	}{
+		{"no overlap asked", "", 2, false, false},
+		{"overlap", "speciesA=1&speciesB=2", 2, true, false},
+		{"overlap of species filtered out", "commonName=Red+Fox&speciesA=1&speciesB=2", 1, true, false},
+		{"overlap with a species without observations", "speciesA=1&speciesB=3", 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/stats/activity?"+tt.query, nil)
+
+			NewController(activityQuerier{}).ObservationActivity(c)
+
+			if (len(c.Errors) > 0) != tt.wantErr {
+				t.Fatalf("errors = %v, want one: %v", c.Errors, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var resp ActivityResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Groups) != tt.groups {
+				t.Errorf("got %d groups, want %d", len(resp.Groups), tt.groups)
+			}
+			if (resp.Overlap != nil) != tt.overlap {
+				t.Fatalf("overlap = %v, want one: %v", resp.Overlap, tt.overlap)
+			}
+			if resp.Overlap != nil && (resp.Overlap.Overlap <= 0 || resp.Overlap.Overlap >= 1) {
+				t.Errorf("overlap = %v, want between 0 and 1", resp.Overlap.Overlap)
+			}
+		})
+	}
+}
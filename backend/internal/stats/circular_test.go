@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// activityAt builds an activity from observation counts by minute of day.
+func activityAt(counts map[int]float64) *activity {
+	var a activity
+	for m, n := range counts {
+		a[m] = n
+	}
+	return &a
+}
+
+// spread spreads n observations evenly over the minutes from..to.
+func spread(from, to int, n float64) map[int]float64 {
+	counts := make(map[int]float64)
+	for m := from; m <= to; m++ {
+		counts[m%minutesPerDay] += n / float64(to-from+1)
+	}
+	return counts
+}
+
+func TestLogBesselI(t *testing.T) {
+	tests := []struct {
+		nu   int
+		x    float64
+		want float64
+	}{
+		{0, 0, 0},
+		{2, 0, math.Inf(-1)},
+		{0, 1, 0.23591435850717865},
+		{1, 1, -0.57064798749083128},
+		{2, 2, -0.37258883268542917},
+		{0, 10, 7.9429720831186956},
+		// Far beyond what math.Exp could hold
+		{0, 500, 495.97400766810670},
+		{2, 1000, 995.62530788945305},
+	}
+	for _, tt := range tests {
+		got := logBesselI(tt.nu, tt.x)
+		if math.IsInf(tt.want, -1) {
+			if !math.IsInf(got, -1) {
+				t.Errorf("logBesselI(%d, %v) = %v, want -Inf", tt.nu, tt.x, got)
+			}
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9*math.Max(1, math.Abs(tt.want)) {
+			t.Errorf("logBesselI(%d, %v) = %v, want %v", tt.nu, tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestConcentration(t *testing.T) {
+	tests := []struct {
+		name     string
+		counts   map[int]float64
+		min, max float64
+	}{
+		{"uniform", spread(0, minutesPerDay-1, 1440), 0, 1e-6},
+		{"opposite peaks", map[int]float64{0: 10, 720: 10}, 0, 1e-6},
+		{"single minute", map[int]float64{300: 5}, maxConcentration, maxConcentration},
+		// A uniform spread of width w has variance w²/12 ≈ 1/κ
+		{"two hours", spread(600, 720, 100), 35, 55},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := activityAt(tt.counts).concentration()
+			if got < tt.min || got > tt.max {
+				t.Errorf("concentration() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestDensityIntegratesToOne(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[int]float64
+	}{
+		{"single minute", map[int]float64{0: 1}},
+		{"dawn and dusk", map[int]float64{360: 20, 1080: 15}},
+		{"night", spread(1200, 1800, 300)},
+		{"uniform", spread(0, minutesPerDay-1, 1440)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := activityAt(tt.counts)
+			for _, bw := range []float64{0, 1, a.bandwidth(), 400} {
+				var integral float64
+				for _, f := range a.density(bw) {
+					integral += f * 2 * math.Pi / minutesPerDay
+				}
+				if math.Abs(integral-1) > 1e-6 {
+					t.Errorf("density(%v) integrates to %v, want 1", bw, integral)
+				}
+			}
+		})
+	}
+}
+
+func TestDensityCurve(t *testing.T) {
+	points := densityCurve(activityAt(map[int]float64{360: 20, 1080: 15}))
+	if len(points) != minutesPerDay/densityStep {
+		t.Fatalf("got %d points, want %d", len(points), minutesPerDay/densityStep)
+	}
+	var integral float64
+	for _, p := range points {
+		integral += p.Density * densityStep / 60
+	}
+	if math.Abs(integral-1) > 1e-3 {
+		t.Errorf("density curve integrates to %v over the day, want 1", integral)
+	}
+	if points[6*60/densityStep].Density < points[0].Density {
+		t.Errorf("density at 6:00 %v is below the density at midnight %v", points[6*60/densityStep].Density, points[0].Density)
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      map[int]float64
+		estimator string
+		min, max  float64
+	}{
+		{"same small samples", spread(300, 420, 30), spread(300, 420, 30), "Dhat1", 0.999, 1.001},
+		{"same large samples", spread(300, 420, 200), spread(300, 420, 200), "Dhat4", 0.999, 1.001},
+		// The bandwidths, hence the curves, differ with the sample sizes
+		{"one small sample", spread(300, 420, 200), spread(300, 420, 50), "Dhat1", 0.9, 1.001},
+		{"diurnal and nocturnal", spread(540, 900, 100), spread(1260, 1620, 100), "Dhat4", 0, 0.1},
+		{"shifted by two hours", spread(360, 600, 100), spread(480, 720, 100), "Dhat4", 0.4, 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := activityAt(tt.a), activityAt(tt.b)
+			estimator, got := overlap(a, b)
+			if estimator != tt.estimator {
+				t.Errorf("estimator = %s, want %s", estimator, tt.estimator)
+			}
+			if got < tt.min || got > tt.max {
+				t.Errorf("overlap = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+			if _, reverse := overlap(b, a); math.Abs(reverse-got) > 1e-9 {
+				t.Errorf("overlap is not symmetric: %v and %v", got, reverse)
+			}
+		})
+	}
+}
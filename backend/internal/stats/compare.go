@@ -0,0 +1,220 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ObservationComparisonRequest struct {
+	ObservationStatsInput
+}
+
+// GroupComparison sums up the observations of a group of sites. The group's
+// observation and species counts grow with its number of sites, so the
+// means per active site are the fairer comparison between groups.
+type GroupComparison struct {
+	// SiteCount counts the sites of the group matching the filters
+	SiteCount int64 `json:"siteCount"`
+	// ActiveSiteCount counts those with observations in the period
+	ActiveSiteCount int64 `json:"activeSiteCount"`
+	ObservationStats
+	// ObservationsPerSite and SpeciesPerSite are the means over the active
+	// sites, omitted without any.
+	ObservationsPerSite *float64 `json:"observationsPerSite"`
+	SpeciesPerSite      *float64 `json:"speciesPerSite"`
+}
+
+type TenureComparison struct {
+	Tenure db.TenureType `json:"tenure"`
+	GroupComparison
+}
+
+type ForestComparison struct {
+	Forest db.ForestType `json:"forest"`
+	GroupComparison
+}
+
+type ObservationByTenuresResponse struct {
+	Tenures []TenureComparison `json:"tenures"`
+	// SharedSpeciesCount counts the species observed on both public and
+	// private land
+	SharedSpeciesCount int64 `json:"sharedSpeciesCount"`
+}
+
+type ObservationByForestsResponse struct {
+	Forests []ForestComparison `json:"forests"`
+	// SharedSpeciesCount counts the species observed in both dry and wet
+	// forest
+	SharedSpeciesCount int64 `json:"sharedSpeciesCount"`
+}
+
+// ObservationByTenures godoc
+//
+//	@Summary		Observation stats compared by tenure
+//	@Description	Compare the detections and species richness of the sites on public and private land, in total and per active site
+//	@Tags			statistics
+//	@Accept			json
+//	@Produce		json
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)	maximum(10080)
+//	@Success		200				{object}	ObservationByTenuresResponse
+//	@Failure		400				{object}	utils.HttpError
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
+//	@Router			/stats/observations/tenures [get]
+func (u *Controller) ObservationByTenures(c *gin.Context) {
+	var req ObservationComparisonRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	rows, err := u.observationSpeciesBySite(c.Request.Context(), req.ObservationStatsInput)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tenures := db.AllTenureTypeValues()
+	groups, shared := compareGroups(rows, tenures, func(row db.ObservationSpeciesBySiteRow) db.TenureType {
+		return row.Tenure
+	})
+	resp := ObservationByTenuresResponse{SharedSpeciesCount: shared}
+	for i, tenure := range tenures {
+		resp.Tenures = append(resp.Tenures, TenureComparison{Tenure: tenure, GroupComparison: groups[i]})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ObservationByForests godoc
+//
+//	@Summary		Observation stats compared by forest type
+//	@Description	Compare the detections and species richness of the sites in dry and wet forest, in total and per active site
+//	@Tags			statistics
+//	@Accept			json
+//	@Produce		json
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)	maximum(10080)
+//	@Success		200				{object}	ObservationByForestsResponse
+//	@Failure		400				{object}	utils.HttpError
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
+//	@Router			/stats/observations/forests [get]
+func (u *Controller) ObservationByForests(c *gin.Context) {
+	var req ObservationComparisonRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	rows, err := u.observationSpeciesBySite(c.Request.Context(), req.ObservationStatsInput)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	forests := db.AllForestTypeValues()
+	groups, shared := compareGroups(rows, forests, func(row db.ObservationSpeciesBySiteRow) db.ForestType {
+		return row.Forest
+	})
+	resp := ObservationByForestsResponse{SharedSpeciesCount: shared}
+	for i, forest := range forests {
+		resp.Forests = append(resp.Forests, ForestComparison{Forest: forest, GroupComparison: groups[i]})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (u *Controller) observationSpeciesBySite(ctx context.Context, input ObservationStatsInput) ([]db.ObservationSpeciesBySiteRow, error) {
+	from, to, taxa, commonName := ParseObservationStatsInput(input)
+	rows, err := u.q.ObservationSpeciesBySite(ctx, db.ObservationSpeciesBySiteParams{
+		From:         from,
+		To:           to,
+		Block:        input.Block,
+		Tenure:       input.NullTenure(),
+		Forest:       input.NullForest(),
+		SiteCode:     input.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: input.Independence,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch observed species by sites: %w", err)
+	}
+	return rows, nil
+}
+
+// compareGroups sums up the sites of each key, in the order of keys, and
+// counts the species observed in every group.
+func compareGroups[K comparable](rows []db.ObservationSpeciesBySiteRow, keys []K, key func(db.ObservationSpeciesBySiteRow) K) ([]GroupComparison, int64) {
+	index := make(map[K]int, len(keys))
+	for i, k := range keys {
+		index[k] = i
+	}
+	groups := make([]GroupComparison, len(keys))
+	species := make([]map[int64]bool, len(keys))
+	speciesSums := make([]int64, len(keys))
+	for i := range species {
+		species[i] = make(map[int64]bool)
+	}
+
+	for _, row := range rows {
+		i, ok := index[key(row)]
+		if !ok {
+			continue
+		}
+		groups[i].SiteCount++
+		if row.ObservationCount == 0 {
+			continue
+		}
+		groups[i].ActiveSiteCount++
+		groups[i].ObservationCount += row.ObservationCount
+		speciesSums[i] += int64(len(row.SpeciesIds))
+		for _, id := range row.SpeciesIds {
+			species[i][id] = true
+		}
+	}
+
+	for i := range groups {
+		g := &groups[i]
+		g.SpeciesCount = int64(len(species[i]))
+		if g.ActiveSiteCount > 0 {
+			observations := float64(g.ObservationCount) / float64(g.ActiveSiteCount)
+			richness := float64(speciesSums[i]) / float64(g.ActiveSiteCount)
+			g.ObservationsPerSite = &observations
+			g.SpeciesPerSite = &richness
+		}
+	}
+
+	var shared int64
+	if len(species) > 0 {
+	next:
+		for id := range species[0] {
+			for _, other := range species[1:] {
+				if !other[id] {
+					continue next
+				}
+			}
+			shared++
+		}
+	}
+	return groups, shared
+}
@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+func TestCompareGroups(t *testing.T) {
+	rows := []db.ObservationSpeciesBySiteRow{
+		{SiteCode: "P1", Tenure: db.TenureTypePublic, ObservationCount: 10, SpeciesIds: []int64{1, 2, 3}},
+		{SiteCode: "P2", Tenure: db.TenureTypePublic, ObservationCount: 4, SpeciesIds: []int64{1}},
+		// Counted as a site of the group, not as an active one
+		{SiteCode: "P3", Tenure: db.TenureTypePublic},
+		{SiteCode: "Q1", Tenure: db.TenureTypePrivate, ObservationCount: 3, SpeciesIds: []int64{3, 4}},
+		// Not one of the groups compared
+		{SiteCode: "X1", Tenure: "crown", ObservationCount: 7, SpeciesIds: []int64{1, 4}},
+	}
+	perSite := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name   string
+		keys   []db.TenureType
+		want   []GroupComparison
+		shared int64
+	}{
+		{
+			name: "public and private",
+			keys: []db.TenureType{db.TenureTypePublic, db.TenureTypePrivate},
+			want: []GroupComparison{
+				{SiteCount: 3, ActiveSiteCount: 2, ObservationStats: ObservationStats{ObservationCount: 14, SpeciesCount: 3},
+					ObservationsPerSite: perSite(7), SpeciesPerSite: perSite(2)},
+				{SiteCount: 1, ActiveSiteCount: 1, ObservationStats: ObservationStats{ObservationCount: 3, SpeciesCount: 2},
+					ObservationsPerSite: perSite(3), SpeciesPerSite: perSite(2)},
+			},
+			// Species 3
+			shared: 1,
+		},
+		{
+			name: "a group without sites",
+			keys: []db.TenureType{db.TenureTypePrivate, "leased"},
+			want: []GroupComparison{
+				{SiteCount: 1, ActiveSiteCount: 1, ObservationStats: ObservationStats{ObservationCount: 3, SpeciesCount: 2},
+					ObservationsPerSite: perSite(3), SpeciesPerSite: perSite(2)},
+				{},
+			},
+			shared: 0,
+		},
+		{
+			name: "one group",
+			keys: []db.TenureType{db.TenureTypePublic},
+			want: []GroupComparison{
+				{SiteCount: 3, ActiveSiteCount: 2, ObservationStats: ObservationStats{ObservationCount: 14, SpeciesCount: 3},
+					ObservationsPerSite: perSite(7), SpeciesPerSite: perSite(2)},
+			},
+			shared: 3,
+		},
+		{
+			name:   "no groups",
+			keys:   nil,
+			want:   []GroupComparison{},
+			shared: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, shared := compareGroups(rows, tt.keys, func(row db.ObservationSpeciesBySiteRow) db.TenureType {
+				return row.Tenure
+			})
+			if shared != tt.shared {
+				t.Errorf("shared species = %d, want %d", shared, tt.shared)
+			}
+			if len(groups) != len(tt.want) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.want))
+			}
+			for i, g := range groups {
+				want := tt.want[i]
+				if g.SiteCount != want.SiteCount || g.ActiveSiteCount != want.ActiveSiteCount || g.ObservationStats != want.ObservationStats {
+					t.Errorf("group %s = %+v, want %+v", tt.keys[i], g, want)
+				}
+				checkIndex(t, string(tt.keys[i])+" observations per site", g.ObservationsPerSite, want.ObservationsPerSite)
+				checkIndex(t, string(tt.keys[i])+" species per site", g.SpeciesPerSite, want.SpeciesPerSite)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 type DashboardStatsRequest struct {
 	models.TimePeriodRequest
+	Independence *int32 `form:"independence" binding:"omitempty,min=1,max=10080"`
 }
 
 type DashboardStatsResponse struct {
@@ -27,10 +28,13 @@ type DashboardStatsResponse struct {
 //	@Tags			statistics
 //	@Accept			json
 //	@Produce		json
-//	@Param			from	query		string	False	"Search start from"	format(date-time)
-//	@Param			to		query		string	False	"Search end to"		format(date-time)
-//	@Success		200		{object}	DashboardStatsResponse
+//	@Param			from			query		string	False	"Search start from"																																format(date-time)
+//	@Param			to				query		string	False	"Search end to"																																	format(date-time)
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)	maximum(10080)
+//	@Success		200				{object}	DashboardStatsResponse
 //	@Error			400 																																											{object}	gin.H
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
 //	@Router			/stats/dashboard [get]
 func (u *Controller) DashboardStats(c *gin.Context) {
 	var req DashboardStatsRequest
@@ -45,8 +49,9 @@ func (u *Controller) DashboardStats(c *gin.Context) {
 	// Use from/to for filtering
 
 	paramsNative := db.CountSpeciesByNativeParams{
-		From: req.From.ToPGTime(),
-		To:   req.To.ToPGTime(),
+		From:         req.From.ToPGTime(),
+		To:           req.To.ToPGTime(),
+		Independence: req.Independence,
 	}
 
 	speciesGroups, err := u.q.CountSpeciesByNative(ctx, paramsNative)
@@ -0,0 +1,198 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type DiversityRequest struct {
+	ObservationStatsInput
+}
+
+// DiversityIndices describe the species diversity of a set of observations,
+// taking the observations of a species as its abundance.
+type DiversityIndices struct {
+	ObservationCount int64 `json:"observationCount"`
+	// Richness is the number of species observed
+	Richness int `json:"richness"`
+	// Shannon is the Shannon index H′ = -Σ pᵢ ln pᵢ
+	Shannon float64 `json:"shannon"`
+	// Simpson is Simpson's index of diversity 1 - D, the probability that
+	// two observations drawn without replacement are of different species.
+	// It needs two observations.
+	Simpson *float64 `json:"simpson"`
+	// Pielou is Pielou's evenness J′ = H′ / ln S. It needs two species.
+	Pielou *float64 `json:"pielou"`
+	// Chao1 estimates the total richness from the species observed once and
+	// twice, bias-corrected when no species was observed twice.
+	Chao1 float64 `json:"chao1"`
+}
+
+type SiteDiversity struct {
+	SiteCode string `json:"siteCode"`
+	Block    int32  `json:"block"`
+	DiversityIndices
+}
+
+type BlockDiversity struct {
+	Block int32 `json:"block"`
+	DiversityIndices
+}
+
+type DiversityResponse struct {
+	Overall DiversityIndices `json:"overall"`
+	Blocks  []BlockDiversity `json:"blocks"`
+	Sites   []SiteDiversity  `json:"sites"`
+}
+
+// Diversity godoc
+//
+//	@Summary		Diversity indices
+//	@Description	Shannon H′, Simpson's index of diversity (1 - D), Pielou's evenness and Chao1 estimated richness per site, per block and overall, taking the observation count of a species, or its independent event count, as its abundance
+//	@Tags			statistics
+//	@Accept			json
+//	@Produce		json
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)	maximum(10080)
+//	@Success		200				{object}	DiversityResponse
+//	@Failure		400				{object}	utils.HttpError
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
+//	@Router			/stats/diversity [get]
+func (u *Controller) Diversity(c *gin.Context) {
+	var req DiversityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+	ctx := c.Request.Context()
+
+	from, to, taxa, commonName := ParseObservationStatsInput(req.ObservationStatsInput)
+
+	rows, err := u.q.ObservationAbundanceBySite(ctx, db.ObservationAbundanceBySiteParams{
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("Failed to fetch species abundance: %w", err))
+		return
+	}
+
+	// Abundance of each species, per site, per block and overall
+	overall := make(map[int64]int64)
+	blocks := make(map[int32]map[int64]int64)
+	sites := make(map[string]map[int64]int64)
+	siteBlocks := make(map[string]int32)
+	for _, row := range rows {
+		if blocks[row.Block] == nil {
+			blocks[row.Block] = make(map[int64]int64)
+		}
+		if sites[row.SiteCode] == nil {
+			sites[row.SiteCode] = make(map[int64]int64)
+			siteBlocks[row.SiteCode] = row.Block
+		}
+		overall[row.SpeciesID] += row.ObservationCount
+		blocks[row.Block][row.SpeciesID] += row.ObservationCount
+		sites[row.SiteCode][row.SpeciesID] += row.ObservationCount
+	}
+
+	resp := DiversityResponse{
+		Overall: diversityIndices(overall),
+		Blocks:  make([]BlockDiversity, 0, len(blocks)),
+		Sites:   make([]SiteDiversity, 0, len(sites)),
+	}
+	for block, abundance := range blocks {
+		resp.Blocks = append(resp.Blocks, BlockDiversity{
+			Block:            block,
+			DiversityIndices: diversityIndices(abundance),
+		})
+	}
+	slices.SortFunc(resp.Blocks, func(a, b BlockDiversity) int {
+		return cmp.Compare(a.Block, b.Block)
+	})
+	for code, abundance := range sites {
+		resp.Sites = append(resp.Sites, SiteDiversity{
+			SiteCode:         code,
+			Block:            siteBlocks[code],
+			DiversityIndices: diversityIndices(abundance),
+		})
+	}
+	slices.SortFunc(resp.Sites, func(a, b SiteDiversity) int {
+		return cmp.Compare(a.SiteCode, b.SiteCode)
+	})
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// diversityIndices computes the indices of the abundances of each species.
+func diversityIndices(abundance map[int64]int64) DiversityIndices {
+	var d DiversityIndices
+	var singletons, doubletons float64
+	for _, n := range abundance {
+		if n == 0 {
+			continue
+		}
+		d.ObservationCount += n
+		d.Richness++
+		switch n {
+		case 1:
+			singletons++
+		case 2:
+			doubletons++
+		}
+	}
+	if d.Richness == 0 {
+		return d
+	}
+
+	total := float64(d.ObservationCount)
+	var sumPairs float64
+	for _, n := range abundance {
+		if n == 0 {
+			continue
+		}
+		p := float64(n) / total
+		d.Shannon -= p * math.Log(p)
+		sumPairs += float64(n) * float64(n-1)
+	}
+	// Single species samples give -0
+	d.Shannon = math.Abs(d.Shannon)
+
+	if d.ObservationCount > 1 {
+		simpson := 1 - sumPairs/(total*(total-1))
+		d.Simpson = &simpson
+	}
+	if d.Richness > 1 {
+		pielou := d.Shannon / math.Log(float64(d.Richness))
+		d.Pielou = &pielou
+	}
+
+	d.Chao1 = float64(d.Richness)
+	if doubletons > 0 {
+		d.Chao1 += singletons * singletons / (2 * doubletons)
+	} else {
+		d.Chao1 += singletons * (singletons - 1) / 2
+	}
+	return d
+}
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiversityIndices(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name      string
+		abundance map[int64]int64
+		want      DiversityIndices
+	}{
+		{"no observations", nil, DiversityIndices{}},
+		{"only absent species", map[int64]int64{1: 0}, DiversityIndices{}},
+		{
+			"single observation",
+			map[int64]int64{1: 1},
+			DiversityIndices{ObservationCount: 1, Richness: 1, Chao1: 1},
+		},
+		{
+			"single species",
+			map[int64]int64{1: 10, 2: 0},
+			DiversityIndices{ObservationCount: 10, Richness: 1, Simpson: ptr(0), Chao1: 1},
+		},
+		{
+			// Two singletons and no doubleton: S + F1(F1-1)/2
+			"two singletons",
+			map[int64]int64{1: 1, 2: 1},
+			DiversityIndices{ObservationCount: 2, Richness: 2, Shannon: math.Ln2, Simpson: ptr(1), Pielou: ptr(1), Chao1: 3},
+		},
+		{
+			"even",
+			map[int64]int64{1: 4, 2: 4, 3: 4, 4: 4},
+			DiversityIndices{ObservationCount: 16, Richness: 4, Shannon: math.Log(4), Simpson: ptr(1 - 48.0/240), Pielou: ptr(1), Chao1: 4},
+		},
+		{
+			// Two singletons and a doubleton: S + F1²/(2 F2)
+			"uneven",
+			map[int64]int64{1: 5, 2: 3, 3: 2, 4: 1, 5: 1},
+			DiversityIndices{ObservationCount: 12, Richness: 5, Shannon: 1.4241299173467734, Simpson: ptr(1 - 28.0/132), Pielou: ptr(0.8848616689990428), Chao1: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diversityIndices(tt.abundance)
+			if got.ObservationCount != tt.want.ObservationCount || got.Richness != tt.want.Richness {
+				t.Errorf("counts = %d observations of %d species, want %d of %d", got.ObservationCount, got.Richness, tt.want.ObservationCount, tt.want.Richness)
+			}
+			checkIndex(t, "Shannon", &got.Shannon, &tt.want.Shannon)
+			checkIndex(t, "Simpson", got.Simpson, tt.want.Simpson)
+			checkIndex(t, "Pielou", got.Pielou, tt.want.Pielou)
+			checkIndex(t, "Chao1", &got.Chao1, &tt.want.Chao1)
+			if math.Signbit(got.Shannon) {
+				t.Errorf("Shannon = %v, want a positive value", got.Shannon)
+			}
+		})
+	}
+}
+
+func checkIndex(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, want %v", name, got, want)
+	case math.Abs(*got-*want) > 1e-12:
+		t.Errorf("%s = %v, want %v", name, *got, *want)
+	}
+}
@@ -0,0 +1,174 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/biomonash/nillumbik/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// eventQuerier records the independence window each counting query is
+// asked for, by query. The starts_event function the queries call to merge
+// observations into events is left to the database.
+type eventQuerier struct {
+	db.Querier
+	windows map[string]*int32
+}
+
+func (q eventQuerier) CountSpeciesByNative(ctx context.Context, arg db.CountSpeciesByNativeParams) ([]db.CountSpeciesByNativeRow, error) {
+	q.windows["CountSpeciesByNative"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) CountActiveSites(ctx context.Context, arg db.CountActiveSitesParams) (int64, error) {
+	return 0, nil
+}
+
+func (q eventQuerier) ListSpeciesCountByTaxa(ctx context.Context, arg db.ListSpeciesCountByTaxaParams) ([]db.ListSpeciesCountByTaxaRow, error) {
+	q.windows["ListSpeciesCountByTaxa"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationTimeSeries(ctx context.Context, arg db.ObservationTimeSeriesParams) ([]db.ObservationTimeSeriesRow, error) {
+	q.windows["ObservationTimeSeries"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationGroupBySites(ctx context.Context, arg db.ObservationGroupBySitesParams) ([]db.ObservationGroupBySitesRow, error) {
+	q.windows["ObservationGroupBySites"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) DeploymentEffortBySite(ctx context.Context, arg db.DeploymentEffortBySiteParams) ([]db.DeploymentEffortBySiteRow, error) {
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationGroupByBlocks(ctx context.Context, arg db.ObservationGroupByBlocksParams) ([]db.ObservationGroupByBlocksRow, error) {
+	q.windows["ObservationGroupByBlocks"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationSpeciesBySite(ctx context.Context, arg db.ObservationSpeciesBySiteParams) ([]db.ObservationSpeciesBySiteRow, error) {
+	q.windows["ObservationSpeciesBySite"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationSpeciesSequence(ctx context.Context, arg db.ObservationSpeciesSequenceParams) ([]db.ObservationSpeciesSequenceRow, error) {
+	q.windows["ObservationSpeciesSequence"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationAbundanceBySite(ctx context.Context, arg db.ObservationAbundanceBySiteParams) ([]db.ObservationAbundanceBySiteRow, error) {
+	q.windows["ObservationAbundanceBySite"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationActivityByMinute(ctx context.Context, arg db.ObservationActivityByMinuteParams) ([]db.ObservationActivityByMinuteRow, error) {
+	q.windows["ObservationActivityByMinute"] = arg.Independence
+	return nil, nil
+}
+
+func (q eventQuerier) ObservationActivityByMonth(ctx context.Context, arg db.ObservationActivityByMonthParams) ([]db.ObservationActivityByMonthRow, error) {
+	q.windows["ObservationActivityByMonth"] = arg.Independence
+	return nil, nil
+}
+
+func TestIndependence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	routes := []struct {
+		path    string
+		queries []string
+	}{
+		{"/stats/dashboard", []string{"CountSpeciesByNative"}},
+		{"/stats/observations", []string{"CountSpeciesByNative", "ListSpeciesCountByTaxa"}},
+		{"/stats/observations/timeseries", []string{"ObservationTimeSeries"}},
+		{"/stats/observations/sites", []string{"ObservationGroupBySites"}},
+		{"/stats/observations/blocks", []string{"ObservationGroupByBlocks"}},
+		{"/stats/observations/tenures", []string{"ObservationSpeciesBySite"}},
+		{"/stats/observations/forests", []string{"ObservationSpeciesBySite"}},
+		{"/stats/observations/accumulation", []string{"ObservationSpeciesSequence"}},
+		{"/stats/diversity", []string{"ObservationAbundanceBySite"}},
+		{"/stats/activity?months=true", []string{"ObservationActivityByMinute", "ObservationActivityByMonth"}},
+	}
+	minutes := func(m int32) *int32 { return &m }
+	tests := []struct {
+		name   string
+		query  string
+		want   int
+		window *int32
+	}{
+		{"observations", "", http.StatusOK, nil},
+		{"30 minute events", "independence=30", http.StatusOK, minutes(30)},
+		{"a week", "independence=10080", http.StatusOK, minutes(10080)},
+		{"no window", "independence=0", http.StatusBadRequest, nil},
+		{"longer than a week", "independence=10081", http.StatusBadRequest, nil},
+		{"not a number", "independence=half-hour", http.StatusBadRequest, nil},
+	}
+	for _, route := range routes {
+		for _, tt := range tests {
+			t.Run(route.path+" "+tt.name, func(t *testing.T) {
+				q := eventQuerier{windows: make(map[string]*int32)}
+				r := gin.New()
+				r.Use(statusOfError)
+				Register(r, NewController(q))
+
+				target := route.path
+				if tt.query != "" && strings.Contains(target, "?") {
+					target += "&" + tt.query
+				} else if tt.query != "" {
+					target += "?" + tt.query
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+				if w.Code != tt.want {
+					t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+				}
+				if tt.want != http.StatusOK {
+					if len(q.windows) > 0 {
+						t.Errorf("queried %v despite the invalid window", q.windows)
+					}
+					return
+				}
+				for _, query := range route.queries {
+					window, ok := q.windows[query]
+					if !ok {
+						t.Errorf("%s was not queried", query)
+						continue
+					}
+					if (window == nil) != (tt.window == nil) || window != nil && *window != *tt.window {
+						t.Errorf("%s window = %s, want %s", query, show(window), show(tt.window))
+					}
+				}
+			})
+		}
+	}
+}
+
+// statusOfError answers with the status of the HttpError a handler failed
+// with, as the server's error handler does.
+func statusOfError(c *gin.Context) {
+	c.Next()
+	if len(c.Errors) == 0 {
+		return
+	}
+	var httpErr utils.HttpError
+	if errors.As(c.Errors.Last().Err, &httpErr) {
+		c.AbortWithStatus(httpErr.Code)
+		return
+	}
+	c.AbortWithStatus(http.StatusInternalServerError)
+}
+
+func show(window *int32) string {
+	if window == nil {
+		return "none"
+	}
+	return fmt.Sprint(*window)
+}
@@ -7,10 +7,16 @@ import (
 
 type ObservationStatsInput struct {
 	models.TimePeriodRequest
-	Block      *int32   `form:"block"`
-	SiteCode   *string  `form:"siteCode"`
-	Taxa       *db.Taxa `form:"taxa"`
-	CommonName *string  `form:"commonName"`
+	Block      *int32         `form:"block"`
+	SiteCode   *string        `form:"siteCode"`
+	Taxa       *db.Taxa       `form:"taxa"`
+	CommonName *string        `form:"commonName"`
+	Tenure     *db.TenureType `form:"tenure" binding:"omitempty,oneof=public private"`
+	Forest     *db.ForestType `form:"forest" binding:"omitempty,oneof=dry wet"`
+	// Independence counts independent detection events instead of
+	// observations, merging the observations of a species at a site by the
+	// same method that follow the previous one within this many minutes.
+	Independence *int32 `form:"independence" binding:"omitempty,min=1,max=10080"`
 }
 
 type ObservationStats struct {
@@ -24,6 +24,10 @@ type ObservationOverviewResponse struct {
 
 type ObservationTimeSeriesRequest struct {
 	ObservationStatsInput
+	// Interval is the width of the buckets, year by default
+	Interval Interval `form:"interval" binding:"omitempty,oneof=day week month quarter season year"`
+	// GroupBy splits the series, by native status by default
+	GroupBy string `form:"groupBy" binding:"omitempty,oneof=native taxa method forest tenure"`
 }
 
 type ObservationTimeSeriesResponse struct {
@@ -37,14 +41,19 @@ type ObservationTimeSeriesResponse struct {
 //	@Tags			statistics
 //	@Accept			json
 //	@Produce		json
-//	@Param			from		query		string	False	"Search start from"	format(date-time)
-//	@Param			to			query		string	False	"Search end to"		format(date-time)
-//	@Param			block		query		integer	False	"Filter by site block"
-//	@Param			siteCode	query		string	False	"Filter by site code"
-//	@Param			taxa		query		string	False	"Filter by taxa"
-//	@Param			commonName	query		string	False	"Filter by species common_name"
-//	@Success		200			{object}	ObservationOverviewResponse
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common_name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)	maximum(10080)
+//	@Success		200				{object}	ObservationOverviewResponse
 //	@Error			400 																																																					{object}	gin.H
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
 //	@Router			/stats/observations [get]
 func (u *Controller) ObservationOverview(c *gin.Context) {
 	var req ObservationOverviewRequest
@@ -58,15 +67,18 @@ func (u *Controller) ObservationOverview(c *gin.Context) {
 
 	// Use from/to for filtering
 
-	from, to, taxa, commonName := parseObservationStatsInput(req.ObservationStatsInput)
+	from, to, taxa, commonName := ParseObservationStatsInput(req.ObservationStatsInput)
 
 	paramsNative := db.CountSpeciesByNativeParams{
-		From:       from,
-		To:         to,
-		Block:      req.Block,
-		SiteCode:   req.SiteCode,
-		Taxa:       taxa,
-		CommonName: commonName,
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
 	}
 
 	speciesGroups, err := u.q.CountSpeciesByNative(ctx, paramsNative)
@@ -83,12 +95,15 @@ func (u *Controller) ObservationOverview(c *gin.Context) {
 	}
 
 	params := db.ListSpeciesCountByTaxaParams{
-		From:       from,
-		To:         to,
-		Block:      req.Block,
-		SiteCode:   req.SiteCode,
-		Taxa:       taxa,
-		CommonName: commonName,
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
 	}
 	countByCategoryRows, err := u.q.ListSpeciesCountByTaxa(ctx, params)
 	if err != nil {
@@ -106,18 +121,25 @@ func (u *Controller) ObservationOverview(c *gin.Context) {
 // ObservationTimeSeries godoc
 //
 //	@Summary		Observation time series
-//	@Description	Observation time series
+//	@Description	Observation and species counts per time bucket, in one series per native status, taxa, method, forest or tenure. Every series has a point for each bucket of the period, counting zero when there were no observations.
 //	@Tags			statistics
 //	@Accept			json
 //	@Produce		json
-//	@Param			from		query		string	False	"Search start from"	format(date-time)
-//	@Param			to			query		string	False	"Search end to"		format(date-time)
-//	@Param			block		query		integer	False	"Filter by site block"
-//	@Param			siteCode	query		string	False	"Filter by site code"
-//	@Param			taxa		query		string	False	"Filter by taxa"
-//	@Param			commonName	query		string	False	"Filter by species common name"
-//	@Success		200			{object}	ObservationTimeSeriesResponse
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)										maximum(10080)
+//	@Param			interval		query		string	False	"Bucket width, austral seasons starting in December"																							Enums(day, week, month, quarter, season, year)	default(year)
+//	@Param			groupBy			query		string	False	"Series to split the counts into"																												Enums(native, taxa, method, forest, tenure)		default(native)
+//	@Success		200				{object}	ObservationTimeSeriesResponse
 //	@Error			400 																																												{object}	gin.H
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
 //	@Router			/stats/observations/timeseries [get]
 func (u *Controller) ObservationTimeSeries(c *gin.Context) {
 	var req ObservationTimeSeriesRequest
@@ -128,38 +150,45 @@ func (u *Controller) ObservationTimeSeries(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Parse common input parameters
-	from, to, taxa, commonName := parseObservationStatsInput(req.ObservationStatsInput)
-
-	params := db.ObservationTimeSeriesGroupByNativeParams{
-		From:       from,
-		To:         to,
-		Block:      req.Block,
-		SiteCode:   req.SiteCode,
-		Taxa:       taxa,
-		CommonName: commonName,
+	from, to, taxa, commonName := ParseObservationStatsInput(req.ObservationStatsInput)
+
+	if req.Interval == "" {
+		req.Interval = IntervalYear
+	}
+	if req.GroupBy == "" {
+		req.GroupBy = "native"
 	}
 
-	rows, err := u.q.ObservationTimeSeriesGroupByNative(ctx, params)
+	params := db.ObservationTimeSeriesParams{
+		Interval:     string(req.Interval),
+		GroupBy:      req.GroupBy,
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
+	}
+
+	rows, err := u.q.ObservationTimeSeries(ctx, params)
 	if err != nil {
 		c.Error(fmt.Errorf("Failed to fetch time series: %w", err))
 		return
 	}
-	series := map[string][]TimeSeriesPoint{
-		"native":     make([]TimeSeriesPoint, 0, len(rows)),
-		"non-native": make([]TimeSeriesPoint, 0, len(rows)),
+	var fromTime, toTime *time.Time
+	if from.Valid {
+		fromTime = &from.Time
 	}
-	for _, row := range rows {
-		key := "native"
-		if !row.IsNative {
-			key = "non-native"
-		}
-		series[key] = append(series[key], TimeSeriesPoint{
-			Timestamp: row.Year.Format(time.RFC3339),
-			ObservationStats: ObservationStats{
-				SpeciesCount:     row.SpeciesCount,
-				ObservationCount: row.ObservationCount,
-			},
-		})
+	if to.Valid {
+		toTime = &to.Time
+	}
+	series, err := fillTimeSeries(rows, seriesKeys(req.GroupBy), req.Interval, fromTime, toTime)
+	if err != nil {
+		c.Error(utils.NewHttpError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
 	}
 	resp := ObservationTimeSeriesResponse{Series: series}
 	c.JSON(http.StatusOK, resp)
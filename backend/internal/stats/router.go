@@ -8,5 +8,10 @@ func Register(r gin.IRouter, ctl *Controller) {
 	g.GET("/observations/timeseries", ctl.ObservationTimeSeries)
 	g.GET("/observations/sites", ctl.ObservationBySites)
 	g.GET("/observations/blocks", ctl.ObservationByBlocks)
+	g.GET("/observations/tenures", ctl.ObservationByTenures)
+	g.GET("/observations/forests", ctl.ObservationByForests)
+	g.GET("/observations/accumulation", ctl.ObservationAccumulation)
 	g.GET("/dashboard", ctl.DashboardStats)
+	g.GET("/diversity", ctl.Diversity)
+	g.GET("/activity", ctl.ObservationActivity)
 }
@@ -1,8 +1,10 @@
 package stats
 
 import (
+	"cmp"
 	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/biomonash/nillumbik/internal/db"
 	"github.com/biomonash/nillumbik/internal/utils"
@@ -20,6 +22,20 @@ type ObservationBySitesResponse struct {
 type SiteResponse struct {
 	SiteCode string `json:"siteCode"`
 	ObservationStats
+	// Effort is the survey effort of the site's deployments in the period,
+	// omitted when it has none.
+	Effort *SiteEffort `json:"effort,omitempty"`
+}
+
+// SiteEffort relates the camera and audio observations of a site to how long
+// its devices were deployed. The rates are omitted without effort.
+type SiteEffort struct {
+	TrapNights           float64  `json:"trapNights"`
+	CameraObservations   int64    `json:"cameraObservations"`
+	PerHundredTrapNights *float64 `json:"perHundredTrapNights,omitempty"`
+	RecordingHours       float64  `json:"recordingHours"`
+	AudioObservations    int64    `json:"audioObservations"`
+	PerRecordingHour     *float64 `json:"perRecordingHour,omitempty"`
 }
 
 type ObservationByBlocksRequest struct {
@@ -38,18 +54,23 @@ type BlockResponse struct {
 // ObservationBySites godoc
 //
 //	@Summary		Observation stats group by sites
-//	@Description	Observation stats group by sites
+//	@Description	Observation stats group by sites. Sites with deployments in the period also get their survey effort, with camera observations per 100 trap-nights and audio observations per recording hour, and are listed with zero counts and rates when nothing was observed there.
 //	@Tags			statistics
 //	@Accept			json
 //	@Produce		json
-//	@Param			from		query		string	False	"Search start from"	format(date-time)
-//	@Param			to			query		string	False	"Search end to"		format(date-time)
-//	@Param			block		query		integer	False	"Filter by site block"
-//	@Param			siteCode	query		string	False	"Filter by site code"
-//	@Param			taxa		query		string	False	"Filter by taxa"
-//	@Param			commonName	query		string	False	"Filter by species common name"
-//	@Success		200			{object}	ObservationBySitesResponse
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)	maximum(10080)
+//	@Success		200				{object}	ObservationBySitesResponse
 //	@Error			400 																																												{object}	gin.H
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
 //	@Router			/stats/observations/sites [get]
 func (u *Controller) ObservationBySites(c *gin.Context) {
 	var req ObservationBySitesRequest
@@ -60,15 +81,18 @@ func (u *Controller) ObservationBySites(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Parse common input parameters
-	from, to, taxa, commonName := parseObservationStatsInput(req.ObservationStatsInput)
+	from, to, taxa, commonName := ParseObservationStatsInput(req.ObservationStatsInput)
 
 	params := db.ObservationGroupBySitesParams{
-		From:       from,
-		To:         to,
-		Block:      req.Block,
-		SiteCode:   req.SiteCode,
-		Taxa:       taxa,
-		CommonName: commonName,
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
 	}
 	rows, err := u.q.ObservationGroupBySites(ctx, params)
 	if err != nil {
@@ -76,6 +100,26 @@ func (u *Controller) ObservationBySites(c *gin.Context) {
 		return
 	}
 
+	effortRows, err := u.q.DeploymentEffortBySite(ctx, db.DeploymentEffortBySiteParams{
+		From:     from,
+		To:       to,
+		Block:    req.Block,
+		Tenure:   req.NullTenure(),
+		Forest:   req.NullForest(),
+		SiteCode: req.SiteCode,
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("Failed to fetch deployment effort by sites: %w", err))
+		return
+	}
+	effort := make(map[string]map[db.ObservationMethod]float64)
+	for _, row := range effortRows {
+		if effort[row.SiteCode] == nil {
+			effort[row.SiteCode] = make(map[db.ObservationMethod]float64)
+		}
+		effort[row.SiteCode][row.Method] = row.Seconds
+	}
+
 	convertSite := func(row db.ObservationGroupBySitesRow) SiteResponse {
 		return SiteResponse{
 			SiteCode: row.SiteCode,
@@ -83,12 +127,28 @@ func (u *Controller) ObservationBySites(c *gin.Context) {
 				ObservationCount: row.ObservationCount,
 				SpeciesCount:     row.SpeciesCount,
 			},
+			Effort: siteEffort(row, effort[row.SiteCode]),
 		}
 	}
 
 	resp := ObservationBySitesResponse{
 		Sites: utils.MapSlice(convertSite, rows),
 	}
+	// Surveyed sites without observations detected nothing, at a rate of 0
+	observed := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		observed[row.SiteCode] = true
+	}
+	for _, row := range effortRows {
+		if observed[row.SiteCode] {
+			continue
+		}
+		observed[row.SiteCode] = true
+		resp.Sites = append(resp.Sites, convertSite(db.ObservationGroupBySitesRow{SiteCode: row.SiteCode}))
+	}
+	slices.SortFunc(resp.Sites, func(a, b SiteResponse) int {
+		return cmp.Compare(a.SiteCode, b.SiteCode)
+	})
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -99,14 +159,19 @@ func (u *Controller) ObservationBySites(c *gin.Context) {
 //	@Tags			statistics
 //	@Accept			json
 //	@Produce		json
-//	@Param			from		query		string	False	"Search start from"	format(date-time)
-//	@Param			to			query		string	False	"Search end to"		format(date-time)
-//	@Param			block		query		integer	False	"Filter by site block"
-//	@Param			siteCode	query		string	False	"Filter by site code"
-//	@Param			taxa		query		string	False	"Filter by taxa"
-//	@Param			commonName	query		string	False	"Filter by species common name"
-//	@Success		200			{object}	ObservationByBlocksResponse
+//	@Param			from			query		string	False	"Search start from"	format(date-time)
+//	@Param			to				query		string	False	"Search end to"		format(date-time)
+//	@Param			block			query		integer	False	"Filter by site block"
+//	@Param			tenure			query		string	False	"Filter by site tenure"			Enums(public, private)
+//	@Param			forest			query		string	False	"Filter by site forest type"	Enums(dry, wet)
+//	@Param			siteCode		query		string	False	"Filter by site code"
+//	@Param			taxa			query		string	False	"Filter by taxa"
+//	@Param			commonName		query		string	False	"Filter by species common name"
+//	@Param			independence	query		integer	False	"Count independent events, merging observations of a species at a site by the same method less than this many minutes after the previous one"	minimum(1)	maximum(10080)
+//	@Success		200				{object}	ObservationByBlocksResponse
 //	@Error			400 																																												{object}	gin.H
+//	@Failure		401				{object}	utils.HttpError
+//	@Failure		403				{object}	utils.HttpError
 //	@Router			/stats/observations/blocks [get]
 func (u *Controller) ObservationByBlocks(c *gin.Context) {
 	var req ObservationBySitesRequest
@@ -117,15 +182,18 @@ func (u *Controller) ObservationByBlocks(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Parse common input parameters
-	from, to, taxa, commonName := parseObservationStatsInput(req.ObservationStatsInput)
+	from, to, taxa, commonName := ParseObservationStatsInput(req.ObservationStatsInput)
 
 	params := db.ObservationGroupByBlocksParams{
-		From:       from,
-		To:         to,
-		Block:      req.Block,
-		SiteCode:   req.SiteCode,
-		Taxa:       taxa,
-		CommonName: commonName,
+		From:         from,
+		To:           to,
+		Block:        req.Block,
+		Tenure:       req.NullTenure(),
+		Forest:       req.NullForest(),
+		SiteCode:     req.SiteCode,
+		Taxa:         taxa,
+		CommonName:   commonName,
+		Independence: req.Independence,
 	}
 	rows, err := u.q.ObservationGroupByBlocks(ctx, params)
 	if err != nil {
@@ -147,3 +215,26 @@ func (u *Controller) ObservationByBlocks(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+// siteEffort works out the detection rates of a site from the seconds its
+// devices were deployed by method, or returns nil without any deployment.
+func siteEffort(row db.ObservationGroupBySitesRow, seconds map[db.ObservationMethod]float64) *SiteEffort {
+	if seconds == nil {
+		return nil
+	}
+	effort := &SiteEffort{
+		TrapNights:         seconds[db.ObservationMethodCamera] / 86400,
+		CameraObservations: row.CameraCount,
+		RecordingHours:     seconds[db.ObservationMethodAudio] / 3600,
+		AudioObservations:  row.AudioCount,
+	}
+	if effort.TrapNights > 0 {
+		rate := float64(row.CameraCount) / effort.TrapNights * 100
+		effort.PerHundredTrapNights = &rate
+	}
+	if effort.RecordingHours > 0 {
+		rate := float64(row.AudioCount) / effort.RecordingHours
+		effort.PerRecordingHour = &rate
+	}
+	return effort
+}
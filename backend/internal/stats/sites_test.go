@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// sitesQuerier has observations at A and C, and deployments at A and B.
+type sitesQuerier struct {
+	db.Querier
+}
+
+func (sitesQuerier) ObservationGroupBySites(ctx context.Context, arg db.ObservationGroupBySitesParams) ([]db.ObservationGroupBySitesRow, error) {
+	return []db.ObservationGroupBySitesRow{
+		{SiteCode: "A", SpeciesCount: 2, ObservationCount: 6, CameraCount: 5, AudioCount: 1},
+		{SiteCode: "C", SpeciesCount: 1, ObservationCount: 1, CameraCount: 1},
+	}, nil
+}
+
+func (sitesQuerier) DeploymentEffortBySite(ctx context.Context, arg db.DeploymentEffortBySiteParams) ([]db.DeploymentEffortBySiteRow, error) {
+	return []db.DeploymentEffortBySiteRow{
+		{SiteCode: "A", Method: db.ObservationMethodCamera, Seconds: 10 * 86400},
+		{SiteCode: "B", Method: db.ObservationMethodAudio, Seconds: 2 * 3600},
+		{SiteCode: "B", Method: db.ObservationMethodCamera, Seconds: 5 * 86400},
+	}, nil
+}
+
+func TestObservationBySites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/stats/observations/sites", nil)
+	NewController(sitesQuerier{}).ObservationBySites(c)
+	if len(c.Errors) > 0 {
+		t.Fatal(c.Errors.Last())
+	}
+	var resp ObservationBySitesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	rate := func(v float64) *float64 { return &v }
+	tests := []struct {
+		site         string
+		observations int64
+		effort       *SiteEffort
+	}{
+		{"A", 6, &SiteEffort{TrapNights: 10, CameraObservations: 5, PerHundredTrapNights: rate(50), AudioObservations: 1}},
+		// Surveyed without observations
+		{"B", 0, &SiteEffort{TrapNights: 5, PerHundredTrapNights: rate(0), RecordingHours: 2, PerRecordingHour: rate(0)}},
+		{"C", 1, nil},
+	}
+	if len(resp.Sites) != len(tests) {
+		t.Fatalf("got %d sites, want %d: %+v", len(resp.Sites), len(tests), resp.Sites)
+	}
+	for i, tt := range tests {
+		got := resp.Sites[i]
+		if got.SiteCode != tt.site || got.ObservationCount != tt.observations {
+			t.Errorf("site %d = %s with %d observations, want %s with %d", i, got.SiteCode, got.ObservationCount, tt.site, tt.observations)
+		}
+		if (got.Effort == nil) != (tt.effort == nil) {
+			t.Fatalf("site %s effort = %+v, want %+v", got.SiteCode, got.Effort, tt.effort)
+		}
+		if got.Effort == nil {
+			continue
+		}
+		e, want := *got.Effort, *tt.effort
+		if e.TrapNights != want.TrapNights || e.CameraObservations != want.CameraObservations ||
+			e.RecordingHours != want.RecordingHours || e.AudioObservations != want.AudioObservations {
+			t.Errorf("site %s effort = %+v, want %+v", got.SiteCode, e, want)
+		}
+		checkIndex(t, got.SiteCode+" per 100 trap-nights", e.PerHundredTrapNights, want.PerHundredTrapNights)
+		checkIndex(t, got.SiteCode+" per recording hour", e.PerRecordingHour, want.PerRecordingHour)
+	}
+}
@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"errors"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+)
+
+// Interval is the width of the buckets of a time series.
+type Interval string
+
+const (
+	IntervalDay     Interval = "day"
+	IntervalWeek    Interval = "week"
+	IntervalMonth   Interval = "month"
+	IntervalQuarter Interval = "quarter"
+	// IntervalSeason buckets by austral season: summer from December,
+	// autumn from March, winter from June and spring from September.
+	IntervalSeason Interval = "season"
+	IntervalYear   Interval = "year"
+)
+
+// maxBuckets caps the length of a time series, past which a larger interval
+// must be asked for.
+const maxBuckets = 10000
+
+var errTooManyBuckets = errors.New("the period has too many buckets for the interval, choose a larger interval or a shorter period")
+
+// Truncate returns the start of the bucket t falls in, the same way the
+// ObservationTimeSeries query does. Weeks start on Monday.
+func (i Interval) Truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch i {
+	case IntervalDay:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	case IntervalWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case IntervalQuarter:
+		return time.Date(y, (m-1)/3*3+1, 1, 0, 0, 0, 0, t.Location())
+	case IntervalSeason:
+		// Month 0 of a year is December of the year before
+		if m == time.December {
+			y++
+		}
+		return time.Date(y, m%12/3*3, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// Next returns the start of the bucket after the one starting at t.
+func (i Interval) Next(t time.Time) time.Time {
+	switch i {
+	case IntervalDay:
+		return t.AddDate(0, 0, 1)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	case IntervalQuarter, IntervalSeason:
+		return t.AddDate(0, 3, 0)
+	default:
+		return t.AddDate(1, 0, 0)
+	}
+}
+
+// seriesKeys lists the series a grouping always has, so that every value
+// shows up in the response even without observations.
+func seriesKeys(groupBy string) []string {
+	switch groupBy {
+	case "taxa":
+		return enumStrings(db.AllTaxaValues())
+	case "method":
+		return enumStrings(db.AllObservationMethodValues())
+	case "forest":
+		return enumStrings(db.AllForestTypeValues())
+	case "tenure":
+		return enumStrings(db.AllTenureTypeValues())
+	default:
+		return []string{"native", "non-native"}
+	}
+}
+
+func enumStrings[T ~string](values []T) []string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return s
+}
+
+// fillTimeSeries turns the rows of the ObservationTimeSeries query into one
+// series per key, with a point for every bucket from the one of from, or the
+// first row, to the one of to, or the last row. Buckets without
+// observations are counted as zero.
+func fillTimeSeries(rows []db.ObservationTimeSeriesRow, keys []string, interval Interval, from, to *time.Time) (map[string][]TimeSeriesPoint, error) {
+	var start, end time.Time
+	if len(rows) > 0 {
+		start, end = rows[0].Bucket, rows[len(rows)-1].Bucket
+	}
+	if from != nil {
+		start = interval.Truncate(*from)
+	}
+	if to != nil {
+		end = interval.Truncate(*to)
+	}
+
+	var buckets []time.Time
+	if (len(rows) > 0 || from != nil && to != nil) && !end.Before(start) {
+		for t := start; !t.After(end); t = interval.Next(t) {
+			if len(buckets) == maxBuckets {
+				return nil, errTooManyBuckets
+			}
+			buckets = append(buckets, t)
+		}
+	}
+
+	counts := make(map[string]map[time.Time]ObservationStats, len(keys))
+	for _, key := range keys {
+		counts[key] = make(map[time.Time]ObservationStats)
+	}
+	for _, row := range rows {
+		if _, ok := counts[row.Series]; !ok {
+			counts[row.Series] = make(map[time.Time]ObservationStats)
+			keys = append(keys, row.Series)
+		}
+		counts[row.Series][row.Bucket] = ObservationStats{
+			SpeciesCount:     row.SpeciesCount,
+			ObservationCount: row.ObservationCount,
+		}
+	}
+
+	series := make(map[string][]TimeSeriesPoint, len(keys))
+	for _, key := range keys {
+		points := make([]TimeSeriesPoint, len(buckets))
+		for i, bucket := range buckets {
+			points[i] = TimeSeriesPoint{
+				Timestamp:        bucket.Format(time.RFC3339),
+				ObservationStats: counts[key][bucket],
+			}
+		}
+		series[key] = points
+	}
+	return series, nil
+}
@@ -0,0 +1,254 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/biomonash/nillumbik/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestIntervalTruncate(t *testing.T) {
+	at := time.Date(2024, time.May, 15, 13, 45, 0, 0, time.UTC) // A Wednesday
+	tests := []struct {
+		interval Interval
+		t        time.Time
+		want     time.Time
+	}{
+		{IntervalDay, at, date(2024, time.May, 15)},
+		{IntervalWeek, at, date(2024, time.May, 13)},
+		{IntervalWeek, date(2024, time.May, 13), date(2024, time.May, 13)},
+		// A Sunday ends the week that started on Monday
+		{IntervalWeek, date(2024, time.May, 19), date(2024, time.May, 13)},
+		// Across the end of a month
+		{IntervalWeek, date(2024, time.June, 1), date(2024, time.May, 27)},
+		{IntervalMonth, at, date(2024, time.May, 1)},
+		{IntervalQuarter, at, date(2024, time.April, 1)},
+		{IntervalQuarter, date(2024, time.December, 31), date(2024, time.October, 1)},
+		{IntervalYear, at, date(2024, time.January, 1)},
+		// Austral seasons: autumn, winter, spring, then summer across the new year
+		{IntervalSeason, at, date(2024, time.March, 1)},
+		{IntervalSeason, date(2024, time.March, 1), date(2024, time.March, 1)},
+		{IntervalSeason, date(2024, time.February, 29), date(2023, time.December, 1)},
+		{IntervalSeason, date(2024, time.August, 31), date(2024, time.June, 1)},
+		{IntervalSeason, date(2024, time.September, 1), date(2024, time.September, 1)},
+		{IntervalSeason, date(2024, time.November, 30), date(2024, time.September, 1)},
+		{IntervalSeason, date(2024, time.December, 1), date(2024, time.December, 1)},
+		{IntervalSeason, date(2025, time.January, 10), date(2024, time.December, 1)},
+	}
+	for _, tt := range tests {
+		if got := tt.interval.Truncate(tt.t); !got.Equal(tt.want) {
+			t.Errorf("%s Truncate(%s) = %s, want %s", tt.interval, tt.t.Format(time.DateTime), got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestIntervalNext(t *testing.T) {
+	tests := []struct {
+		interval Interval
+		t        time.Time
+		want     time.Time
+	}{
+		{IntervalDay, date(2024, time.February, 28), date(2024, time.February, 29)},
+		{IntervalWeek, date(2024, time.December, 30), date(2025, time.January, 6)},
+		{IntervalMonth, date(2024, time.December, 1), date(2025, time.January, 1)},
+		{IntervalQuarter, date(2024, time.October, 1), date(2025, time.January, 1)},
+		{IntervalSeason, date(2024, time.December, 1), date(2025, time.March, 1)},
+		{IntervalSeason, date(2024, time.September, 1), date(2024, time.December, 1)},
+		{IntervalYear, date(2024, time.January, 1), date(2025, time.January, 1)},
+	}
+	for _, tt := range tests {
+		if got := tt.interval.Next(tt.t); !got.Equal(tt.want) {
+			t.Errorf("%s Next(%s) = %s, want %s", tt.interval, tt.t.Format(time.DateOnly), got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestFillTimeSeries(t *testing.T) {
+	ptr := func(t time.Time) *time.Time { return &t }
+	rows := []db.ObservationTimeSeriesRow{
+		{Bucket: date(2023, time.December, 1), Series: "native", SpeciesCount: 2, ObservationCount: 5},
+		{Bucket: date(2024, time.June, 1), Series: "native", SpeciesCount: 1, ObservationCount: 1},
+		{Bucket: date(2024, time.June, 1), Series: "non-native", SpeciesCount: 1, ObservationCount: 3},
+	}
+	tests := []struct {
+		name     string
+		rows     []db.ObservationTimeSeriesRow
+		interval Interval
+		from, to *time.Time
+		buckets  []string
+		counts   map[string][]int64
+		wantErr  error
+	}{
+		{
+			name:     "gaps between rows",
+			rows:     rows,
+			interval: IntervalSeason,
+			buckets:  []string{"2023-12-01T00:00:00Z", "2024-03-01T00:00:00Z", "2024-06-01T00:00:00Z"},
+			counts:   map[string][]int64{"native": {5, 0, 1}, "non-native": {0, 0, 3}},
+		},
+		{
+			name:     "from and to widen the series",
+			rows:     rows,
+			interval: IntervalSeason,
+			from:     ptr(date(2023, time.October, 15)),
+			to:       ptr(date(2024, time.September, 2)),
+			buckets: []string{"2023-09-01T00:00:00Z", "2023-12-01T00:00:00Z", "2024-03-01T00:00:00Z",
+				"2024-06-01T00:00:00Z", "2024-09-01T00:00:00Z"},
+			counts: map[string][]int64{"native": {0, 5, 0, 1, 0}, "non-native": {0, 0, 0, 3, 0}},
+		},
+		{
+			name:     "without rows",
+			interval: IntervalMonth,
+			from:     ptr(date(2024, time.January, 20)),
+			to:       ptr(date(2024, time.March, 1)),
+			buckets:  []string{"2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z", "2024-03-01T00:00:00Z"},
+			counts:   map[string][]int64{"native": {0, 0, 0}, "non-native": {0, 0, 0}},
+		},
+		{
+			name:     "without rows or a period",
+			interval: IntervalMonth,
+			from:     ptr(date(2024, time.January, 20)),
+			buckets:  []string{},
+			counts:   map[string][]int64{"native": {}, "non-native": {}},
+		},
+		{
+			name:     "to before from",
+			rows:     nil,
+			interval: IntervalDay,
+			from:     ptr(date(2024, time.March, 2)),
+			to:       ptr(date(2024, time.March, 1)),
+			buckets:  []string{},
+			counts:   map[string][]int64{"native": {}, "non-native": {}},
+		},
+		{
+			name:     "too many buckets",
+			interval: IntervalDay,
+			from:     ptr(date(1990, time.January, 1)),
+			to:       ptr(date(2024, time.January, 1)),
+			wantErr:  errTooManyBuckets,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := fillTimeSeries(tt.rows, seriesKeys("native"), tt.interval, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(series) != len(tt.counts) {
+				t.Errorf("series = %v, want %v", series, tt.counts)
+			}
+			for key, want := range tt.counts {
+				points := series[key]
+				buckets := make([]string, len(points))
+				counts := make([]int64, len(points))
+				for i, p := range points {
+					buckets[i], counts[i] = p.Timestamp, p.ObservationCount
+				}
+				if !slices.Equal(buckets, tt.buckets) {
+					t.Errorf("%s buckets = %v, want %v", key, buckets, tt.buckets)
+				}
+				if !slices.Equal(counts, want) {
+					t.Errorf("%s counts = %v, want %v", key, counts, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSeriesKeys(t *testing.T) {
+	tests := []struct {
+		groupBy string
+		want    []string
+	}{
+		{"native", []string{"native", "non-native"}},
+		{"taxa", []string{"bird", "mammal", "reptile"}},
+		{"method", []string{"audio", "camera", "observed"}},
+		{"forest", []string{"dry", "wet"}},
+		{"tenure", []string{"public", "private"}},
+	}
+	for _, tt := range tests {
+		got := seriesKeys(tt.groupBy)
+		if !slices.Equal(slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(tt.want))) {
+			t.Errorf("seriesKeys(%q) = %v, want %v", tt.groupBy, got, tt.want)
+		}
+	}
+}
+
+// timeSeriesQuerier records the parameters of the time series query and
+// has a mammal observation in the winter of 2024.
+type timeSeriesQuerier struct {
+	db.Querier
+	params *db.ObservationTimeSeriesParams
+}
+
+func (q timeSeriesQuerier) ObservationTimeSeries(ctx context.Context, arg db.ObservationTimeSeriesParams) ([]db.ObservationTimeSeriesRow, error) {
+	*q.params = arg
+	return []db.ObservationTimeSeriesRow{
+		{Bucket: date(2024, time.June, 1), Series: "mammal", SpeciesCount: 1, ObservationCount: 2},
+	}, nil
+}
+
+func TestObservationTimeSeries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name     string
+		query    string
+		interval string
+		groupBy  string
+		series   []string
+		wantErr  bool
+	}{
+		{"defaults", "", "year", "native", []string{"native", "non-native"}, false},
+		{"seasons by taxa", "interval=season&groupBy=taxa&from=2024-05-01&to=2024-10-01", "season", "taxa", []string{"bird", "mammal", "reptile"}, false},
+		{"unknown interval", "interval=fortnight", "", "", nil, true},
+		{"unknown grouping", "groupBy=observer", "", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params db.ObservationTimeSeriesParams
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/stats/observations/timeseries?"+tt.query, nil)
+			NewController(timeSeriesQuerier{params: &params}).ObservationTimeSeries(c)
+			if (len(c.Errors) > 0) != tt.wantErr {
+				t.Fatalf("errors = %v, want one: %v", c.Errors, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if params.Interval != tt.interval || params.GroupBy != tt.groupBy {
+				t.Errorf("interval = %q and groupBy = %q, want %q and %q", params.Interval, params.GroupBy, tt.interval, tt.groupBy)
+			}
+			var resp ObservationTimeSeriesResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for key := range resp.Series {
+				keys = append(keys, key)
+			}
+			// The mammal row adds its series even to the native grouping
+			want := tt.series
+			if !slices.Contains(want, "mammal") {
+				want = append(slices.Clone(want), "mammal")
+			}
+			if !slices.Equal(slices.Sorted(slices.Values(keys)), slices.Sorted(slices.Values(want))) {
+				t.Errorf("series = %v, want %v", keys, want)
+			}
+		})
+	}
+}
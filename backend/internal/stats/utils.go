@@ -6,9 +6,9 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// parseObservationStatsInput converts ObservationStatsInput to SQLC parameters
+// ParseObservationStatsInput converts ObservationStatsInput to SQLC parameters
 // This function extracts the common parsing logic used across all observation endpoints
-func parseObservationStatsInput(input ObservationStatsInput) (from, to pgtype.Timestamp, taxa db.NullTaxa, commonName *string) {
+func ParseObservationStatsInput(input ObservationStatsInput) (from, to pgtype.Timestamp, taxa db.NullTaxa, commonName *string) {
 	taxa = db.NullTaxa{Valid: false}
 	if input.Taxa != nil {
 		taxa.Taxa = *input.Taxa
@@ -19,3 +19,19 @@ func parseObservationStatsInput(input ObservationStatsInput) (from, to pgtype.Ti
 
 	return input.From.ToPGTime(), input.To.ToPGTime(), taxa, commonName
 }
+
+// NullTenure returns the tenure filter for the queries.
+func (input ObservationStatsInput) NullTenure() db.NullTenureType {
+	if input.Tenure == nil {
+		return db.NullTenureType{}
+	}
+	return db.NullTenureType{TenureType: *input.Tenure, Valid: true}
+}
+
+// NullForest returns the forest filter for the queries.
+func (input ObservationStatsInput) NullForest() db.NullForestType {
+	if input.Forest == nil {
+		return db.NullForestType{}
+	}
+	return db.NullForestType{ForestType: *input.Forest, Valid: true}
+}